@@ -0,0 +1,19 @@
+package dto
+
+type CreateBookmarkCollectionRequest struct {
+	Name   string `json:"name" binding:"required,min=1,max=64"`
+	Public bool   `json:"public"`
+}
+
+type UpdateBookmarkCollectionRequest struct {
+	Name   *string `json:"name" binding:"omitempty,min=1,max=64"`
+	Public *bool   `json:"public"`
+}
+
+type AddToBookmarkCollectionRequest struct {
+	PostID int64 `json:"post_id" binding:"required"`
+}
+
+type ReorderBookmarkCollectionRequest struct {
+	PostIDs []int64 `json:"post_ids" binding:"required,min=1"`
+}
@@ -6,7 +6,6 @@ type CreateCommentDto struct {
 	Content  string `json:"content" binding:"required,min=1"`
 }
 
-type GetCommentsDto struct {
-	Limit  int `json:"limit"`
-	Offset int `json:"offset"`
+type EditCommentRequest struct {
+	Content string `json:"content" binding:"required,min=1"`
 }
@@ -0,0 +1,33 @@
+package dto
+
+import "github.com/BloggingApp/post-service/internal/model"
+
+// Comment with its first replies, replies_cursor loads more of them with the same sort
+type CommentNode struct {
+	model.FullComment
+	RepliesCount  int64          `json:"replies_count"`
+	Replies       []*CommentNode `json:"replies"`
+	RepliesCursor string         `json:"replies_cursor"` // empty if all replies are loaded or the max depth is reached
+}
+
+// Comments tree, next_cursor loads more top level comments
+type CommentTree struct {
+	Comments   []*CommentNode `json:"comments"`
+	NextCursor string         `json:"next_cursor"`
+}
+
+// Returns comments of all tree levels
+func (t *CommentTree) FullComments() []*model.FullComment {
+	comments := []*model.FullComment{}
+	nodes := t.Comments
+	for len(nodes) != 0 {
+		var next []*CommentNode
+		for _, node := range nodes {
+			comments = append(comments, &node.FullComment)
+			next = append(next, node.Replies...)
+		}
+		nodes = next
+	}
+
+	return comments
+}
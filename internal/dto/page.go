@@ -0,0 +1,57 @@
+package dto
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+
+	"github.com/BloggingApp/post-service/internal/model"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Page of a cursor paginated list, next_cursor is empty on the last page
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor"`
+}
+
+func NewPage[T any](items []T, next *model.Cursor) *Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+
+	return &Page[T]{
+		Items: items,
+		NextCursor: EncodeCursor(next),
+	}
+}
+
+// Cursors are opaque for clients
+func EncodeCursor(cursor *model.Cursor) string {
+	if cursor == nil {
+		return ""
+	}
+
+	cursorJSON, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(cursorJSON)
+}
+
+// Empty cursor means the first page and is decoded to nil
+func DecodeCursor(cursor string) (*model.Cursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	cursorJSON, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var result model.Cursor
+	if err := json.Unmarshal(cursorJSON, &result); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	return &result, nil
+}
@@ -0,0 +1,65 @@
+package dto
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/BloggingApp/post-service/internal/model"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor model.Cursor
+	}{
+		{name: "time and id", cursor: model.Cursor{CreatedAt: time.Date(2024, 5, 1, 12, 30, 0, 123456000, time.UTC), ID: 42}},
+		{name: "with score", cursor: model.Cursor{Score: 3.75, CreatedAt: time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), ID: 7}},
+		{name: "zero", cursor: model.Cursor{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			encoded := EncodeCursor(&tt.cursor)
+			decoded, err := DecodeCursor(encoded)
+			if err != nil {
+				t.Fatalf("failed to decode %q: %s", encoded, err.Error())
+			}
+			if decoded.Score != tt.cursor.Score || !decoded.CreatedAt.Equal(tt.cursor.CreatedAt) || decoded.ID != tt.cursor.ID {
+				t.Errorf("expected %+v, got %+v", tt.cursor, *decoded)
+			}
+		})
+	}
+}
+
+func TestEmptyCursor(t *testing.T) {
+	if encoded := EncodeCursor(nil); encoded != "" {
+		t.Errorf("expected empty cursor for nil, got %q", encoded)
+	}
+
+	cursor, err := DecodeCursor("")
+	if cursor != nil || err != nil {
+		t.Errorf("expected nil cursor for the first page, got %+v, %v", cursor, err)
+	}
+}
+
+func TestDecodeInvalidCursor(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{name: "not base64", cursor: "!!!"},
+		{name: "padded base64", cursor: base64.URLEncoding.EncodeToString([]byte(`{"i":1}`))},
+		{name: "not json", cursor: base64.RawURLEncoding.EncodeToString([]byte("cursor"))},
+		{name: "wrong types", cursor: base64.RawURLEncoding.EncodeToString([]byte(`{"t":1,"i":"a"}`))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeCursor(tt.cursor); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("expected ErrInvalidCursor, got %v", err)
+			}
+		})
+	}
+}
@@ -1,29 +1,78 @@
 package dto
 
-import "github.com/google/uuid"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type CreatePostRequest struct {
 	Title   string   `json:"title" binding:"required,min=2"`
 	Content string   `json:"content" binding:"required,min=100,max=15000"`
 	FeedView string `json:"feed_view" binding:"required,min=100,max=2000"`
 	Tags    []string `json:"tags"`
+	PublishAt *time.Time `json:"publish_at"`
 }
 
-type GetPostsRequest struct {
-	Limit  int `json:"limit"`
-	Offset int `json:"offset"`
+type SchedulePostRequest struct {
+	PublishAt time.Time `json:"publish_at" binding:"required"`
 }
 
-type EditPostRequest struct {
-	PostID   int64     `json:"id"`
-	AuthorID uuid.UUID `json:"author_id"`
+type PublishDraftRequest struct {
+	PublishAt *time.Time `json:"publish_at"`
+}
+
+type SaveDraftRequest struct {
+	Title    string   `json:"title"`
+	Content  string   `json:"content" binding:"max=15000"`
+	FeedView string   `json:"feed_view" binding:"max=2000"`
+	Tags     []string `json:"tags"`
+}
+
+type EditDraftRequest struct {
 	Title    *string   `json:"title"`
-	Content  *string   `json:"content"`
-	FeedView *string   `json:"feed_view"`
+	Content  *string   `json:"content" binding:"omitempty,max=15000"`
+	FeedView *string   `json:"feed_view" binding:"omitempty,max=2000"`
+	Tags     *[]string `json:"tags"`
+}
+
+type AutosaveDraftRequest struct {
+	Title    *string `json:"title"`
+	Content  *string `json:"content" binding:"omitempty,max=15000"`
+	FeedView *string `json:"feed_view" binding:"omitempty,max=2000"`
+}
+
+// Autosaved draft fields kept in redis until they are flushed to postgres
+type DraftAutosave struct {
+	AuthorID uuid.UUID `json:"author_id"`
+	AutosaveDraftRequest
+}
+
+type EditPostRequest struct {
+	PostID     int64     `json:"id"`
+	AuthorID   uuid.UUID `json:"author_id"`
+	Title      *string   `json:"title"`
+	Content    *string   `json:"content"`
+	FeedView   *string   `json:"feed_view"`
+	Tags       *[]string `json:"tags"`        // replaces all tags, applied before add_tags and remove_tags
+	AddTags    []string  `json:"add_tags"`
+	RemoveTags []string  `json:"remove_tags"`
+}
+
+// Appeal against the rejection, the optional edit is applied to the post before the appeal
+type AppealPostRequest struct {
+	Justification string           `json:"justification" binding:"required,min=10,max=2000"`
+	Edit          *EditPostRequest `json:"edit"`
+}
+
+type DecideAppealRequest struct {
+	Overturned  bool   `json:"overturned"`
+	DecisionMsg string `json:"decision_msg" binding:"required,max=1024"`
 }
 
 type UpdatePostValidationStatusRequest struct {
-	PostID    int64  `json:"post_id" binding:"required"`
-	Validated bool   `json:"validated" binding:"required"`
-	StatusMsg string `json:"status_msg" binding:"required,max=1024"`
+	PostID     int64  `json:"post_id" binding:"required"`
+	RevisionID *int64 `json:"revision_id"` // set to review a pending edit of the post
+	Validated  bool   `json:"validated"`
+	StatusMsg  string `json:"status_msg" binding:"required,max=1024"`
 }
@@ -5,4 +5,25 @@ import "github.com/BloggingApp/post-service/internal/model"
 type GetPost struct {
 	Post model.FullPost `json:"post"`
 	IsLiked bool `json:"is_liked"`
+	IsBookmarked bool `json:"is_bookmarked"`
+	MyReaction *string `json:"my_reaction"`
+}
+
+// Pending edit of a validated post, diff is made against the live version
+type PendingEdit struct {
+	Revision model.PostRevision `json:"revision"`
+	Post     model.FullPost     `json:"post"`
+	Diff     PostDiff           `json:"diff"`
+}
+
+type ModerationQueue struct {
+	Posts        Page[*model.QueuedPost] `json:"posts"`
+	PendingEdits Page[*PendingEdit]    `json:"pending_edits"`
+}
+
+// New post or pending edit handed to a moderator with their claim on the post
+type ModerationAssignment struct {
+	Post        *model.QueuedPost     `json:"post,omitempty"`
+	PendingEdit *PendingEdit          `json:"pending_edit,omitempty"`
+	Claim       model.ModerationClaim `json:"claim"`
 }
@@ -17,4 +17,71 @@ type MQPostValidationStatusUpdateMsg struct {
 	PostID    int64      `json:"post_id"`
 	UserID    uuid.UUID  `json:"user_id"`
 	StatusMsg string     `json:"status_msg"`
+	RevisionID *int64    `json:"revision_id,omitempty"`
+}
+
+// New comment on a post, UserID is the post author to notify
+type MQCommentCreatedMsg struct {
+	CommentID   int64     `json:"comment_id"`
+	PostID      int64     `json:"post_id"`
+	PostTitle   string    `json:"post_title"`
+	UserID      uuid.UUID `json:"user_id"`
+	CommenterID uuid.UUID `json:"commenter_id"`
+	Content     string    `json:"content"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Reply to a comment, UserID is the parent comment author to notify
+type MQCommentRepliedMsg struct {
+	CommentID   int64     `json:"comment_id"`
+	ParentID    int64     `json:"parent_id"`
+	PostID      int64     `json:"post_id"`
+	PostTitle   string    `json:"post_title"`
+	UserID      uuid.UUID `json:"user_id"`
+	CommenterID uuid.UUID `json:"commenter_id"`
+	Content     string    `json:"content"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// User mentioned in a post or a comment for the first time, UserID is the mentioned user to notify
+type MQUserMentionedMsg struct {
+	UserID        uuid.UUID `json:"user_id"`
+	MentionedByID uuid.UUID `json:"mentioned_by_id"`
+	PostID        int64     `json:"post_id"`
+	CommentID     *int64    `json:"comment_id,omitempty"` // set if mentioned in a comment
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Reports of a post or comment resolved by a moderator, AuthorID is the target author and
+// ReporterIDs are the users whose reports were resolved
+type MQReportResolvedMsg struct {
+	ResolutionID int64       `json:"resolution_id"`
+	Target       string      `json:"target"`
+	TargetID     int64       `json:"target_id"`
+	PostID       int64       `json:"post_id"`
+	AuthorID     uuid.UUID   `json:"author_id"`
+	ModeratorID  uuid.UUID   `json:"moderator_id"`
+	Action       string      `json:"action"`
+	Note         string      `json:"note"`
+	ReporterIDs  []uuid.UUID `json:"reporter_ids"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+// Appeal against a post rejection decided by a moderator, UserID is the post author to notify
+type MQPostAppealDecidedMsg struct {
+	AppealID    int64     `json:"appeal_id"`
+	PostID      int64     `json:"post_id"`
+	UserID      uuid.UUID `json:"user_id"`
+	ModeratorID uuid.UUID `json:"moderator_id"`
+	Status      string    `json:"status"` // model.APPEAL_STATUS_UPHELD or model.APPEAL_STATUS_OVERTURNED
+	DecisionMsg string    `json:"decision_msg"`
+	DecidedAt   time.Time `json:"decided_at"`
+}
+
+// Follow or unfollow event from users.follows exchange
+type MQUserFollowMsg struct {
+	FollowerID uuid.UUID `json:"follower_id"`
+	FolloweeID uuid.UUID `json:"followee_id"`
+	Followed   bool      `json:"followed"` // false if unfollowed
+	CreatedAt  time.Time `json:"created_at"`
 }
@@ -0,0 +1,5 @@
+package dto
+
+type ReactRequest struct {
+	Type string `json:"type" binding:"required"`
+}
@@ -0,0 +1,11 @@
+package dto
+
+type ReportRequest struct {
+	Reason  string `json:"reason" binding:"required"`
+	Details string `json:"details" binding:"max=1000"`
+}
+
+type ResolveReportsRequest struct {
+	Action string `json:"action" binding:"required"`
+	Note   string `json:"note" binding:"max=1000"`
+}
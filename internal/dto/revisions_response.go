@@ -0,0 +1,19 @@
+package dto
+
+type DiffLine struct {
+	Op   string `json:"op"` // "=", "+" or "-"
+	Text string `json:"text"`
+}
+
+type PostDiff struct {
+	Title    []DiffLine `json:"title"`
+	FeedView []DiffLine `json:"feed_view"`
+	Content  []DiffLine `json:"content"`
+	Tags     []DiffLine `json:"tags"` // one tag per line
+}
+
+type RevisionDiff struct {
+	FromRevisionID int64 `json:"from_revision_id"`
+	ToRevisionID   int64 `json:"to_revision_id"`
+	PostDiff
+}
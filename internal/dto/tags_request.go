@@ -0,0 +1,14 @@
+package dto
+
+type MergeTagsRequest struct {
+	From []string `json:"from" binding:"required,min=1,max=10"`
+	To   string   `json:"to" binding:"required"`
+}
+
+type RenameTagRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+type AddTagAliasRequest struct {
+	Alias string `json:"alias" binding:"required"`
+}
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/BloggingApp/post-service/internal/dto"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) postsAppeal(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	postID, err := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidPostID.Error()))
+		return
+	}
+
+	var input dto.AppealPostRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	appeal, err := h.services.Post.Appeal(c.Request.Context(), int64(postID), user.ID, input)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	if appeal == nil {
+		c.JSON(http.StatusOK, dto.NewBasicResponse(true, approvedAfterEditDetails))
+		return
+	}
+
+	c.JSON(http.StatusCreated, appeal)
+}
+
+func (h *Handler) postsGetAppeals(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	postID, err := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidPostID.Error()))
+		return
+	}
+
+	appeals, err := h.services.Post.FindPostAppeals(c.Request.Context(), int64(postID), user.ID)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, appeals)
+}
+
+func (h *Handler) modAppealsGet(c *gin.Context) {
+	moderator := h.getUserFromRequest(c)
+
+	cursor, limit, err := cursorQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	appeals, err := h.services.Post.FindAppealsQueue(c.Request.Context(), moderator.ID, cursor, limit)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, appeals)
+}
+
+func (h *Handler) modAppealsDecide(c *gin.Context) {
+	moderator := h.getUserFromRequest(c)
+
+	appealID, err := strconv.Atoi(strings.TrimSpace(c.Param("appealID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	var input dto.DecideAppealRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	appeal, err := h.services.Post.DecideAppeal(c.Request.Context(), int64(appealID), moderator.ID, input)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, appeal)
+}
@@ -0,0 +1,265 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/BloggingApp/post-service/internal/dto"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *Handler) postsIsBookmarked(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	postID, err := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidPostID.Error()))
+		return
+	}
+
+	isBookmarked := h.services.Bookmark.IsBookmarked(c.Request.Context(), int64(postID), user.ID)
+
+	c.JSON(http.StatusOK, gin.H{"isBookmarked": isBookmarked})
+}
+
+func (h *Handler) postsBookmark(c *gin.Context) {
+	h.setBookmark(c, false)
+}
+
+func (h *Handler) postsUnbookmark(c *gin.Context) {
+	h.setBookmark(c, true)
+}
+
+func (h *Handler) setBookmark(c *gin.Context, unbookmark bool) {
+	user := h.getUserFromRequest(c)
+
+	postID, err := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidPostID.Error()))
+		return
+	}
+
+	if err := h.services.Bookmark.Bookmark(c.Request.Context(), int64(postID), user.ID, unbookmark); err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) bookmarksGetMy(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	cursor, limit, err := cursorQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	posts, err := h.services.Bookmark.FindUserBookmarks(c.Request.Context(), user.ID, cursor, limit)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, posts)
+}
+
+func (h *Handler) collectionsCreate(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	var input dto.CreateBookmarkCollectionRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	collection, err := h.services.Bookmark.CreateCollection(c.Request.Context(), user.ID, input)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, collection)
+}
+
+func (h *Handler) collectionsGetMy(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	collections, err := h.services.Bookmark.FindUserCollections(c.Request.Context(), user.ID, false)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, collections)
+}
+
+func (h *Handler) collectionsGetUser(c *gin.Context) {
+	userID, err := uuid.Parse(strings.TrimSpace(c.Param("userID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	collections, err := h.services.Bookmark.FindUserCollections(c.Request.Context(), userID, true)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, collections)
+}
+
+// Anonymous users and other users can see only public collections
+func (h *Handler) collectionsGetByID(c *gin.Context) {
+	collectionID, err := strconv.Atoi(strings.TrimSpace(c.Param("collectionID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	collection, err := h.services.Bookmark.FindCollection(c.Request.Context(), int64(collectionID), h.viewerID(c))
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, collection)
+}
+
+func (h *Handler) collectionsGetPosts(c *gin.Context) {
+	collectionID, err := strconv.Atoi(strings.TrimSpace(c.Param("collectionID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	cursor, limit, err := cursorQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	posts, err := h.services.Bookmark.FindCollectionPosts(c.Request.Context(), int64(collectionID), h.viewerID(c), cursor, limit)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, posts)
+}
+
+func (h *Handler) collectionsUpdate(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	collectionID, err := strconv.Atoi(strings.TrimSpace(c.Param("collectionID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	var input dto.UpdateBookmarkCollectionRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	if err := h.services.Bookmark.UpdateCollection(c.Request.Context(), int64(collectionID), user.ID, input); err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) collectionsDelete(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	collectionID, err := strconv.Atoi(strings.TrimSpace(c.Param("collectionID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	if err := h.services.Bookmark.DeleteCollection(c.Request.Context(), int64(collectionID), user.ID); err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) collectionsAddPost(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	collectionID, err := strconv.Atoi(strings.TrimSpace(c.Param("collectionID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	var input dto.AddToBookmarkCollectionRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	if err := h.services.Bookmark.AddToCollection(c.Request.Context(), int64(collectionID), user.ID, input.PostID); err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) collectionsRemovePost(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	collectionID, err0 := strconv.Atoi(strings.TrimSpace(c.Param("collectionID")))
+	postID, err1 := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
+	if err0 != nil || err1 != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	if err := h.services.Bookmark.RemoveFromCollection(c.Request.Context(), int64(collectionID), user.ID, int64(postID)); err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) collectionsReorder(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	collectionID, err := strconv.Atoi(strings.TrimSpace(c.Param("collectionID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	var input dto.ReorderBookmarkCollectionRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	if err := h.services.Bookmark.ReorderCollection(c.Request.Context(), int64(collectionID), user.ID, input.PostIDs); err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+// Returns uuid.Nil for anonymous users
+func (h *Handler) viewerID(c *gin.Context) uuid.UUID {
+	if user := h.getUserFromRequest(c); user != nil {
+		return user.ID
+	}
+	return uuid.Nil
+}
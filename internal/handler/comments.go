@@ -35,18 +35,22 @@ func (h *Handler) commentsGet(c *gin.Context) {
 		return
 	}
 
-	var input dto.GetCommentsDto
-	if err := c.ShouldBindJSON(&input); err != nil {
+	cursor, limit, err := cursorQuery(c)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
-	comments, err := h.services.Comment.FindPostComments(c.Request.Context(), int64(postID), input.Limit, input.Offset)
+	comments, err := h.services.Comment.FindPostComments(c.Request.Context(), int64(postID), cursor, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
+	if user := h.getUserFromRequest(c); user != nil {
+		h.services.Reaction.SetMyCommentReactions(c.Request.Context(), comments.Items, user.ID)
+	}
+
 	c.JSON(http.StatusOK, comments)
 }
 
@@ -62,22 +66,72 @@ func (h *Handler) commentsGetReplies(c *gin.Context) {
 		return
 	}
 
-	var input dto.GetCommentsDto
-	if err := c.ShouldBindJSON(&input); err != nil {
+	cursor, limit, err := cursorQuery(c)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
-	replies, err := h.services.Comment.FindCommentReplies(c.Request.Context(), int64(postID), int64(commentID), input.Limit, input.Offset)
+	replies, err := h.services.Comment.FindCommentReplies(c.Request.Context(), int64(postID), int64(commentID), cursor, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
+	if user := h.getUserFromRequest(c); user != nil {
+		h.services.Reaction.SetMyCommentReactions(c.Request.Context(), replies.Items, user.ID)
+	}
+
 	c.JSON(http.StatusOK, replies)
 }
 
+// Returns the post's comments tree, or the replies tree of ":commentID" if it's set
+func (h *Handler) commentsGetTree(c *gin.Context) {
+	postID, err := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidPostID.Error()))
+		return
+	}
+
+	var parentID *int64
+	if commentIDString := strings.TrimSpace(c.Param("commentID")); commentIDString != "" {
+		commentID, err := strconv.ParseInt(commentIDString, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+			return
+		}
+		parentID = &commentID
+	}
+
+	depth, err0 := strconv.Atoi(c.DefaultQuery("depth", "0"))
+	breadth, err1 := strconv.Atoi(c.DefaultQuery("breadth", "0"))
+	if err0 != nil || err1 != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errDepthAndBreadthMustBeInt.Error()))
+		return
+	}
+
+	tree, err := h.services.Comment.FindTree(c.Request.Context(), int64(postID), parentID, c.Query("sort"), c.Query("cursor"), depth, breadth)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	if user := h.getUserFromRequest(c); user != nil {
+		h.services.Reaction.SetMyCommentReactions(c.Request.Context(), tree.FullComments(), user.ID)
+	}
+
+	c.JSON(http.StatusOK, tree)
+}
+
 func (h *Handler) commentsDelete(c *gin.Context) {
+	h.deleteComment(c, false)
+}
+
+func (h *Handler) modCommentsDelete(c *gin.Context) {
+	h.deleteComment(c, true)
+}
+
+func (h *Handler) deleteComment(c *gin.Context, asModerator bool) {
 	user := h.getUserFromRequest(c)
 
 	postIDString := strings.TrimSpace(c.Param("postID"))
@@ -91,14 +145,68 @@ func (h *Handler) commentsDelete(c *gin.Context) {
 		return
 	}
 
-	if err := h.services.Comment.Delete(c.Request.Context(), int64(postID), int64(commentID), user.ID); err != nil {
-		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+	reason := strings.TrimSpace(c.Query("reason"))
+
+	if err := h.services.Comment.Delete(c.Request.Context(), int64(postID), int64(commentID), user.ID, asModerator, reason); err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
 	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
 }
 
+func (h *Handler) commentsEdit(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	postID, err0 := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
+	commentID, err1 := strconv.Atoi(strings.TrimSpace(c.Param("commentID")))
+	if err0 != nil || err1 != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	var input dto.EditCommentRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	comment, err := h.services.Comment.Edit(c.Request.Context(), int64(postID), int64(commentID), user.ID, input)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, comment)
+}
+
+func (h *Handler) commentsGetRevisions(c *gin.Context) {
+	h.getCommentRevisions(c, false)
+}
+
+func (h *Handler) modCommentsGetRevisions(c *gin.Context) {
+	h.getCommentRevisions(c, true)
+}
+
+func (h *Handler) getCommentRevisions(c *gin.Context, asModerator bool) {
+	user := h.getUserFromRequest(c)
+
+	postID, err0 := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
+	commentID, err1 := strconv.Atoi(strings.TrimSpace(c.Param("commentID")))
+	if err0 != nil || err1 != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	revisions, err := h.services.Comment.FindRevisions(c.Request.Context(), int64(postID), int64(commentID), user.ID, asModerator)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
 func (h *Handler) commentsIsLiked(c *gin.Context) {
 	user := h.getUserFromRequest(c)
 
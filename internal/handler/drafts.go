@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/BloggingApp/post-service/internal/dto"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) draftsCreate(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	var input dto.SaveDraftRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	draft, err := h.services.Post.CreateDraft(c.Request.Context(), user.ID, input)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, *draft)
+}
+
+func (h *Handler) draftsGetMy(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	cursor, limit, err := cursorQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	drafts, err := h.services.Post.FindAuthorDrafts(c.Request.Context(), user.ID, cursor, limit)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, drafts)
+}
+
+func (h *Handler) draftsGetByID(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	postID, err := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidPostID.Error()))
+		return
+	}
+
+	draft, err := h.services.Post.FindAuthorDraft(c.Request.Context(), int64(postID), user.ID)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, draft)
+}
+
+func (h *Handler) draftsUpdate(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	postID, err := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidPostID.Error()))
+		return
+	}
+
+	var input dto.EditDraftRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	if err := h.services.Post.UpdateDraft(c.Request.Context(), int64(postID), user.ID, input); err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) draftsAutosave(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	postID, err := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidPostID.Error()))
+		return
+	}
+
+	var input dto.AutosaveDraftRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	if err := h.services.Post.AutosaveDraft(c.Request.Context(), int64(postID), user.ID, input); err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) draftsDiscard(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	postID, err := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidPostID.Error()))
+		return
+	}
+
+	if err := h.services.Post.DiscardDraft(c.Request.Context(), int64(postID), user.ID); err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) draftsPublish(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	postID, err := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidPostID.Error()))
+		return
+	}
+
+	// Body is optional, it's only needed to schedule the post
+	var input dto.PublishDraftRequest
+	if err := c.ShouldBindJSON(&input); err != nil && err != io.EOF {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	post, err := h.services.Post.PublishDraft(c.Request.Context(), int64(postID), user.ID, input.PublishAt)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, *post)
+}
@@ -1,12 +1,58 @@
 package handler
 
-import "errors"
+import (
+	"errors"
+	"net/http"
+
+	"github.com/BloggingApp/post-service/internal/dto"
+	"github.com/BloggingApp/post-service/internal/service"
+)
 
 var (
 	errNotAuthorized = errors.New("user is not authorized")
 	errPositionMustBeInt = errors.New("position must be int")
 	errInvalidPostID = errors.New("invalid post ID")
 	errInvalidID = errors.New("invalid ID")
-	errHoursAndLimitMustBeInt = errors.New("hours and limit must be int")
 	errLimitAndOffsetMustBeInt = errors.New("limit and offset must be int")
+	errLimitMustBeInt = errors.New("limit must be int")
+	errFromAndToMustBeInt = errors.New("from and to must be int")
+	errDepthAndBreadthMustBeInt = errors.New("depth and breadth must be int")
+	errInvalidModeratorID = errors.New("invalid moderator ID")
+	errInvalidAuthorID = errors.New("invalid author ID")
+	errInvalidOutcome = errors.New("outcome must be approved or rejected")
+	errInvalidDate = errors.New("dates must be RFC3339 or YYYY-MM-DD")
 )
+
+const (
+	editPendingModerationDetails = "edit is pending moderation"
+	approvalPendingDetails = "approval is counted, post is waiting for more approvals"
+	approvedAfterEditDetails = "edited post is approved, no appeal is needed"
+)
+
+// Maps service errors to HTTP status codes, defaults to 500
+func errStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrPostNotFound), errors.Is(err, service.ErrDraftNotFound), errors.Is(err, service.ErrRevisionNotFound),
+		errors.Is(err, service.ErrTagNotFound), errors.Is(err, service.ErrBookmarkCollectionNotFound),
+		errors.Is(err, service.ErrCommentNotFound), errors.Is(err, service.ErrReportsNotFound), errors.Is(err, service.ErrNoPostsToModerate),
+		errors.Is(err, service.ErrAppealNotFound), errors.Is(err, service.ErrPostIsNotScheduled):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrDraftIsIncomplete), errors.Is(err, service.ErrPublishAtMustBeInFuture),
+		errors.Is(err, service.ErrSearchQueryIsEmpty), errors.Is(err, service.ErrInvalidTrendingWindow), errors.Is(err, dto.ErrInvalidCursor),
+		errors.Is(err, service.ErrInvalidCommentsSort),
+		errors.Is(err, service.ErrNoTags), errors.Is(err, service.ErrTooManyTags), errors.Is(err, service.ErrInvalidTagsMode),
+		errors.Is(err, service.ErrInvalidTag), errors.Is(err, service.ErrTagIsBanned),
+		errors.Is(err, service.ErrFailedToBookmarkThePost), errors.Is(err, service.ErrFailedToReact), errors.Is(err, service.ErrInvalidReactionType),
+		errors.Is(err, service.ErrFailedToReport), errors.Is(err, service.ErrInvalidReportReason), errors.Is(err, service.ErrInvalidReportTarget),
+		errors.Is(err, service.ErrInvalidReportAction), errors.Is(err, service.ErrPostAlreadyApprovedByModerator), errors.Is(err, service.ErrInvalidDateRange),
+		errors.Is(err, service.ErrTooManyDecisionsToExport), errors.Is(err, service.ErrPostIsNotAppealable):
+		return http.StatusBadRequest
+	case errors.Is(err, service.ErrCommentEditWindowExpired), errors.Is(err, service.ErrNotAllowedToDeleteComment),
+		errors.Is(err, service.ErrNotAllowedToViewCommentRevisions), errors.Is(err, service.ErrAppealRejectedByModerator):
+		return http.StatusForbidden
+	case errors.Is(err, service.ErrPostClaimedByAnotherModerator):
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
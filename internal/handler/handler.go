@@ -27,7 +27,7 @@ func (h *Handler) InitRoutes() *gin.Engine {
 
 	r.Use(cors.New(cors.Config{
 		AllowOrigins: []string{viper.GetString("client.origin")},
-		AllowMethods: []string{"POST", "GET", "PATCH", "DELETE"},
+		AllowMethods: []string{"POST", "GET", "PUT", "PATCH", "DELETE"},
 		AllowCredentials: true,
 	}))
 	
@@ -39,39 +39,142 @@ func (h *Handler) InitRoutes() *gin.Engine {
 			posts.POST("", h.authMiddleware, h.postsCreate)
 			posts.GET("/my", h.authMiddleware, h.postsGetMy)
 			posts.GET("/my/notValidated", h.authMiddleware, h.postsGetMyNotValidated)
+			posts.GET("/my/trash", h.authMiddleware, h.postsGetMyTrash)
+			posts.GET("/my/scheduled", h.authMiddleware, h.postsGetMyScheduled)
 			posts.GET("/author/:userID", h.postsGet)
 			posts.GET("/liked", h.authMiddleware, h.postsGetLiked)
+			posts.GET("/feed", h.authMiddleware, h.postsGetFeed)
 			posts.GET("/trending", h.authMiddleware, h.postsTrending)
-			posts.GET("/search", h.authMiddleware, h.postsSearchByTitle)
+			posts.GET("/search", h.authMiddleware, h.postsSearch)
+			posts.GET("/tags", h.postsGetByTags)
+			posts.GET("/tags/:tag", h.postsGetByTag)
 			posts.PATCH("/edit", h.authMiddleware, h.postsEdit)
 
+			drafts := posts.Group("/drafts", h.authMiddleware)
+			{
+				drafts.POST("", h.draftsCreate)
+				drafts.GET("", h.draftsGetMy)
+				drafts.GET("/:postID", h.draftsGetByID)
+				drafts.PATCH("/:postID", h.draftsUpdate)
+				drafts.PUT("/:postID/autosave", h.draftsAutosave)
+				drafts.DELETE("/:postID", h.draftsDiscard)
+				drafts.POST("/:postID/publish", h.draftsPublish)
+			}
+
 			post := posts.Group("/:postID")
 			{
 				post.GET("", h.notRequiredAuthMiddleware, h.postsGetByID)
+				post.GET("/related", h.postsGetRelated)
 				post.POST("/like", h.authMiddleware, h.postsLike)
 				post.DELETE("/unlike", h.authMiddleware, h.postsUnlike)
 				post.GET("/isLiked", h.authMiddleware, h.postsIsLiked)
+				post.POST("/bookmark", h.authMiddleware, h.postsBookmark)
+				post.DELETE("/unbookmark", h.authMiddleware, h.postsUnbookmark)
+				post.GET("/isBookmarked", h.authMiddleware, h.postsIsBookmarked)
+				post.PUT("/reaction", h.authMiddleware, h.postsReact)
+				post.DELETE("/reaction", h.authMiddleware, h.postsUnreact)
+				post.POST("/report", h.authMiddleware, h.postsReport)
+				post.POST("/appeal", h.authMiddleware, h.postsAppeal)
+				post.GET("/appeals", h.authMiddleware, h.postsGetAppeals)
+				post.DELETE("", h.authMiddleware, h.postsDelete)
+				post.POST("/restore", h.authMiddleware, h.postsRestore)
+				post.DELETE("/purge", h.authMiddleware, h.postsPurge)
+				post.PATCH("/schedule", h.authMiddleware, h.postsReschedule)
+				post.DELETE("/schedule", h.authMiddleware, h.postsCancelSchedule)
+				post.PUT("/claim", h.moderatorMiddleware, h.modClaimPost)
+				post.DELETE("/claim", h.moderatorMiddleware, h.modReleasePostClaim)
+
+				revisions := post.Group("/revisions", h.authMiddleware)
+				{
+					revisions.GET("", h.revisionsGet)
+					revisions.GET("/diff", h.revisionsDiff)
+					revisions.GET("/:revisionID", h.revisionsGetByID)
+					revisions.POST("/:revisionID/rollback", h.revisionsRollback)
+				}
 			}
 
 			posts.GET("/notValidated", h.moderatorMiddleware, h.modGetNotValidatedPosts)
+			posts.POST("/notValidated/next", h.moderatorMiddleware, h.modAssignNextPost)
 			posts.PATCH("/validationStatus", h.moderatorMiddleware, h.modUpdatePostValidationStatus)
 		}
 
+		tags := v1.Group("/tags")
+		{
+			tags.GET("", h.tagsGet)
+			tags.GET("/autocomplete", h.tagsAutocomplete)
+			tags.POST("/merge", h.moderatorMiddleware, h.modTagsMerge)
+			tags.PATCH("/:tag", h.moderatorMiddleware, h.modTagsRename)
+			tags.POST("/:tag/aliases", h.moderatorMiddleware, h.modTagsAddAlias)
+			tags.PUT("/:tag/ban", h.moderatorMiddleware, h.modTagsBan)
+			tags.DELETE("/:tag/ban", h.moderatorMiddleware, h.modTagsUnban)
+		}
+
+		v1.GET("/reactions", h.reactionsGetTypes)
+
+		reports := v1.Group("/reports")
+		{
+			reports.GET("/reasons", h.reportsGetReasons)
+			reports.GET("", h.moderatorMiddleware, h.modReportsGet)
+			reports.GET("/:target/:targetID", h.moderatorMiddleware, h.modReportsGetTarget)
+			reports.POST("/:target/:targetID/resolve", h.moderatorMiddleware, h.modReportsResolve)
+		}
+
+		appeals := v1.Group("/appeals", h.moderatorMiddleware)
+		{
+			appeals.GET("", h.modAppealsGet)
+			appeals.POST("/:appealID/decide", h.modAppealsDecide)
+		}
+
+		moderation := v1.Group("/moderation", h.adminMiddleware)
+		{
+			moderation.GET("/decisions", h.adminModerationDecisions)
+			moderation.GET("/posts/:postID/decisions", h.adminPostModerationDecisions)
+			moderation.GET("/stats", h.adminModeratorStats)
+		}
+
+		bookmarks := v1.Group("/bookmarks")
+		{
+			bookmarks.GET("", h.authMiddleware, h.bookmarksGetMy)
+			bookmarks.GET("/users/:userID/collections", h.collectionsGetUser)
+
+			collections := bookmarks.Group("/collections")
+			{
+				collections.POST("", h.authMiddleware, h.collectionsCreate)
+				collections.GET("", h.authMiddleware, h.collectionsGetMy)
+				collections.GET("/:collectionID", h.notRequiredAuthMiddleware, h.collectionsGetByID)
+				collections.GET("/:collectionID/posts", h.notRequiredAuthMiddleware, h.collectionsGetPosts)
+				collections.PATCH("/:collectionID", h.authMiddleware, h.collectionsUpdate)
+				collections.DELETE("/:collectionID", h.authMiddleware, h.collectionsDelete)
+				collections.POST("/:collectionID/posts", h.authMiddleware, h.collectionsAddPost)
+				collections.DELETE("/:collectionID/posts/:postID", h.authMiddleware, h.collectionsRemovePost)
+				collections.PUT("/:collectionID/order", h.authMiddleware, h.collectionsReorder)
+			}
+		}
+
 		comments := v1.Group("/comments")
 		{
 			comments.POST("", h.authMiddleware, h.commentsCreate)
 
 			postComments := comments.Group("/:postID")
 			{
-				postComments.GET("", h.commentsGet)
+				postComments.GET("", h.notRequiredAuthMiddleware, h.commentsGet)
+				postComments.GET("/tree", h.notRequiredAuthMiddleware, h.commentsGetTree)
 
 				comment := postComments.Group("/:commentID")
 				{
-					comment.GET("/replies", h.commentsGetReplies)
+					comment.GET("/replies", h.notRequiredAuthMiddleware, h.commentsGetReplies)
+					comment.GET("/tree", h.notRequiredAuthMiddleware, h.commentsGetTree)
 					comment.DELETE("", h.authMiddleware, h.commentsDelete)
+					comment.DELETE("/mod", h.moderatorMiddleware, h.modCommentsDelete)
+					comment.PATCH("", h.authMiddleware, h.commentsEdit)
+					comment.GET("/revisions", h.authMiddleware, h.commentsGetRevisions)
+					comment.GET("/revisions/mod", h.moderatorMiddleware, h.modCommentsGetRevisions)
 					comment.GET("/isLiked", h.authMiddleware, h.commentsIsLiked)
 					comment.POST("/like", h.authMiddleware, h.commentsLike)
 					comment.DELETE("/unlike", h.authMiddleware, h.commentsUnlike)
+					comment.PUT("/reaction", h.authMiddleware, h.commentsReact)
+					comment.DELETE("/reaction", h.authMiddleware, h.commentsUnreact)
+					comment.POST("/report", h.authMiddleware, h.commentsReport)
 				}
 			}
 		}
@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BloggingApp/post-service/internal/dto"
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const dateLayout = "2006-01-02"
+
+// Reads RFC3339 time or a date, date-only "to" includes the whole day
+func parseFilterTime(value string, endOfDay bool) (*time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+
+	t, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return nil, errInvalidDate
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+
+	return &t, nil
+}
+
+// Reads "moderator_id", "post_id", "author_id", "outcome" (approved or rejected), "from" and "to" query params
+func decisionsFilterQuery(c *gin.Context) (model.ModerationDecisionsFilter, error) {
+	var filter model.ModerationDecisionsFilter
+
+	if value := strings.TrimSpace(c.Query("moderator_id")); value != "" {
+		moderatorID, err := uuid.Parse(value)
+		if err != nil {
+			return filter, errInvalidModeratorID
+		}
+		filter.ModeratorID = &moderatorID
+	}
+
+	if value := strings.TrimSpace(c.Query("post_id")); value != "" {
+		postID, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return filter, errInvalidPostID
+		}
+		filter.PostID = &postID
+	}
+
+	if value := strings.TrimSpace(c.Query("author_id")); value != "" {
+		authorID, err := uuid.Parse(value)
+		if err != nil {
+			return filter, errInvalidAuthorID
+		}
+		filter.AuthorID = &authorID
+	}
+
+	switch c.Query("outcome") {
+	case "":
+	case model.MODERATION_OUTCOME_APPROVED:
+		validated := true
+		filter.Validated = &validated
+	case model.MODERATION_OUTCOME_REJECTED:
+		validated := false
+		filter.Validated = &validated
+	default:
+		return filter, errInvalidOutcome
+	}
+
+	if value := strings.TrimSpace(c.Query("from")); value != "" {
+		from, err := parseFilterTime(value, false)
+		if err != nil {
+			return filter, err
+		}
+		filter.From = from
+	}
+
+	if value := strings.TrimSpace(c.Query("to")); value != "" {
+		to, err := parseFilterTime(value, true)
+		if err != nil {
+			return filter, err
+		}
+		filter.To = to
+	}
+
+	return filter, nil
+}
+
+func (h *Handler) adminModerationDecisions(c *gin.Context) {
+	filter, err := decisionsFilterQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	h.moderationDecisions(c, filter)
+}
+
+func (h *Handler) adminPostModerationDecisions(c *gin.Context) {
+	postID, err := strconv.ParseInt(strings.TrimSpace(c.Param("postID")), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidPostID.Error()))
+		return
+	}
+
+	filter, err := decisionsFilterQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+	filter.PostID = &postID
+
+	h.moderationDecisions(c, filter)
+}
+
+// Responds with a page of decisions or with all of them as CSV if "format" is csv
+func (h *Handler) moderationDecisions(c *gin.Context, filter model.ModerationDecisionsFilter) {
+	if c.Query("format") == "csv" {
+		decisions, err := h.services.ModerationAudit.ExportDecisions(c.Request.Context(), filter)
+		if err != nil {
+			c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+			return
+		}
+
+		writeDecisionsCSV(c, decisions)
+		return
+	}
+
+	cursor, limit, err := cursorQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	decisions, err := h.services.ModerationAudit.FindDecisions(c.Request.Context(), filter, cursor, limit)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, decisions)
+}
+
+func writeDecisionsCSV(c *gin.Context, decisions []*model.ModerationDecision) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="moderation-decisions-%s.csv"`, time.Now().UTC().Format(dateLayout)))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"id", "post_id", "revision_id", "author_id", "moderator_id", "outcome", "status_msg", "submitted_at", "created_at"})
+	for _, decision := range decisions {
+		outcome := model.MODERATION_OUTCOME_REJECTED
+		if decision.Validated {
+			outcome = model.MODERATION_OUTCOME_APPROVED
+		}
+
+		revisionID, authorID, statusMsg, submittedAt := "", "", "", ""
+		if decision.RevisionID != nil {
+			revisionID = strconv.FormatInt(*decision.RevisionID, 10)
+		}
+		if decision.AuthorID != nil {
+			authorID = decision.AuthorID.String()
+		}
+		if decision.StatusMsg != nil {
+			statusMsg = *decision.StatusMsg
+		}
+		if decision.SubmittedAt != nil {
+			submittedAt = decision.SubmittedAt.UTC().Format(time.RFC3339)
+		}
+
+		w.Write([]string{
+			strconv.FormatInt(decision.ID, 10),
+			strconv.FormatInt(decision.PostID, 10),
+			revisionID,
+			authorID,
+			decision.ModeratorID.String(),
+			outcome,
+			statusMsg,
+			submittedAt,
+			decision.CreatedAt.UTC().Format(time.RFC3339),
+		})
+	}
+	w.Flush()
+}
+
+func (h *Handler) adminModeratorStats(c *gin.Context) {
+	filter, err := decisionsFilterQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	stats, err := h.services.ModerationAudit.FindModeratorStats(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, stats)
+}
@@ -0,0 +1,28 @@
+package handler
+
+import (
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Reads "cursor" and "limit" query params, missing limit is 0 so the service uses the default page size
+func cursorQuery(c *gin.Context) (string, int, error) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "0"))
+	if err != nil {
+		return "", 0, errLimitMustBeInt
+	}
+
+	return c.Query("cursor"), limit, nil
+}
+
+// Reads "limit" and "offset" query params for lists that are still paginated with offsets
+func offsetQuery(c *gin.Context) (int, int, error) {
+	limit, err0 := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(5)))
+	offset, err1 := strconv.Atoi(c.DefaultQuery("offset", "0"))
+	if err0 != nil || err1 != nil {
+		return 0, 0, errLimitAndOffsetMustBeInt
+	}
+
+	return limit, offset, nil
+}
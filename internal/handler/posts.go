@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/BloggingApp/post-service/internal/dto"
+	"github.com/BloggingApp/post-service/internal/model"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 )
@@ -37,7 +38,7 @@ func (h *Handler) postsCreate(c *gin.Context) {
 
 	createdPost, err := h.services.Post.Create(c.Request.Context(), user.ID, input)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
@@ -47,15 +48,15 @@ func (h *Handler) postsCreate(c *gin.Context) {
 func (h *Handler) postsGetMy(c *gin.Context) {
 	user := h.getUserFromRequest(c)
 
-	var input dto.GetPostsRequest
-	if err := c.ShouldBindJSON(&input); err != nil {
+	cursor, limit, err := cursorQuery(c)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
-	posts, err := h.services.Post.FindAuthorPosts(c.Request.Context(), user.ID, input.Limit, input.Offset)
+	posts, err := h.services.Post.FindAuthorPosts(c.Request.Context(), user.ID, cursor, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
@@ -65,15 +66,15 @@ func (h *Handler) postsGetMy(c *gin.Context) {
 func (h *Handler) postsGetMyNotValidated(c *gin.Context) {
 	user := h.getUserFromRequest(c)
 
-	var input dto.GetPostsRequest
-	if err := c.ShouldBindJSON(&input); err != nil {
+	cursor, limit, err := cursorQuery(c)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
-	posts, err := h.services.Post.FindUserNotValidatedPosts(c.Request.Context(), user.ID, input.Limit, input.Offset)
+	posts, err := h.services.Post.FindUserNotValidatedPosts(c.Request.Context(), user.ID, cursor, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
@@ -103,14 +104,32 @@ func (h *Handler) postsGetByID(c *gin.Context) {
 	if user != nil {
 		isLiked := h.services.Post.IsLiked(c.Request.Context(), post.Post.ID, user.ID)
 		postDto.IsLiked = isLiked
+		postDto.IsBookmarked = h.services.Bookmark.IsBookmarked(c.Request.Context(), post.Post.ID, user.ID)
+		postDto.MyReaction = h.services.Reaction.FindMyReaction(c.Request.Context(), model.REACTION_TARGET_POST, post.Post.ID, user.ID)
 	}
 
 	c.JSON(http.StatusOK, postDto)
 }
 
+func (h *Handler) postsGetRelated(c *gin.Context) {
+	postID, err := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidPostID.Error()))
+		return
+	}
+
+	posts, err := h.services.Post.FindRelated(c.Request.Context(), int64(postID))
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, posts)
+}
+
 func (h *Handler) postsGet(c *gin.Context) {
-	var input dto.GetPostsRequest
-	if err := c.ShouldBindJSON(&input); err != nil {
+	cursor, limit, err := cursorQuery(c)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
 		return
 	}
@@ -122,9 +141,9 @@ func (h *Handler) postsGet(c *gin.Context) {
 		return
 	}
 
-	posts, err := h.services.Post.FindAuthorPosts(c.Request.Context(), userID, input.Limit, input.Offset)
+	posts, err := h.services.Post.FindAuthorPosts(c.Request.Context(), userID, cursor, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
@@ -185,15 +204,33 @@ func (h *Handler) postsUnlike(c *gin.Context) {
 func (h *Handler) postsGetLiked(c *gin.Context) {
 	user := h.getUserFromRequest(c)
 
-	var input dto.GetPostsRequest
-	if err := c.ShouldBindJSON(&input); err != nil {
+	cursor, limit, err := cursorQuery(c)
+	if err != nil {
 		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
-	posts, err := h.services.Post.FindUserLikes(c.Request.Context(), user.ID, input.Limit, input.Offset)
+	posts, err := h.services.Post.FindUserLikes(c.Request.Context(), user.ID, cursor, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, posts)
+}
+
+func (h *Handler) postsGetFeed(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	cursor, limit, err := cursorQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	posts, err := h.services.Post.FindFeed(c.Request.Context(), user.ID, cursor, limit)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
@@ -201,34 +238,32 @@ func (h *Handler) postsGetLiked(c *gin.Context) {
 }
 
 func (h *Handler) postsTrending(c *gin.Context) {
-	hours, err0 := strconv.Atoi(c.Query("hours"))
-	limit, err1 := strconv.Atoi(c.Query("limit"))
-	if err0 != nil || err1 != nil {
-		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errHoursAndLimitMustBeInt.Error()))
+	cursor, limit, err := cursorQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
-	posts, err := h.services.Post.GetTrending(c.Request.Context(), hours, limit)
+	posts, err := h.services.Post.FindTrending(c.Request.Context(), c.Query("window"), c.Query("tag"), cursor, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
 	c.JSON(http.StatusOK, posts)
 }
 
-func (h *Handler) postsSearchByTitle(c *gin.Context) {
-	limit, err0 := strconv.Atoi(c.Query("limit"))
-	offset, err1 := strconv.Atoi(c.Query("offset"))
-	if err0 != nil || err1 != nil {
-		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errLimitAndOffsetMustBeInt.Error()))
+func (h *Handler) postsSearch(c *gin.Context) {
+	cursor, limit, err := cursorQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
 		return
 	}
-	title := c.Query("q")
+	query := c.Query("q")
 
-	result, err := h.services.Post.SearchByTitle(c.Request.Context(), title, limit, offset)
+	result, err := h.services.Post.Search(c.Request.Context(), query, cursor, limit)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
@@ -245,24 +280,157 @@ func (h *Handler) postsEdit(c *gin.Context) {
 	}
 	input.AuthorID = user.ID
 
-	if err := h.services.Post.Edit(c.Request.Context(), input); err != nil {
-		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+	pending, err := h.services.Post.Edit(c.Request.Context(), input)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	if pending {
+		c.JSON(http.StatusOK, dto.NewBasicResponse(true, editPendingModerationDetails))
 		return
 	}
 
 	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
 }
 
-func (h *Handler) modGetNotValidatedPosts(c *gin.Context) {
-	var input dto.GetPostsRequest
+func (h *Handler) postsDelete(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	postID, err := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidPostID.Error()))
+		return
+	}
+
+	if err := h.services.Post.Delete(c.Request.Context(), int64(postID), user.ID); err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) postsRestore(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	postID, err := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidPostID.Error()))
+		return
+	}
+
+	if err := h.services.Post.Restore(c.Request.Context(), int64(postID), user.ID); err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) postsPurge(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	postID, err := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidPostID.Error()))
+		return
+	}
+
+	if err := h.services.Post.Purge(c.Request.Context(), int64(postID), user.ID); err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) postsGetMyTrash(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	cursor, limit, err := cursorQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	posts, err := h.services.Post.FindAuthorDeletedPosts(c.Request.Context(), user.ID, cursor, limit)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, posts)
+}
+
+func (h *Handler) postsGetMyScheduled(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	cursor, limit, err := cursorQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	posts, err := h.services.Post.FindAuthorScheduledPosts(c.Request.Context(), user.ID, cursor, limit)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, posts)
+}
+
+func (h *Handler) postsReschedule(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	postID, err := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidPostID.Error()))
+		return
+	}
+
+	var input dto.SchedulePostRequest
 	if err := c.ShouldBindJSON(&input); err != nil {
 		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
-	posts, err := h.services.Post.FindNotValidatedPosts(c.Request.Context(), input.Limit, input.Offset)
+	if err := h.services.Post.Reschedule(c.Request.Context(), int64(postID), user.ID, input.PublishAt); err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) postsCancelSchedule(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	postID, err := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidPostID.Error()))
+		return
+	}
+
+	if err := h.services.Post.CancelSchedule(c.Request.Context(), int64(postID), user.ID); err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) modGetNotValidatedPosts(c *gin.Context) {
+	cursor, limit, err := cursorQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	posts, err := h.services.Post.FindNotValidatedPosts(c.Request.Context(), cursor, c.Query("edits_cursor"), limit)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
@@ -278,8 +446,71 @@ func (h *Handler) modUpdatePostValidationStatus(c *gin.Context) {
 		return
 	}
 
-	if err := h.services.Post.UpdateValidationStatus(c.Request.Context(), input.PostID, moderator.ID, input.Validated, input.StatusMsg); err != nil {
-		c.JSON(http.StatusInternalServerError, dto.NewBasicResponse(false, err.Error()))
+	if input.RevisionID != nil {
+		if err := h.services.Post.ReviewRevision(c.Request.Context(), *input.RevisionID, moderator.ID, input.Validated, input.StatusMsg); err != nil {
+			c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+			return
+		}
+
+		c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+		return
+	}
+
+	applied, err := h.services.Post.UpdateValidationStatus(c.Request.Context(), input.PostID, moderator.ID, input.Validated, input.StatusMsg)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	if !applied {
+		c.JSON(http.StatusOK, dto.NewBasicResponse(true, approvalPendingDetails))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) modAssignNextPost(c *gin.Context) {
+	moderator := h.getUserFromRequest(c)
+
+	assignment, err := h.services.Post.AssignNextPost(c.Request.Context(), moderator.ID)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, assignment)
+}
+
+func (h *Handler) modClaimPost(c *gin.Context) {
+	moderator := h.getUserFromRequest(c)
+
+	postID, err := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidPostID.Error()))
+		return
+	}
+
+	claim, err := h.services.Post.ClaimPost(c.Request.Context(), int64(postID), moderator.ID)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, claim)
+}
+
+func (h *Handler) modReleasePostClaim(c *gin.Context) {
+	moderator := h.getUserFromRequest(c)
+
+	postID, err := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidPostID.Error()))
+		return
+	}
+
+	if err := h.services.Post.ReleasePostClaim(c.Request.Context(), int64(postID), moderator.ID); err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
 		return
 	}
 
@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/BloggingApp/post-service/internal/dto"
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) reactionsGetTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, h.services.Reaction.ReactionTypes())
+}
+
+func (h *Handler) postsReact(c *gin.Context) {
+	postID, err := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidPostID.Error()))
+		return
+	}
+
+	h.react(c, model.REACTION_TARGET_POST, int64(postID))
+}
+
+func (h *Handler) postsUnreact(c *gin.Context) {
+	postID, err := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidPostID.Error()))
+		return
+	}
+
+	h.unreact(c, model.REACTION_TARGET_POST, int64(postID))
+}
+
+func (h *Handler) commentsReact(c *gin.Context) {
+	commentID, err := strconv.Atoi(strings.TrimSpace(c.Param("commentID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	h.react(c, model.REACTION_TARGET_COMMENT, int64(commentID))
+}
+
+func (h *Handler) commentsUnreact(c *gin.Context) {
+	commentID, err := strconv.Atoi(strings.TrimSpace(c.Param("commentID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	h.unreact(c, model.REACTION_TARGET_COMMENT, int64(commentID))
+}
+
+func (h *Handler) react(c *gin.Context, target string, targetID int64) {
+	user := h.getUserFromRequest(c)
+
+	var input dto.ReactRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	if err := h.services.Reaction.React(c.Request.Context(), target, targetID, user.ID, input.Type); err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) unreact(c *gin.Context, target string, targetID int64) {
+	user := h.getUserFromRequest(c)
+
+	if err := h.services.Reaction.Unreact(c.Request.Context(), target, targetID, user.ID); err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
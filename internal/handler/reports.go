@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/BloggingApp/post-service/internal/dto"
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) reportsGetReasons(c *gin.Context) {
+	c.JSON(http.StatusOK, h.services.Report.ReportReasons())
+}
+
+func (h *Handler) postsReport(c *gin.Context) {
+	postID, err := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidPostID.Error()))
+		return
+	}
+
+	h.report(c, model.REPORT_TARGET_POST, int64(postID))
+}
+
+func (h *Handler) commentsReport(c *gin.Context) {
+	commentID, err := strconv.Atoi(strings.TrimSpace(c.Param("commentID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	h.report(c, model.REPORT_TARGET_COMMENT, int64(commentID))
+}
+
+func (h *Handler) report(c *gin.Context, target string, targetID int64) {
+	user := h.getUserFromRequest(c)
+
+	var input dto.ReportRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	if err := h.services.Report.Report(c.Request.Context(), target, targetID, user.ID, input); err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) modReportsGet(c *gin.Context) {
+	cursor, limit, err := cursorQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	targets, err := h.services.Report.FindQueue(c.Request.Context(), c.Query("target"), cursor, limit)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, targets)
+}
+
+func (h *Handler) modReportsGetTarget(c *gin.Context) {
+	targetID, err := strconv.Atoi(strings.TrimSpace(c.Param("targetID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	reports, err := h.services.Report.FindTargetReports(c.Request.Context(), c.Param("target"), int64(targetID))
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, reports)
+}
+
+func (h *Handler) modReportsResolve(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	targetID, err := strconv.Atoi(strings.TrimSpace(c.Param("targetID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	var input dto.ResolveReportsRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	resolution, err := h.services.Report.Resolve(c.Request.Context(), c.Param("target"), int64(targetID), user.ID, input)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, resolution)
+}
@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/BloggingApp/post-service/internal/dto"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) revisionsGet(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	postID, err := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidPostID.Error()))
+		return
+	}
+
+	cursor, limit, err := cursorQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	revisions, err := h.services.Post.FindRevisions(c.Request.Context(), int64(postID), user.ID, cursor, limit)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, revisions)
+}
+
+func (h *Handler) revisionsGetByID(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	postID, err0 := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
+	revisionID, err1 := strconv.Atoi(strings.TrimSpace(c.Param("revisionID")))
+	if err0 != nil || err1 != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	revision, err := h.services.Post.FindRevision(c.Request.Context(), int64(postID), int64(revisionID), user.ID)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, revision)
+}
+
+func (h *Handler) revisionsDiff(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	postID, err := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidPostID.Error()))
+		return
+	}
+
+	from, err0 := strconv.Atoi(c.Query("from"))
+	to, err1 := strconv.Atoi(c.Query("to"))
+	if err0 != nil || err1 != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errFromAndToMustBeInt.Error()))
+		return
+	}
+
+	diff, err := h.services.Post.DiffRevisions(c.Request.Context(), int64(postID), user.ID, int64(from), int64(to))
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, diff)
+}
+
+func (h *Handler) revisionsRollback(c *gin.Context) {
+	user := h.getUserFromRequest(c)
+
+	postID, err0 := strconv.Atoi(strings.TrimSpace(c.Param("postID")))
+	revisionID, err1 := strconv.Atoi(strings.TrimSpace(c.Param("revisionID")))
+	if err0 != nil || err1 != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, errInvalidID.Error()))
+		return
+	}
+
+	pending, err := h.services.Post.Rollback(c.Request.Context(), int64(postID), int64(revisionID), user.ID)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	if pending {
+		c.JSON(http.StatusOK, dto.NewBasicResponse(true, editPendingModerationDetails))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"net/http"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/BloggingApp/post-service/internal/dto"
@@ -11,6 +12,15 @@ import (
 )
 
 func (h *Handler) moderatorMiddleware(c *gin.Context) {
+	h.roleMiddleware(c, "mod", "admin")
+}
+
+func (h *Handler) adminMiddleware(c *gin.Context) {
+	h.roleMiddleware(c, "admin")
+}
+
+// Lets the request through only if the access token's role is one of roles
+func (h *Handler) roleMiddleware(c *gin.Context, roles ...string) {
 	header := c.GetHeader("Authorization")
 	if !strings.HasPrefix(header, "Bearer ") {
 		c.JSON(http.StatusUnauthorized, dto.NewBasicResponse(false, errNotAuthorized.Error()))
@@ -32,8 +42,8 @@ func (h *Handler) moderatorMiddleware(c *gin.Context) {
 		return
 	}
 
-	role := strings.ToLower(claims["role"].(string))
-	if role != "mod" && role != "admin" {
+	role, ok := claims["role"].(string)
+	if !ok || !slices.Contains(roles, strings.ToLower(role)) {
 		c.JSON(http.StatusForbidden, dto.NewBasicResponse(false, "no access"))
 		c.Abort()
 		return
@@ -46,7 +56,7 @@ func (h *Handler) moderatorMiddleware(c *gin.Context) {
 		return
 	}
 
-	c.Set("cached-user", *user)
+	c.Set("user", *user)
 
 	c.Next()
 }
@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/BloggingApp/post-service/internal/dto"
+	"github.com/gin-gonic/gin"
+)
+
+func (h *Handler) postsGetByTag(c *gin.Context) {
+	cursor, limit, err := cursorQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	posts, err := h.services.Post.FindByTags(c.Request.Context(), []string{c.Param("tag")}, "", cursor, limit)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, posts)
+}
+
+func (h *Handler) postsGetByTags(c *gin.Context) {
+	cursor, limit, err := cursorQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+	tags := strings.Split(c.Query("tags"), ",")
+
+	posts, err := h.services.Post.FindByTags(c.Request.Context(), tags, c.Query("mode"), cursor, limit)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, posts)
+}
+
+func (h *Handler) tagsGet(c *gin.Context) {
+	limit, offset, err := offsetQuery(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	tags, err := h.services.Tag.FindTags(c.Request.Context(), c.Query("prefix"), limit, offset)
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+func (h *Handler) tagsAutocomplete(c *gin.Context) {
+	tags, err := h.services.Tag.Autocomplete(c.Request.Context(), c.Query("q"))
+	if err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+func (h *Handler) modTagsMerge(c *gin.Context) {
+	var input dto.MergeTagsRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	if err := h.services.Tag.Merge(c.Request.Context(), input.From, input.To); err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) modTagsRename(c *gin.Context) {
+	var input dto.RenameTagRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	if err := h.services.Tag.Rename(c.Request.Context(), c.Param("tag"), input.Name); err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) modTagsAddAlias(c *gin.Context) {
+	var input dto.AddTagAliasRequest
+	if err := c.ShouldBindJSON(&input); err != nil {
+		c.JSON(http.StatusBadRequest, dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	if err := h.services.Tag.AddAlias(c.Request.Context(), c.Param("tag"), input.Alias); err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) modTagsBan(c *gin.Context) {
+	if err := h.services.Tag.SetBanned(c.Request.Context(), c.Param("tag"), true); err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
+
+func (h *Handler) modTagsUnban(c *gin.Context) {
+	if err := h.services.Tag.SetBanned(c.Request.Context(), c.Param("tag"), false); err != nil {
+		c.JSON(errStatus(err), dto.NewBasicResponse(false, err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.NewBasicResponse(true, ""))
+}
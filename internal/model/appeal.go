@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Appeal statuses
+const (
+	APPEAL_STATUS_PENDING = "pending"
+	APPEAL_STATUS_UPHELD = "upheld"         // the rejection stands
+	APPEAL_STATUS_OVERTURNED = "overturned" // the post is validated
+)
+
+// Author's appeal against the rejection of their post, decided by a moderator other than the rejecting one
+type PostAppeal struct {
+	ID            int64      `json:"id"`
+	PostID        int64      `json:"post_id"`
+	AuthorID      uuid.UUID  `json:"author_id"`
+	RejectionID   int64      `json:"rejection_id"` // appealed moderation decision
+	RejectedBy    uuid.UUID  `json:"rejected_by"`
+	RejectionMsg  *string    `json:"rejection_msg"`
+	Justification string     `json:"justification"`
+	Edited        bool       `json:"edited"` // true if the post was edited with the appeal
+	Status        string     `json:"status"`
+	ModeratorID   *uuid.UUID `json:"moderator_id"`
+	DecisionMsg   *string    `json:"decision_msg"`
+	CreatedAt     time.Time  `json:"created_at"`
+	DecidedAt     *time.Time `json:"decided_at"`
+}
+
+// Pending appeal with the appealed post in the appeals queue
+type AppealedPost struct {
+	Appeal PostAppeal `json:"appeal"`
+	Post   FullPost   `json:"post"`
+}
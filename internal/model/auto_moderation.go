@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Moderator ID of decisions made by auto-moderation
+var AUTO_MODERATOR_ID = uuid.Nil
+
+// Auto-moderation decisions
+const (
+	AUTO_MODERATION_APPROVE = "approve" // trusted author without flags
+	AUTO_MODERATION_REJECT = "reject"   // risk score reached the reject score
+	AUTO_MODERATION_REVIEW = "review"   // left for moderators
+)
+
+// Rule triggered by a post, scores of all flags add up to the post's risk score
+type AutoModerationFlag struct {
+	Rule    string  `json:"rule"`
+	Score   float64 `json:"score"`
+	Details string  `json:"details"`
+}
+
+type AutoModerationResult struct {
+	Decision  string               `json:"decision"`
+	RiskScore float64              `json:"risk_score"` // 0-1
+	Flags     []AutoModerationFlag `json:"flags"`
+	Reason    string               `json:"reason"` // rejection reason sent to the author
+	CheckedAt time.Time            `json:"checked_at"`
+}
+
+// Author's moderation history used by auto-moderation
+type AuthorHistory struct {
+	ValidatedPosts int64
+	Rejections     int64
+	RecentPosts    int64      // posts created in the last 24 hours
+	FirstPostAt    *time.Time // nil if the author has no posts yet
+}
+
+// Post in the moderation queue with its latest auto-moderation result, nil if it wasn't checked
+type QueuedPost struct {
+	FullPost
+	AutoModeration *AutoModerationResult `json:"auto_moderation"`
+}
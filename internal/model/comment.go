@@ -6,6 +6,24 @@ import (
 	"github.com/google/uuid"
 )
 
+// Content of deleted comments kept because of their replies
+const COMMENT_TOMBSTONE_CONTENT = "[deleted]"
+
+// Who deleted a comment
+const (
+	COMMENT_DELETER_AUTHOR = "author"
+	COMMENT_DELETER_POST_AUTHOR = "post_author"
+	COMMENT_DELETER_MODERATOR = "moderator"
+)
+
+// Comment tree sort modes
+const (
+	COMMENTS_SORT_TOP = "top"
+	COMMENTS_SORT_NEWEST = "newest"
+	COMMENTS_SORT_OLDEST = "oldest"
+	COMMENTS_SORT_CONTROVERSIAL = "controversial"
+)
+
 type Comment struct {
 	ID        int64     `json:"id"`
 	ParentID  *int64    `json:"parent_id"`
@@ -13,10 +31,34 @@ type Comment struct {
 	AuthorID  uuid.UUID `json:"author_id"`
 	Content   string    `json:"content"`
 	Likes     int64     `json:"likes"`
+	Reactions ReactionCounts `json:"reactions"`
+	Mentions  []Mention `json:"mentions"`
 	CreatedAt time.Time `json:"created_at"`
+	EditedAt  *time.Time `json:"edited_at"` // nil if the comment wasn't edited
+	DeletedAt *time.Time `json:"deleted_at"` // set for tombstones, their content and author are hidden
+	HiddenAt  *time.Time `json:"hidden_at"` // set if hidden by reports, its content is hidden
+}
+
+// Comment of a flattened comment tree
+type CommentTreeEntry struct {
+	Comment      FullComment
+	RepliesCount int64
+	Depth        int   // 1 for top level comments
+	Position     int64 // position among siblings starting from 1
+	Cursor       Cursor
+}
+
+// Previous version of an edited comment
+type CommentRevision struct {
+	ID         int64     `json:"id"`
+	CommentID  int64     `json:"comment_id"`
+	Content    string    `json:"content"`
+	CreatedAt  time.Time `json:"created_at"`  // when this version was written
+	ReplacedAt time.Time `json:"replaced_at"` // when this version was replaced by an edit
 }
 
 type FullComment struct {
-	Comment Comment    `json:"comment"`
-	Author  UserAuthor `json:"author"`
+	Comment    Comment    `json:"comment"`
+	Author     UserAuthor `json:"author"`
+	MyReaction *string    `json:"my_reaction,omitempty"` // set only for authorized requests
 }
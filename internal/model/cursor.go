@@ -0,0 +1,11 @@
+package model
+
+import "time"
+
+// Position of the last item of a page in keyset pagination.
+// Lists are ordered by (score, time, id), score is 0 for lists ordered by time only
+type Cursor struct {
+	Score     float64   `json:"s,omitempty"`
+	CreatedAt time.Time `json:"t"`
+	ID        int64     `json:"i"`
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Follow struct {
+	FollowerID uuid.UUID `json:"follower_id"`
+	FolloweeID uuid.UUID `json:"followee_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type Followee struct {
+	ID             uuid.UUID `json:"id"`
+	FollowersCount int64     `json:"followers_count"`
+}
+
+// Post in a user's feed timeline
+type TimelineEntry struct {
+	PostID    int64     `json:"post_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import "github.com/google/uuid"
+
+// Mention targets, each has its own table of mentioned users
+const (
+	MENTION_TARGET_POST = "post"
+	MENTION_TARGET_COMMENT = "comment"
+)
+
+// Resolved @username in post or comment content, offset and length are in characters (runes) and include "@"
+type Mention struct {
+	UserID   uuid.UUID `json:"user_id"`
+	Username string    `json:"username"`
+	Offset   int       `json:"offset"`
+	Length   int       `json:"length"`
+}
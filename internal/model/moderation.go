@@ -0,0 +1,56 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Moderator's lease on a post in the moderation queue, others can't decide on the post until it expires
+type ModerationClaim struct {
+	PostID      int64     `json:"post_id"`
+	ModeratorID uuid.UUID `json:"moderator_id"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// Moderation decision outcomes
+const (
+	MODERATION_OUTCOME_APPROVED = "approved"
+	MODERATION_OUTCOME_REJECTED = "rejected"
+)
+
+// Moderator's decision on a post or its pending edit
+type ModerationDecision struct {
+	ID          int64      `json:"id"`
+	PostID      int64      `json:"post_id"`
+	RevisionID  *int64     `json:"revision_id"` // set for decisions on pending edits
+	AuthorID    *uuid.UUID `json:"author_id"`   // nil if the post is purged
+	ModeratorID uuid.UUID  `json:"moderator_id"`
+	Validated   bool       `json:"validated"`
+	StatusMsg   *string    `json:"status_msg"`
+	SubmittedAt *time.Time `json:"submitted_at"` // when the post entered the moderation queue, the edit or the appeal was made. nil for old decisions of purged posts
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// Moderation decisions filter, nil fields match everything. From is inclusive, To is exclusive
+type ModerationDecisionsFilter struct {
+	ModeratorID *uuid.UUID
+	PostID      *int64
+	AuthorID    *uuid.UUID
+	Validated   *bool
+	From        *time.Time
+	To          *time.Time
+}
+
+type ModeratorStats struct {
+	ModeratorID          uuid.UUID `json:"moderator_id"`
+	Username             *string   `json:"username"`
+	Decisions            int64     `json:"decisions"`
+	Approvals            int64     `json:"approvals"`
+	Rejections           int64     `json:"rejections"`
+	ApprovalRate         float64   `json:"approval_rate"`              // approvals / decisions
+	DecisionsPerDay      float64   `json:"decisions_per_day"`          // over the filter's date range or between the first and the last decision
+	MedianTimeToDecision float64   `json:"median_time_to_decision"`    // seconds from submission to decision
+	FirstDecisionAt      time.Time `json:"first_decision_at"`
+	LastDecisionAt       time.Time `json:"last_decision_at"`
+}
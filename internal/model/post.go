@@ -14,10 +14,17 @@ type Post struct {
 	FeedView            string    `json:"feed_view"`
 	Views               int64     `json:"views"`
 	Likes               int64     `json:"likes"`
+	Reactions           ReactionCounts `json:"reactions"`
+	Comments            int64     `json:"comments"`
+	Mentions            []Mention `json:"mentions"`
 	CreatedAt           time.Time `json:"created_at"`
 	UpdatedAt           time.Time `json:"updated_at"`
 	Validated           bool      `json:"validated"`
 	ValidationStatusMsg *string   `json:"validation_status_msg"`
+	Draft               bool      `json:"draft"`
+	PublishAt           *time.Time `json:"publish_at"`
+	DeletedAt           *time.Time `json:"deleted_at,omitempty"`
+	HiddenAt            *time.Time `json:"hidden_at,omitempty"` // set if hidden by reports, hidden posts are visible only to their authors
 }
 
 type FullPost struct {
@@ -30,3 +37,28 @@ type AuthorPost struct {
 	Post   Post         `json:"post"`
 	Tags   []string     `json:"tags"`
 }
+
+type PostSearchResult struct {
+	FullPost
+	Rank           float32 `json:"rank"`
+	TitleHighlight string  `json:"title_highlight"` // title with matched terms wrapped in <mark>
+	Snippet        string  `json:"snippet"`         // fragments of feed view and content with matched terms wrapped in <mark>
+}
+
+// Post engagement used to compute its trending score
+type PostEngagement struct {
+	PostID    int64     `json:"post_id"`
+	Likes     int64     `json:"likes"`
+	Views     int64     `json:"views"`
+	Comments  int64     `json:"comments"`
+	CreatedAt time.Time `json:"created_at"`
+	Tags      []string  `json:"tags"`
+}
+
+// Weights of related posts signals
+type RelatedPostsWeights struct {
+	Tags    float64 // per shared tag
+	Author  float64 // same author
+	CoLikes float64 // per ln(1 + users who liked both posts)
+	Text    float64 // per text rank of the post's title and feed view words
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	REVISION_STATUS_APPLIED = "applied"
+	REVISION_STATUS_PENDING = "pending"
+	REVISION_STATUS_REJECTED = "rejected"
+	REVISION_STATUS_SUPERSEDED = "superseded"
+)
+
+type PostRevision struct {
+	ID            int64     `json:"id"`
+	PostID        int64     `json:"post_id"`
+	AuthorID      uuid.UUID `json:"author_id"`
+	Title         string    `json:"title"`
+	Content       string    `json:"content"`
+	FeedView      string    `json:"feed_view"`
+	Tags          []string  `json:"tags"` // nil for revisions recorded before tags were tracked
+	ChangedFields []string  `json:"changed_fields"`
+	Status        string    `json:"status"`
+	CreatedAt     time.Time `json:"created_at"`
+	// Auto-moderation result of the pending edit, nil if it wasn't checked. Filled only for the moderation queue
+	AutoModeration *AutoModerationResult `json:"auto_moderation,omitempty"`
+}
@@ -0,0 +1,10 @@
+package model
+
+// Reaction targets, each has its own reactions table and per-type counts
+const (
+	REACTION_TARGET_POST = "post"
+	REACTION_TARGET_COMMENT = "comment"
+)
+
+// Per-type reaction counts, types without reactions may be missing
+type ReactionCounts map[string]int64
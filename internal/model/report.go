@@ -0,0 +1,83 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Report targets
+const (
+	REPORT_TARGET_POST = "post"
+	REPORT_TARGET_COMMENT = "comment"
+)
+
+// Report reason categories
+const (
+	REPORT_REASON_SPAM = "spam"
+	REPORT_REASON_HARASSMENT = "harassment"
+	REPORT_REASON_HATE = "hate"
+	REPORT_REASON_VIOLENCE = "violence"
+	REPORT_REASON_SEXUAL = "sexual"
+	REPORT_REASON_MISINFORMATION = "misinformation"
+	REPORT_REASON_OTHER = "other"
+)
+
+var REPORT_REASONS = []string{
+	REPORT_REASON_SPAM,
+	REPORT_REASON_HARASSMENT,
+	REPORT_REASON_HATE,
+	REPORT_REASON_VIOLENCE,
+	REPORT_REASON_SEXUAL,
+	REPORT_REASON_MISINFORMATION,
+	REPORT_REASON_OTHER,
+}
+
+// Moderator actions resolving reports of a target
+const (
+	REPORT_ACTION_DISMISS = "dismiss" // reports are unfounded, the target is shown again if it was hidden
+	REPORT_ACTION_HIDE = "hide"       // the target is hidden from everyone but its author
+	REPORT_ACTION_DELETE = "delete"   // the target is deleted
+	REPORT_ACTION_WARN = "warn"       // the target is shown again and its author is warned
+)
+
+// Content of comments hidden by reports
+const COMMENT_HIDDEN_CONTENT = "[hidden]"
+
+type Report struct {
+	ID         int64     `json:"id"`
+	Target     string    `json:"target"`
+	TargetID   int64     `json:"target_id"`
+	PostID     int64     `json:"post_id"` // the post itself or the commented post
+	ReporterID uuid.UUID `json:"reporter_id"`
+	Reason     string    `json:"reason"`
+	Details    string    `json:"details"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// Target with open reports in the moderation queue
+type ReportedTarget struct {
+	Target          string           `json:"target"`
+	TargetID        int64            `json:"target_id"`
+	PostID          int64            `json:"post_id"`
+	AuthorID        uuid.UUID        `json:"author_id"`
+	Content         string           `json:"content"` // post title or comment content
+	Reports         int64            `json:"reports"`
+	Reasons         map[string]int64 `json:"reasons"` // reports count by reason
+	FirstReportedAt time.Time        `json:"first_reported_at"`
+	LastReportedAt  time.Time        `json:"last_reported_at"`
+	HiddenAt        *time.Time       `json:"hidden_at"`
+}
+
+type ReportResolution struct {
+	ID          int64     `json:"id"`
+	Target      string    `json:"target"`
+	TargetID    int64     `json:"target_id"`
+	PostID      int64     `json:"post_id"`
+	AuthorID    uuid.UUID `json:"author_id"`
+	ModeratorID uuid.UUID `json:"moderator_id"`
+	Action      string    `json:"action"`
+	Note        string    `json:"note"`
+	ReporterIDs []uuid.UUID `json:"reporter_ids"`
+	CreatedAt   time.Time `json:"created_at"`
+}
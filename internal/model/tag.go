@@ -0,0 +1,10 @@
+package model
+
+import "time"
+
+type Tag struct {
+	Tag              string    `json:"tag"`
+	PostsCount       int64     `json:"posts_count"`
+	RecentPostsCount int64     `json:"recent_posts_count"` // posts in the last week
+	LastPostAt       time.Time `json:"last_post_at"`
+}
@@ -3,4 +3,5 @@ package rabbitmq
 const (
 	USERS_CREATED_EXCHANGE = "users.created"
 	USERS_UPDATED_EXCHANGE = "users.updated"
+	USERS_FOLLOWS_EXCHANGE = "users.follows"
 )
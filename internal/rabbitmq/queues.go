@@ -3,4 +3,9 @@ package rabbitmq
 const (
 	NEW_POST_NOTIFICATION_QUEUE = "new-post"
 	POST_VALIDATION_STATUS_UPDATES_QUEUE = "post-validation-status-updates"
+	COMMENT_CREATED_QUEUE = "comment.created"
+	COMMENT_REPLIED_QUEUE = "comment.replied"
+	USER_MENTIONED_QUEUE = "user.mentioned"
+	REPORT_RESOLVED_QUEUE = "report.resolved"
+	POST_APPEAL_DECIDED_QUEUE = "post.appeal.decided"
 )
@@ -0,0 +1,244 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type appealRepo struct {
+	db *pgxpool.Pool
+}
+
+func newAppealRepo(db *pgxpool.Pool) Appeal {
+	return &appealRepo{
+		db: db,
+	}
+}
+
+const appealColumns = "a.id, a.post_id, a.author_id, a.rejection_id, a.rejected_by, a.rejection_msg, a.justification, a.edited, a.status, a.moderator_id, a.decision_msg, a.created_at, a.decided_at"
+
+// Rejection of the post can be appealed if it's the latest decision on the post, the post is still not validated
+// and the rejection wasn't appealed yet. Decisions on pending edits aren't appealable
+const appealableRejectionCond = `NOT p.validated AND p.deleted_at IS NULL AND NOT p.draft AND NOT d.validated AND d.revision_id IS NULL
+	AND d.id = (SELECT MAX(l.id) FROM post_validation_status_contribs l WHERE l.post_id = p.id)
+	AND NOT EXISTS (SELECT 1 FROM post_appeals x WHERE x.rejection_id = d.id)`
+
+// Appeals queue, oldest first
+var appealsByCreatedAt = keyset{time: "a.created_at", id: "a.id", asc: true}
+
+// Returns the author's post rejection that can be appealed or pgx.ErrNoRows if there's none
+func (r *appealRepo) FindAppealableRejection(ctx context.Context, postID int64, authorID uuid.UUID) (*model.ModerationDecision, error) {
+	var rejection model.ModerationDecision
+	if err := r.db.QueryRow(
+		ctx,
+		`SELECT d.id, d.post_id, p.author_id, d.moderator_id, d.validated, d.validation_status_msg, d.created_at
+		FROM post_validation_status_contribs d
+		JOIN posts p ON p.id = d.post_id
+		WHERE d.post_id = $1 AND p.author_id = $2 AND `+appealableRejectionCond,
+		postID,
+		authorID,
+	).Scan(
+		&rejection.ID,
+		&rejection.PostID,
+		&rejection.AuthorID,
+		&rejection.ModeratorID,
+		&rejection.Validated,
+		&rejection.StatusMsg,
+		&rejection.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return &rejection, nil
+}
+
+// Creates the appeal if its rejection can still be appealed, returns pgx.ErrNoRows otherwise
+func (r *appealRepo) Create(ctx context.Context, appeal model.PostAppeal) (*model.PostAppeal, error) {
+	err := r.db.QueryRow(
+		ctx,
+		`INSERT INTO post_appeals(post_id, author_id, rejection_id, rejected_by, rejection_msg, justification, edited, status)
+		SELECT d.post_id, p.author_id, d.id, d.moderator_id, d.validation_status_msg, $3, $4, $5
+		FROM post_validation_status_contribs d
+		JOIN posts p ON p.id = d.post_id
+		WHERE d.id = $1 AND p.author_id = $2 AND `+appealableRejectionCond+`
+		ON CONFLICT (rejection_id) DO NOTHING
+		RETURNING id, post_id, rejected_by, rejection_msg, created_at`,
+		appeal.RejectionID,
+		appeal.AuthorID,
+		appeal.Justification,
+		appeal.Edited,
+		model.APPEAL_STATUS_PENDING,
+	).Scan(&appeal.ID, &appeal.PostID, &appeal.RejectedBy, &appeal.RejectionMsg, &appeal.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	appeal.Status = model.APPEAL_STATUS_PENDING
+	return &appeal, nil
+}
+
+// Deletes the appeal if it isn't decided yet
+func (r *appealRepo) Withdraw(ctx context.Context, id int64) error {
+	_, err := r.db.Exec(ctx, "DELETE FROM post_appeals WHERE id = $1 AND status = $2", id, model.APPEAL_STATUS_PENDING)
+	return err
+}
+
+// Returns appeals of the author's post, newest first
+func (r *appealRepo) FindPostAppeals(ctx context.Context, postID int64, authorID uuid.UUID) ([]*model.PostAppeal, error) {
+	rows, err := r.db.Query(
+		ctx,
+		`SELECT `+appealColumns+`
+		FROM post_appeals a
+		WHERE a.post_id = $1 AND a.author_id = $2
+		ORDER BY a.created_at DESC, a.id DESC`,
+		postID,
+		authorID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	appeals := []*model.PostAppeal{}
+	for rows.Next() {
+		appeal, err := scanAppeal(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		appeals = append(appeals, appeal)
+	}
+
+	return appeals, rows.Err()
+}
+
+// Returns pending appeals of existing posts that weren't rejected by the moderator, oldest first
+func (r *appealRepo) FindQueue(ctx context.Context, moderatorID uuid.UUID, cursor *model.Cursor, limit int) ([]*model.AppealedPost, *model.Cursor, error) {
+	after, args := appealsByCreatedAt.after(cursor, 4)
+
+	rows, err := r.db.Query(
+		ctx,
+		`SELECT `+fullPostColumns+`, `+appealColumns+`, `+appealsByCreatedAt.columns()+`
+		FROM post_appeals a
+		JOIN posts p ON p.id = a.post_id
+		JOIN cached_users u ON p.author_id = u.id
+		WHERE a.status = $2 AND a.rejected_by <> $3 AND p.deleted_at IS NULL AND `+after+`
+		ORDER BY `+appealsByCreatedAt.orderBy()+`
+		LIMIT $1`,
+		append([]any{limit + 1, model.APPEAL_STATUS_PENDING, moderatorID}, args...)...,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	return collectPage(rows, limit, scanAppealedPost)
+}
+
+// Post of the pending appeal, pgx.ErrNoRows if there's no such pending appeal
+func (r *appealRepo) FindPendingPostID(ctx context.Context, id int64) (int64, error) {
+	var postID int64
+	err := r.db.QueryRow(ctx, "SELECT post_id FROM post_appeals WHERE id = $1 AND status = $2", id, model.APPEAL_STATUS_PENDING).Scan(&postID)
+	return postID, err
+}
+
+// Decides the pending appeal. Overturned appeal counts as the moderator's approval of the post, which is validated
+// once it has requiredApprovals approvals like any other post. Returns true if the post was validated.
+// Returns pgx.ErrNoRows if there's no such pending appeal and ErrAppealRejectedByModerator if the moderator rejected the post.
+// beforeCommit runs right before the decision is committed, its error rolls the decision back
+func (r *appealRepo) Decide(ctx context.Context, id int64, moderatorID uuid.UUID, overturned bool, decisionMsg string, requiredApprovals int, beforeCommit func(ctx context.Context) error) (*model.PostAppeal, bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback(ctx)
+
+	appeal, err := scanAppeal(tx.QueryRow(ctx, "SELECT "+appealColumns+" FROM post_appeals a WHERE a.id = $1 AND a.status = $2 FOR UPDATE", id, model.APPEAL_STATUS_PENDING))
+	if err != nil {
+		return nil, false, err
+	}
+
+	if appeal.RejectedBy == moderatorID {
+		return nil, false, ErrAppealRejectedByModerator
+	}
+
+	appeal.Status = model.APPEAL_STATUS_UPHELD
+	if overturned {
+		appeal.Status = model.APPEAL_STATUS_OVERTURNED
+	}
+	appeal.ModeratorID = &moderatorID
+	appeal.DecisionMsg = &decisionMsg
+
+	if err := tx.QueryRow(
+		ctx,
+		"UPDATE post_appeals SET status = $1, moderator_id = $2, decision_msg = $3, decided_at = NOW() WHERE id = $4 RETURNING decided_at",
+		appeal.Status,
+		moderatorID,
+		decisionMsg,
+		id,
+	).Scan(&appeal.DecidedAt); err != nil {
+		return nil, false, err
+	}
+
+	validated := false
+	if overturned {
+		// Recorded as a moderation decision, so it shows in the post's history
+		validated, err = recordValidationDecision(ctx, tx, appeal.PostID, moderatorID, true, decisionMsg, requiredApprovals, &appeal.CreatedAt)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	if err := beforeCommit(ctx); err != nil {
+		return nil, false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, false, err
+	}
+
+	return appeal, validated, nil
+}
+
+func scanAppeal(row pgx.Row) (*model.PostAppeal, error) {
+	var appeal model.PostAppeal
+	if err := row.Scan(appealDest(&appeal)...); err != nil {
+		return nil, err
+	}
+
+	return &appeal, nil
+}
+
+func appealDest(appeal *model.PostAppeal) []any {
+	return []any{
+		&appeal.ID,
+		&appeal.PostID,
+		&appeal.AuthorID,
+		&appeal.RejectionID,
+		&appeal.RejectedBy,
+		&appeal.RejectionMsg,
+		&appeal.Justification,
+		&appeal.Edited,
+		&appeal.Status,
+		&appeal.ModeratorID,
+		&appeal.DecisionMsg,
+		&appeal.CreatedAt,
+		&appeal.DecidedAt,
+	}
+}
+
+func scanAppealedPost(rows pgx.Rows, keysetDest ...any) (*model.AppealedPost, error) {
+	var appealed model.AppealedPost
+	post, err := scanFullPost(rows, append(appealDest(&appealed.Appeal), keysetDest...)...)
+	if err != nil {
+		return nil, err
+	}
+	appealed.Post = *post
+
+	return &appealed, nil
+}
+
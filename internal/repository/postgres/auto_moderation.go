@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type autoModerationRepo struct {
+	db *pgxpool.Pool
+}
+
+func newAutoModerationRepo(db *pgxpool.Pool) AutoModeration {
+	return &autoModerationRepo{
+		db: db,
+	}
+}
+
+// Auto-moderation result columns of post_auto_moderation or revision_auto_moderation joined as am
+const autoModerationColumns = "am.decision, am.risk_score, am.flags, am.reason, am.checked_at"
+
+func (r *autoModerationRepo) FindAuthorHistory(ctx context.Context, authorID uuid.UUID) (*model.AuthorHistory, error) {
+	var history model.AuthorHistory
+	if err := r.db.QueryRow(
+		ctx,
+		`SELECT
+		COUNT(*) FILTER (WHERE p.validated),
+		(SELECT COUNT(*) FROM post_validation_status_contribs d JOIN posts dp ON dp.id = d.post_id WHERE dp.author_id = $1 AND NOT d.validated),
+		COUNT(*) FILTER (WHERE p.created_at > NOW() - INTERVAL '1 day'),
+		MIN(p.created_at)
+		FROM posts p
+		WHERE p.author_id = $1 AND NOT p.draft`,
+		authorID,
+	).Scan(&history.ValidatedPosts, &history.Rejections, &history.RecentPosts, &history.FirstPostAt); err != nil {
+		return nil, err
+	}
+
+	return &history, nil
+}
+
+// Replaces the post's previous result
+func (r *autoModerationRepo) Save(ctx context.Context, postID int64, result model.AutoModerationResult) error {
+	_, err := r.db.Exec(
+		ctx,
+		`INSERT INTO post_auto_moderation(post_id, decision, risk_score, flags, reason, checked_at)
+		VALUES($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (post_id) DO UPDATE SET
+		decision = EXCLUDED.decision, risk_score = EXCLUDED.risk_score, flags = EXCLUDED.flags, reason = EXCLUDED.reason, checked_at = EXCLUDED.checked_at`,
+		postID,
+		result.Decision,
+		result.RiskScore,
+		result.Flags,
+		result.Reason,
+		result.CheckedAt,
+	)
+	return err
+}
+
+// Result of a pending edit, kept apart from the result of the live post
+func (r *autoModerationRepo) SaveRevision(ctx context.Context, revisionID int64, result model.AutoModerationResult) error {
+	_, err := r.db.Exec(
+		ctx,
+		`INSERT INTO revision_auto_moderation(revision_id, decision, risk_score, flags, reason, checked_at)
+		VALUES($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (revision_id) DO UPDATE SET
+		decision = EXCLUDED.decision, risk_score = EXCLUDED.risk_score, flags = EXCLUDED.flags, reason = EXCLUDED.reason, checked_at = EXCLUDED.checked_at`,
+		revisionID,
+		result.Decision,
+		result.RiskScore,
+		result.Flags,
+		result.Reason,
+		result.CheckedAt,
+	)
+	return err
+}
+
+// Scan destinations of autoModerationColumns, the result is nil if the post wasn't checked
+type autoModerationDest struct {
+	decision  *string
+	riskScore *float64
+	flags     []model.AutoModerationFlag
+	reason    *string
+	checkedAt *time.Time
+}
+
+func (d *autoModerationDest) dest() []any {
+	return []any{&d.decision, &d.riskScore, &d.flags, &d.reason, &d.checkedAt}
+}
+
+func (d *autoModerationDest) result() *model.AutoModerationResult {
+	if d.decision == nil {
+		return nil
+	}
+
+	result := model.AutoModerationResult{
+		Decision: *d.decision,
+		Flags: d.flags,
+	}
+	if d.riskScore != nil {
+		result.RiskScore = *d.riskScore
+	}
+	if d.reason != nil {
+		result.Reason = *d.reason
+	}
+	if d.checkedAt != nil {
+		result.CheckedAt = *d.checkedAt
+	}
+	if result.Flags == nil {
+		result.Flags = []model.AutoModerationFlag{}
+	}
+
+	return &result
+}
+
+func scanQueuedPost(rows pgx.Rows, keysetDest ...any) (*model.QueuedPost, error) {
+	var autoModeration autoModerationDest
+	post, err := scanFullPost(rows, append(autoModeration.dest(), keysetDest...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.QueuedPost{
+		FullPost: *post,
+		AutoModeration: autoModeration.result(),
+	}, nil
+}
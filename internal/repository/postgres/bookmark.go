@@ -0,0 +1,340 @@
+package postgres
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type bookmarkRepo struct {
+	db *pgxpool.Pool
+}
+
+func newBookmarkRepo(db *pgxpool.Pool) Bookmark {
+	return &bookmarkRepo{
+		db: db,
+	}
+}
+
+const bookmarkCollectionColumns = "c.id, c.user_id, c.name, c.public, (SELECT COUNT(*) FROM bookmark_collection_posts cp WHERE cp.collection_id = c.id), c.created_at, c.updated_at"
+
+func scanBookmarkCollection(row pgx.Row) (*model.BookmarkCollection, error) {
+	var collection model.BookmarkCollection
+	if err := row.Scan(
+		&collection.ID,
+		&collection.UserID,
+		&collection.Name,
+		&collection.Public,
+		&collection.PostsCount,
+		&collection.CreatedAt,
+		&collection.UpdatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return &collection, nil
+}
+
+// Returns false if the post isn't visible or is already bookmarked
+func (r *bookmarkRepo) Create(ctx context.Context, postID int64, userID uuid.UUID) (bool, error) {
+	cmd, err := r.db.Exec(
+		ctx,
+		`INSERT INTO bookmarks(user_id, post_id)
+		SELECT $1, p.id FROM posts p WHERE p.id = $2 AND `+visiblePostCond+`
+		ON CONFLICT DO NOTHING`,
+		userID,
+		postID,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return cmd.RowsAffected() == 1, nil
+}
+
+// Removes the bookmark and the post from the user's collections
+func (r *bookmarkRepo) Delete(ctx context.Context, postID int64, userID uuid.UUID) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	cmd, err := tx.Exec(ctx, "DELETE FROM bookmarks WHERE user_id = $1 AND post_id = $2", userID, postID)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := tx.Exec(
+		ctx,
+		"DELETE FROM bookmark_collection_posts cp USING bookmark_collections c WHERE cp.collection_id = c.id AND c.user_id = $1 AND cp.post_id = $2",
+		userID,
+		postID,
+	); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+
+	return cmd.RowsAffected() == 1, nil
+}
+
+func (r *bookmarkRepo) IsBookmarked(ctx context.Context, postID int64, userID uuid.UUID) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM bookmarks WHERE user_id = $1 AND post_id = $2)", userID, postID).Scan(&exists)
+	return exists, err
+}
+
+// Bookmarked posts, most recently bookmarked first
+func (r *bookmarkRepo) FindUserBookmarks(ctx context.Context, userID uuid.UUID, cursor *model.Cursor, limit int) ([]*model.FullPost, *model.Cursor, error) {
+	order := keyset{time: "b.created_at", id: "p.id"}
+	after, args := order.after(cursor, 3)
+
+	rows, err := r.db.Query(
+		ctx,
+		`SELECT `+fullPostColumns+`, `+order.columns()+`
+		FROM bookmarks b
+		JOIN posts p ON `+visiblePostCond+` AND b.post_id = p.id
+		JOIN cached_users u ON p.author_id = u.id
+		WHERE b.user_id = $1 AND `+after+`
+		ORDER BY `+order.orderBy()+`
+		LIMIT $2`,
+		append([]any{userID, limit + 1}, args...)...,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	return collectPage(rows, limit, scanFullPost)
+}
+
+func (r *bookmarkRepo) CreateCollection(ctx context.Context, userID uuid.UUID, name string, public bool) (*model.BookmarkCollection, error) {
+	return scanBookmarkCollection(r.db.QueryRow(
+		ctx,
+		`INSERT INTO bookmark_collections AS c (user_id, name, public) VALUES($1, $2, $3)
+		RETURNING `+bookmarkCollectionColumns,
+		userID,
+		name,
+		public,
+	))
+}
+
+func (r *bookmarkRepo) FindCollection(ctx context.Context, id int64) (*model.BookmarkCollection, error) {
+	return scanBookmarkCollection(r.db.QueryRow(ctx, "SELECT "+bookmarkCollectionColumns+" FROM bookmark_collections c WHERE c.id = $1", id))
+}
+
+// Returns user's collections, private ones only if publicOnly is false
+func (r *bookmarkRepo) FindUserCollections(ctx context.Context, userID uuid.UUID, publicOnly bool) ([]*model.BookmarkCollection, error) {
+	rows, err := r.db.Query(
+		ctx,
+		`SELECT `+bookmarkCollectionColumns+`
+		FROM bookmark_collections c
+		WHERE c.user_id = $1 AND (c.public OR NOT $2)
+		ORDER BY c.created_at`,
+		userID,
+		publicOnly,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	collections := []*model.BookmarkCollection{}
+	for rows.Next() {
+		collection, err := scanBookmarkCollection(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		collections = append(collections, collection)
+	}
+
+	return collections, rows.Err()
+}
+
+func (r *bookmarkRepo) UpdateCollection(ctx context.Context, id int64, userID uuid.UUID, fields map[string]any) error {
+	query := "UPDATE bookmark_collections SET updated_at = $1"
+	args := []any{time.Now()}
+	for column, value := range fields {
+		args = append(args, value)
+		query += ", " + column + " = $" + strconv.Itoa(len(args))
+	}
+	args = append(args, id, userID)
+	query += " WHERE id = $" + strconv.Itoa(len(args)-1) + " AND user_id = $" + strconv.Itoa(len(args))
+
+	cmd, err := r.db.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r *bookmarkRepo) DeleteCollection(ctx context.Context, id int64, userID uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	cmd, err := tx.Exec(ctx, "DELETE FROM bookmark_collections WHERE id = $1 AND user_id = $2", id, userID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM bookmark_collection_posts WHERE collection_id = $1", id); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Collection posts in their order
+func (r *bookmarkRepo) FindCollectionPosts(ctx context.Context, collectionID int64, cursor *model.Cursor, limit int) ([]*model.FullPost, *model.Cursor, error) {
+	order := keyset{score: "cp.position", time: "cp.added_at", id: "p.id", asc: true}
+	after, args := order.after(cursor, 3)
+
+	rows, err := r.db.Query(
+		ctx,
+		`SELECT `+fullPostColumns+`, `+order.columns()+`
+		FROM bookmark_collection_posts cp
+		JOIN posts p ON `+visiblePostCond+` AND cp.post_id = p.id
+		JOIN cached_users u ON p.author_id = u.id
+		WHERE cp.collection_id = $1 AND `+after+`
+		ORDER BY `+order.orderBy()+`
+		LIMIT $2`,
+		append([]any{collectionID, limit + 1}, args...)...,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	return collectPage(rows, limit, scanFullPost)
+}
+
+// Bookmarks the post and appends it to the end of the collection, it's a no-op if the post is already there
+func (r *bookmarkRepo) AddToCollection(ctx context.Context, collectionID int64, userID uuid.UUID, postID int64) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockCollection(ctx, tx, collectionID, userID); err != nil {
+		return err
+	}
+
+	cmd, err := tx.Exec(
+		ctx,
+		`INSERT INTO bookmarks(user_id, post_id)
+		SELECT $1, p.id FROM posts p WHERE p.id = $2 AND `+visiblePostCond+`
+		ON CONFLICT DO NOTHING`,
+		userID,
+		postID,
+	)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		var bookmarked bool
+		if err := tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM bookmarks WHERE user_id = $1 AND post_id = $2)", userID, postID).Scan(&bookmarked); err != nil {
+			return err
+		}
+		if !bookmarked {
+			return ErrPostIsNotVisible
+		}
+	}
+
+	if _, err := tx.Exec(
+		ctx,
+		`INSERT INTO bookmark_collection_posts(collection_id, post_id, position)
+		SELECT $1, $2, COALESCE(MAX(position), 0) + 1 FROM bookmark_collection_posts WHERE collection_id = $1
+		ON CONFLICT DO NOTHING`,
+		collectionID,
+		postID,
+	); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE bookmark_collections SET updated_at = $1 WHERE id = $2", time.Now(), collectionID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *bookmarkRepo) RemoveFromCollection(ctx context.Context, collectionID int64, userID uuid.UUID, postID int64) error {
+	cmd, err := r.db.Exec(
+		ctx,
+		`DELETE FROM bookmark_collection_posts cp
+		USING bookmark_collections c
+		WHERE cp.collection_id = c.id AND c.id = $1 AND c.user_id = $2 AND cp.post_id = $3`,
+		collectionID,
+		userID,
+		postID,
+	)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// Sets positions of the collection posts to the order of postIDs, posts that aren't listed go after them
+func (r *bookmarkRepo) ReorderCollection(ctx context.Context, collectionID int64, userID uuid.UUID, postIDs []int64) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := lockCollection(ctx, tx, collectionID, userID); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(
+		ctx,
+		`UPDATE bookmark_collection_posts cp SET position = o.position
+		FROM (
+			SELECT cp.post_id, ROW_NUMBER() OVER (ORDER BY x.n NULLS LAST, cp.position) AS position
+			FROM bookmark_collection_posts cp
+			LEFT JOIN unnest($2::bigint[]) WITH ORDINALITY x(post_id, n) ON x.post_id = cp.post_id
+			WHERE cp.collection_id = $1
+		) o
+		WHERE cp.collection_id = $1 AND cp.post_id = o.post_id`,
+		collectionID,
+		postIDs,
+	); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE bookmark_collections SET updated_at = $1 WHERE id = $2", time.Now(), collectionID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Locks the user's collection so concurrent changes of its posts don't get the same positions
+func lockCollection(ctx context.Context, tx pgx.Tx, id int64, userID uuid.UUID) error {
+	var lockedID int64
+	return tx.QueryRow(ctx, "SELECT id FROM bookmark_collections WHERE id = $1 AND user_id = $2 FOR UPDATE", id, userID).Scan(&lockedID)
+}
@@ -2,10 +2,12 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/BloggingApp/post-service/internal/model"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 )
@@ -25,6 +27,8 @@ func newCommentRepo(db *pgxpool.Pool, logger *zap.Logger) Comment {
 func (r *commentRepo) Create(ctx context.Context, comment model.Comment) (*model.Comment, error) {
 	comment.CreatedAt = time.Now()
 	comment.Likes = 0
+	comment.Reactions = model.ReactionCounts{}
+	comment.Mentions = []model.Mention{}
 	if err := r.db.QueryRow(
 		ctx,
 		"INSERT INTO comments(parent_id, post_id, author_id, content, likes) VALUES($1, $2, $3, $4, $5) RETURNING id",
@@ -40,109 +44,349 @@ func (r *commentRepo) Create(ctx context.Context, comment model.Comment) (*model
 	return &comment, nil
 }
 
-func (r *commentRepo) FindPostComments(ctx context.Context, postID int64, limit int, offset int) ([]*model.FullComment, error) {
-	maxLimit(&limit)
+// Comments lists ordered from most liked to least liked
+var commentsByLikes = keyset{score: "c.likes", time: "c.created_at", id: "c.id"}
+
+func (r *commentRepo) FindPostComments(ctx context.Context, postID int64, cursor *model.Cursor, limit int) ([]*model.FullComment, *model.Cursor, error) {
+	after, args := commentsByLikes.after(cursor, 3)
 
 	rows, err := r.db.Query(
 		ctx,
 		`SELECT
-		c.id, c.post_id, c.author_id, c.content, c.likes, c.created_at, u.username, u.display_name, u.avatar_url
+		c.id, c.parent_id, c.post_id, c.author_id, c.content, c.likes, c.reactions, c.mentions, c.created_at, c.edited_at, c.deleted_at, c.hidden_at, u.username, u.display_name, u.avatar_url,
+		`+commentsByLikes.columns()+`
 		FROM comments c
 		JOIN cached_users u ON c.author_id = u.id
-		WHERE c.post_id = $1 AND c.parent_id IS NULL
-		ORDER BY c.likes DESC, c.created_at DESC
-		LIMIT $2
-		OFFSET $3`,
-		postID,
-		limit,
-		offset,
+		WHERE c.post_id = $1 AND c.parent_id IS NULL AND `+after+`
+		ORDER BY `+commentsByLikes.orderBy()+`
+		LIMIT $2`,
+		append([]any{postID, limit + 1}, args...)...,
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
-	var comments []*model.FullComment
-	for rows.Next() {
-		var comment model.FullComment
-		if err := rows.Scan(
+	return collectPage(rows, limit, scanFullComment)
+}
+
+func (r *commentRepo) FindCommentReplies(ctx context.Context, postID int64, commentID int64, cursor *model.Cursor, limit int) ([]*model.FullComment, *model.Cursor, error) {
+	after, args := commentsByLikes.after(cursor, 4)
+
+	rows, err := r.db.Query(
+		ctx,
+		`SELECT
+		c.id, c.parent_id, c.post_id, c.author_id, c.content, c.likes, c.reactions, c.mentions, c.created_at, c.edited_at, c.deleted_at, c.hidden_at, u.username, u.display_name, u.avatar_url,
+		`+commentsByLikes.columns()+`
+		FROM comments c
+		JOIN cached_users u ON c.author_id = u.id
+		WHERE c.post_id = $1 AND c.parent_id = $2 AND `+after+`
+		ORDER BY `+commentsByLikes.orderBy()+`
+		LIMIT $3`,
+		append([]any{postID, commentID, limit + 1}, args...)...,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	return collectPage(rows, limit, scanFullComment)
+}
+
+// Scans comment and its author columns followed by extra columns
+func scanFullComment(rows pgx.Rows, extraDest ...any) (*model.FullComment, error) {
+	var comment model.FullComment
+	if err := rows.Scan(append(
+		[]any{
 			&comment.Comment.ID,
+			&comment.Comment.ParentID,
 			&comment.Comment.PostID,
 			&comment.Comment.AuthorID,
 			&comment.Comment.Content,
 			&comment.Comment.Likes,
+			&comment.Comment.Reactions,
+			&comment.Comment.Mentions,
 			&comment.Comment.CreatedAt,
+			&comment.Comment.EditedAt,
+			&comment.Comment.DeletedAt,
+			&comment.Comment.HiddenAt,
 			&comment.Author.Username,
 			&comment.Author.DisplayName,
 			&comment.Author.AvatarURL,
+		},
+		extraDest...,
+	)...); err != nil {
+		return nil, err
+	}
+
+	if comment.Comment.DeletedAt != nil {
+		comment.Comment.AuthorID = uuid.Nil
+		comment.Author = model.UserAuthor{}
+		comment.Comment.Mentions = []model.Mention{}
+	}
+	if comment.Comment.HiddenAt != nil && comment.Comment.DeletedAt == nil {
+		comment.Comment.Content = model.COMMENT_HIDDEN_CONTENT
+		comment.Comment.Mentions = []model.Mention{}
+	}
+
+	return &comment, nil
+}
+
+// Deletes the comment by its author, the post author or a moderator and records the deletion.
+// Comments with replies become tombstones, leaf comments are deleted along with tombstones left without replies.
+// Returns ErrNotAllowedToDeleteComment if the user can't delete the comment
+func (r *commentRepo) Delete(ctx context.Context, postID int64, commentID int64, userID uuid.UUID, asModerator bool, reason string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var (
+		authorID uuid.UUID
+		postAuthorID uuid.UUID
+		parentID *int64
+		content string
+	)
+	if err := tx.QueryRow(
+		ctx,
+		`SELECT c.author_id, p.author_id, c.parent_id, c.content
+		FROM comments c
+		JOIN posts p ON p.id = c.post_id
+		WHERE c.post_id = $1 AND c.id = $2 AND c.deleted_at IS NULL
+		FOR UPDATE OF c`,
+		postID,
+		commentID,
+	).Scan(&authorID, &postAuthorID, &parentID, &content); err != nil {
+		return err
+	}
+
+	var deleterRole string
+	switch {
+	case userID == authorID:
+		deleterRole = model.COMMENT_DELETER_AUTHOR
+	case userID == postAuthorID:
+		deleterRole = model.COMMENT_DELETER_POST_AUTHOR
+	case asModerator:
+		deleterRole = model.COMMENT_DELETER_MODERATOR
+	default:
+		return ErrNotAllowedToDeleteComment
+	}
+
+	if _, err := tx.Exec(
+		ctx,
+		`INSERT INTO comment_deletions(comment_id, post_id, author_id, content, deleted_by, deleter_role, reason)
+		VALUES($1, $2, $3, $4, $5, $6, $7)`,
+		commentID,
+		postID,
+		authorID,
+		content,
+		userID,
+		deleterRole,
+		reason,
+	); err != nil {
+		return err
+	}
+
+	var hasReplies bool
+	if err := tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM comments WHERE parent_id = $1)", commentID).Scan(&hasReplies); err != nil {
+		return err
+	}
+
+	if hasReplies {
+		if _, err := tx.Exec(
+			ctx,
+			"UPDATE comments SET content = $1, deleted_at = $2 WHERE id = $3",
+			model.COMMENT_TOMBSTONE_CONTENT,
+			time.Now(),
+			commentID,
 		); err != nil {
-			return nil, err
+			return err
+		}
+
+		// Previous versions would still show the deleted text
+		if _, err := tx.Exec(ctx, "DELETE FROM comment_revisions WHERE comment_id = $1", commentID); err != nil {
+			return err
+		}
+
+		return tx.Commit(ctx)
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM comments WHERE id = $1", commentID); err != nil {
+		return err
+	}
+
+	// Tombstones are kept only while they have replies
+	for parentID != nil {
+		var grandparentID *int64
+		err := tx.QueryRow(
+			ctx,
+			`DELETE FROM comments c
+			WHERE c.id = $1 AND c.deleted_at IS NOT NULL AND NOT EXISTS (SELECT 1 FROM comments r WHERE r.parent_id = c.id)
+			RETURNING c.parent_id`,
+			*parentID,
+		).Scan(&grandparentID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			break
+		}
+		if err != nil {
+			return err
 		}
+		parentID = grandparentID
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Replaces the comment's content and stores the previous one as a revision.
+// Returns ErrCommentEditWindowExpired if the comment was created before editableSince
+func (r *commentRepo) Edit(ctx context.Context, postID, commentID int64, authorID uuid.UUID, content string, editableSince time.Time) (*model.Comment, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var comment model.Comment
+	if err := tx.QueryRow(
+		ctx,
+		`SELECT id, parent_id, post_id, author_id, content, likes, reactions, mentions, created_at, edited_at
+		FROM comments
+		WHERE post_id = $1 AND id = $2 AND author_id = $3 AND deleted_at IS NULL AND hidden_at IS NULL
+		FOR UPDATE`,
+		postID,
+		commentID,
+		authorID,
+	).Scan(
+		&comment.ID,
+		&comment.ParentID,
+		&comment.PostID,
+		&comment.AuthorID,
+		&comment.Content,
+		&comment.Likes,
+		&comment.Reactions,
+		&comment.Mentions,
+		&comment.CreatedAt,
+		&comment.EditedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if comment.CreatedAt.Before(editableSince) {
+		return nil, ErrCommentEditWindowExpired
+	}
+	if comment.Content == content {
+		return &comment, nil
+	}
+
+	// Previous version was written when the comment was created or last edited
+	writtenAt := comment.CreatedAt
+	if comment.EditedAt != nil {
+		writtenAt = *comment.EditedAt
+	}
+	now := time.Now()
 
-		comments = append(comments, &comment)
+	if _, err := tx.Exec(
+		ctx,
+		"INSERT INTO comment_revisions(comment_id, content, created_at, replaced_at) VALUES($1, $2, $3, $4)",
+		comment.ID,
+		comment.Content,
+		writtenAt,
+		now,
+	); err != nil {
+		return nil, err
 	}
 
-	if err := rows.Err(); err != nil {
+	if _, err := tx.Exec(ctx, "UPDATE comments SET content = $1, edited_at = $2 WHERE id = $3", content, now, comment.ID); err != nil {
 		return nil, err
 	}
 
-	return comments, nil
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	comment.Content = content
+	comment.EditedAt = &now
+
+	return &comment, nil
 }
 
-func (r *commentRepo) FindCommentReplies(ctx context.Context, postID int64, commentID int64, limit int, offset int) ([]*model.FullComment, error) {
-	maxLimit(&limit)
+// Returns author of the comment if it isn't deleted
+func (r *commentRepo) FindAuthorID(ctx context.Context, commentID int64) (uuid.UUID, error) {
+	var authorID uuid.UUID
+	err := r.db.QueryRow(ctx, "SELECT author_id FROM comments WHERE id = $1 AND deleted_at IS NULL", commentID).Scan(&authorID)
+	return authorID, err
+}
+
+// Returns previous versions of the comment, newest first
+// Revisions are only returned for comments of visible posts and only to the comment author or a moderator (asModerator).
+// Deleted and hidden comments have no revisions. Returns ErrNotAllowedToViewCommentRevisions if the user can't see them
+func (r *commentRepo) FindRevisions(ctx context.Context, postID, commentID int64, userID uuid.UUID, asModerator bool) ([]*model.CommentRevision, error) {
+	var (
+		authorID uuid.UUID
+		deletedAt *time.Time
+		hiddenAt *time.Time
+	)
+	if err := r.db.QueryRow(
+		ctx,
+		"SELECT c.author_id, c.deleted_at, c.hidden_at FROM comments c JOIN posts p ON p.id = c.post_id WHERE "+visiblePostCond+" AND c.post_id = $1 AND c.id = $2",
+		postID,
+		commentID,
+	).Scan(&authorID, &deletedAt, &hiddenAt); err != nil {
+		return nil, err
+	}
+	if !asModerator && authorID != userID {
+		return nil, ErrNotAllowedToViewCommentRevisions
+	}
+	if deletedAt != nil || hiddenAt != nil {
+		return []*model.CommentRevision{}, nil
+	}
 
 	rows, err := r.db.Query(
 		ctx,
-		`SELECT
-		c.id, c.parent_id, c.post_id, c.author_id, c.content, c.likes, c.created_at, u.username, u.display_name, u.avatar_url
-		FROM comments c
-		JOIN cached_users u ON c.author_id = u.id
-		WHERE c.post_id = $1 AND c.parent_id = $2
-		ORDER BY c.likes DESC, c.created_at DESC
-		LIMIT $3
-		OFFSET $4`,
+		`SELECT cr.id, cr.comment_id, cr.content, cr.created_at, cr.replaced_at
+		FROM comment_revisions cr
+		JOIN comments c ON c.id = cr.comment_id
+		WHERE c.post_id = $1 AND c.id = $2
+		ORDER BY cr.replaced_at DESC, cr.id DESC`,
 		postID,
 		commentID,
-		limit,
-		offset,
 	)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var comments []*model.FullComment
+	revisions := []*model.CommentRevision{}
 	for rows.Next() {
-		var comment model.FullComment
+		var revision model.CommentRevision
 		if err := rows.Scan(
-			&comment.Comment.ID,
-			&comment.Comment.ParentID,
-			&comment.Comment.PostID,
-			&comment.Comment.AuthorID,
-			&comment.Comment.Content,
-			&comment.Comment.Likes,
-			&comment.Comment.CreatedAt,
-			&comment.Author.Username,
-			&comment.Author.DisplayName,
-			&comment.Author.AvatarURL,
+			&revision.ID,
+			&revision.CommentID,
+			&revision.Content,
+			&revision.CreatedAt,
+			&revision.ReplacedAt,
 		); err != nil {
 			return nil, err
 		}
-
-		comments = append(comments, &comment)
+		revisions = append(revisions, &revision)
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return comments, nil
+	return revisions, rows.Err()
 }
 
-func (r *commentRepo) Delete(ctx context.Context, postID int64, commentID int64, authorID uuid.UUID) error {
-	_, err := r.db.Exec(ctx, "DELETE FROM comments WHERE post_id = $1 AND id = $2 AND author_id = $3", postID, commentID, authorID)
-	return err
+// Deletes revisions of comments that became tombstones before their revisions were deleted with them.
+// Returns the number of deleted revisions
+func (r *commentRepo) DeleteTombstonesRevisions(ctx context.Context) (int64, error) {
+	cmd, err := r.db.Exec(
+		ctx,
+		"DELETE FROM comment_revisions cr USING comments c WHERE c.id = cr.comment_id AND c.deleted_at IS NOT NULL",
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return cmd.RowsAffected(), nil
 }
 
 func (r *commentRepo) Like(ctx context.Context, commentID int64, userID uuid.UUID) bool {
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/BloggingApp/post-service/internal/model"
+)
+
+const commentRepliesCount = "(SELECT COUNT(*) FROM comments r WHERE r.parent_id = c.id)"
+
+// Comment tree orderings by sort mode
+var commentTreeKeysets = map[string]keyset{
+	model.COMMENTS_SORT_TOP: commentsByLikes,
+	model.COMMENTS_SORT_NEWEST: {time: "c.created_at", id: "c.id"},
+	model.COMMENTS_SORT_OLDEST: {time: "c.created_at", id: "c.id", asc: true},
+	// Many replies with few likes
+	model.COMMENTS_SORT_CONTROVERSIAL: {score: commentRepliesCount + "::float8 / (c.likes + 1)", time: "c.created_at", id: "c.id"},
+}
+
+// Returns the comments tree of the post flattened, children of every comment are ordered by sort.
+// Top level comments are replies to parentID (top level post comments if nil) after the cursor.
+// Up to breadth+1 children are fetched for each comment to find out if there are more of them,
+// comments on the maxDepth level and extra children aren't expanded
+func (r *commentRepo) FindTree(ctx context.Context, postID int64, parentID *int64, sort string, cursor *model.Cursor, maxDepth, breadth int) ([]*model.CommentTreeEntry, error) {
+	k, ok := commentTreeKeysets[sort]
+	if !ok {
+		k = commentsByLikes
+	}
+
+	score := "0::float8"
+	if k.score != "" {
+		score = "(" + k.score + ")::float8"
+	}
+	// Keyset columns are named to be referenced from the recursive query
+	columns := `c.id, c.parent_id, c.post_id, c.author_id, c.content, c.likes, c.reactions, c.mentions, c.created_at, c.edited_at, c.deleted_at, c.hidden_at,
+		u.username, u.display_name, u.avatar_url, ` + commentRepliesCount + ` AS replies_count,
+		` + score + ` AS sort_score, ` + k.time + ` AS sort_time, ` + k.id + ` AS sort_id,
+		ROW_NUMBER() OVER (ORDER BY ` + k.orderBy() + `) AS position`
+
+	parentCond := "c.parent_id IS NULL"
+	args := []any{postID, breadth + 1, maxDepth, breadth}
+	if parentID != nil {
+		parentCond = "c.parent_id = $5"
+		args = append(args, *parentID)
+	}
+	after, afterArgs := k.after(cursor, len(args)+1)
+	args = append(args, afterArgs...)
+
+	rows, err := r.db.Query(
+		ctx,
+		`WITH RECURSIVE tree AS (
+			SELECT roots.*, 1 AS depth FROM (
+				SELECT `+columns+`
+				FROM comments c
+				JOIN cached_users u ON c.author_id = u.id
+				WHERE c.post_id = $1 AND `+parentCond+` AND `+after+`
+				ORDER BY `+k.orderBy()+`
+				LIMIT $2
+			) roots
+			UNION ALL
+			SELECT child.*, t.depth + 1
+			FROM tree t
+			CROSS JOIN LATERAL (
+				SELECT `+columns+`
+				FROM comments c
+				JOIN cached_users u ON c.author_id = u.id
+				WHERE c.parent_id = t.id
+				ORDER BY `+k.orderBy()+`
+				LIMIT $2
+			) child
+			WHERE t.depth < $3 AND t.position <= $4
+		)
+		SELECT
+		id, parent_id, post_id, author_id, content, likes, reactions, mentions, created_at, edited_at, deleted_at, hidden_at, username, display_name, avatar_url,
+		replies_count, sort_score, sort_time, sort_id, position, depth
+		FROM tree
+		ORDER BY depth, position`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*model.CommentTreeEntry{}
+	for rows.Next() {
+		var entry model.CommentTreeEntry
+		comment, err := scanFullComment(
+			rows,
+			&entry.RepliesCount,
+			&entry.Cursor.Score,
+			&entry.Cursor.CreatedAt,
+			&entry.Cursor.ID,
+			&entry.Position,
+			&entry.Depth,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entry.Comment = *comment
+
+		entries = append(entries, &entry)
+	}
+
+	return entries, rows.Err()
+}
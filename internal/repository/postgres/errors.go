@@ -2,4 +2,14 @@ package postgres
 
 import "errors"
 
-var ErrFieldsNotAllowedToUpdate = errors.New("these fields are not allowed to be updated")
+var (
+	ErrFieldsNotAllowedToUpdate = errors.New("these fields are not allowed to be updated")
+	ErrPostIsNotVisible = errors.New("post is not visible")
+	ErrReactionTargetIsNotVisible = errors.New("reaction target is not visible")
+	ErrPostAlreadyApprovedByModerator = errors.New("post is already approved by the moderator")
+	ErrReportTargetIsNotVisible = errors.New("report target is not visible")
+	ErrCommentEditWindowExpired = errors.New("comment edit window has expired")
+	ErrNotAllowedToDeleteComment = errors.New("not allowed to delete the comment")
+	ErrNotAllowedToViewCommentRevisions = errors.New("not allowed to view the comment revisions")
+	ErrAppealRejectedByModerator = errors.New("appeal can't be decided by the moderator who rejected the post")
+)
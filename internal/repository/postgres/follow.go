@@ -0,0 +1,137 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type followRepo struct {
+	db *pgxpool.Pool
+}
+
+func newFollowRepo(db *pgxpool.Pool) Follow {
+	return &followRepo{
+		db: db,
+	}
+}
+
+// Followers counts are kept in follower_counts, so they aren't counted on every feed request
+func (r *followRepo) Create(ctx context.Context, follow model.Follow) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	cmd, err := tx.Exec(
+		ctx,
+		"INSERT INTO follows(follower_id, followee_id, created_at) VALUES($1, $2, $3) ON CONFLICT DO NOTHING",
+		follow.FollowerID,
+		follow.FolloweeID,
+		follow.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return nil
+	}
+
+	if _, err := tx.Exec(
+		ctx,
+		"INSERT INTO follower_counts(user_id, followers) VALUES($1, 1) ON CONFLICT (user_id) DO UPDATE SET followers = follower_counts.followers + 1",
+		follow.FolloweeID,
+	); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *followRepo) Delete(ctx context.Context, followerID, followeeID uuid.UUID) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	cmd, err := tx.Exec(ctx, "DELETE FROM follows WHERE follower_id = $1 AND followee_id = $2", followerID, followeeID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return nil
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE follower_counts SET followers = GREATEST(followers - 1, 0) WHERE user_id = $1", followeeID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Returns users followed by the follower with their followers count
+func (r *followRepo) FindFollowees(ctx context.Context, followerID uuid.UUID) ([]*model.Followee, error) {
+	rows, err := r.db.Query(
+		ctx,
+		`SELECT f.followee_id, COALESCE(c.followers, 0)
+		FROM follows f
+		LEFT JOIN follower_counts c ON c.user_id = f.followee_id
+		WHERE f.follower_id = $1`,
+		followerID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	followees := []*model.Followee{}
+	for rows.Next() {
+		var followee model.Followee
+		if err := rows.Scan(&followee.ID, &followee.FollowersCount); err != nil {
+			return nil, err
+		}
+
+		followees = append(followees, &followee)
+	}
+
+	return followees, rows.Err()
+}
+
+func (r *followRepo) FindFollowers(ctx context.Context, followeeID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.db.Query(ctx, "SELECT follower_id FROM follows WHERE followee_id = $1", followeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[uuid.UUID])
+}
+
+func (r *followRepo) CountFollowers(ctx context.Context, followeeID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.db.QueryRow(ctx, "SELECT COALESCE((SELECT followers FROM follower_counts WHERE user_id = $1), 0)", followeeID).Scan(&count)
+	return count, err
+}
+
+// Recounts followers of every user from follows, fixing counts written before follower_counts existed.
+// Returns the number of corrected counts
+func (r *followRepo) RecountFollowers(ctx context.Context) (int64, error) {
+	cmd, err := r.db.Exec(
+		ctx,
+		`INSERT INTO follower_counts(user_id, followers)
+		SELECT u.user_id, COALESCE(f.followers, 0)
+		FROM (SELECT followee_id AS user_id FROM follows UNION SELECT user_id FROM follower_counts) u
+		LEFT JOIN (SELECT followee_id, COUNT(*) AS followers FROM follows GROUP BY followee_id) f ON f.followee_id = u.user_id
+		ON CONFLICT (user_id) DO UPDATE SET followers = EXCLUDED.followers
+		WHERE follower_counts.followers IS DISTINCT FROM EXCLUDED.followers`,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return cmd.RowsAffected(), nil
+}
@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type mentionRepo struct {
+	db *pgxpool.Pool
+}
+
+func newMentionRepo(db *pgxpool.Pool) Mention {
+	return &mentionRepo{
+		db: db,
+	}
+}
+
+// Tables of a mention target
+type mentionTable struct {
+	mentions string // mentioned users table
+	column   string // target ID column of the mentioned users table
+	target   string // table with the mentions column
+}
+
+var mentionTables = map[string]mentionTable{
+	model.MENTION_TARGET_POST: {
+		mentions: "post_mentions",
+		column: "post_id",
+		target: "posts",
+	},
+	model.MENTION_TARGET_COMMENT: {
+		mentions: "comment_mentions",
+		column: "comment_id",
+		target: "comments",
+	},
+}
+
+func mentionTableOf(target string) (mentionTable, error) {
+	table, ok := mentionTables[target]
+	if !ok {
+		return mentionTable{}, fmt.Errorf("unknown mention target: %s", target)
+	}
+	return table, nil
+}
+
+// Replaces mentions of the target. If record is set, mentioned users are recorded and
+// the ones mentioned in the target for the first time are returned. Users stay recorded
+// when mentions are removed by edits, so they aren't returned again if they're mentioned back
+func (r *mentionRepo) Save(ctx context.Context, target string, targetID int64, mentions []model.Mention, record bool) ([]uuid.UUID, error) {
+	table, err := mentionTableOf(target)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "UPDATE "+table.target+" SET mentions = $1 WHERE id = $2", mentions, targetID); err != nil {
+		return nil, err
+	}
+
+	newUserIDs := []uuid.UUID{}
+	if record && len(mentions) > 0 {
+		userIDs := make([]uuid.UUID, 0, len(mentions))
+		for _, mention := range mentions {
+			userIDs = append(userIDs, mention.UserID)
+		}
+
+		rows, err := tx.Query(
+			ctx,
+			`INSERT INTO `+table.mentions+`(`+table.column+`, user_id)
+			SELECT $1, u.id FROM unnest($2::uuid[]) AS u(id)
+			ON CONFLICT DO NOTHING
+			RETURNING user_id`,
+			targetID,
+			userIDs,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		for rows.Next() {
+			var userID uuid.UUID
+			if err := rows.Scan(&userID); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			newUserIDs = append(newUserIDs, userID)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return newUserIDs, nil
+}
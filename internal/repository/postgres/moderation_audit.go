@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type moderationAuditRepo struct {
+	db *pgxpool.Pool
+}
+
+func newModerationAuditRepo(db *pgxpool.Pool) ModerationAudit {
+	return &moderationAuditRepo{
+		db: db,
+	}
+}
+
+// Submission time of decisions recorded before it was kept falls back to the revision or post creation
+const decisionSubmittedAt = "COALESCE(d.submitted_at, r.created_at, p.created_at)"
+
+// Decisions are joined with their posts (p) and revisions (r), posts may be purged
+const moderationDecisionsFrom = `post_validation_status_contribs d
+	LEFT JOIN posts p ON p.id = d.post_id
+	LEFT JOIN post_revisions r ON r.id = d.revision_id`
+
+// Decisions ordered from newest to oldest
+var decisionsByCreatedAt = keyset{time: "d.created_at", id: "d.id"}
+
+// Returns the filter condition, its args are numbered from argN
+func moderationDecisionsCond(filter model.ModerationDecisionsFilter, argN int) (string, []any) {
+	conds := []string{"TRUE"}
+	args := []any{}
+	add := func(cond string, arg any) {
+		conds = append(conds, fmt.Sprintf(cond, argN+len(args)))
+		args = append(args, arg)
+	}
+
+	if filter.ModeratorID != nil {
+		add("d.moderator_id = $%d", *filter.ModeratorID)
+	}
+	if filter.PostID != nil {
+		add("d.post_id = $%d", *filter.PostID)
+	}
+	if filter.AuthorID != nil {
+		add("p.author_id = $%d", *filter.AuthorID)
+	}
+	if filter.Validated != nil {
+		add("d.validated = $%d", *filter.Validated)
+	}
+	if filter.From != nil {
+		add("d.created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		add("d.created_at < $%d", *filter.To)
+	}
+
+	return strings.Join(conds, " AND "), args
+}
+
+func (r *moderationAuditRepo) FindDecisions(ctx context.Context, filter model.ModerationDecisionsFilter, cursor *model.Cursor, limit int) ([]*model.ModerationDecision, *model.Cursor, error) {
+	cond, args := moderationDecisionsCond(filter, 2)
+	after, afterArgs := decisionsByCreatedAt.after(cursor, 2+len(args))
+
+	rows, err := r.db.Query(
+		ctx,
+		`SELECT
+		d.id, d.post_id, d.revision_id, p.author_id, d.moderator_id, d.validated, d.validation_status_msg, `+decisionSubmittedAt+`, d.created_at,
+		`+decisionsByCreatedAt.columns()+`
+		FROM `+moderationDecisionsFrom+`
+		WHERE `+cond+` AND `+after+`
+		ORDER BY `+decisionsByCreatedAt.orderBy()+`
+		LIMIT $1`,
+		append(append([]any{limit + 1}, args...), afterArgs...)...,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	return collectPage(rows, limit, scanModerationDecision)
+}
+
+func scanModerationDecision(rows pgx.Rows, extraDest ...any) (*model.ModerationDecision, error) {
+	var decision model.ModerationDecision
+	if err := rows.Scan(append(
+		[]any{
+			&decision.ID,
+			&decision.PostID,
+			&decision.RevisionID,
+			&decision.AuthorID,
+			&decision.ModeratorID,
+			&decision.Validated,
+			&decision.StatusMsg,
+			&decision.SubmittedAt,
+			&decision.CreatedAt,
+		},
+		extraDest...,
+	)...); err != nil {
+		return nil, err
+	}
+
+	return &decision, nil
+}
+
+// Returns stats of moderators with decisions matching the filter, most active first.
+// Approval rate and decisions per day are left for the caller
+func (r *moderationAuditRepo) FindModeratorStats(ctx context.Context, filter model.ModerationDecisionsFilter) ([]*model.ModeratorStats, error) {
+	cond, args := moderationDecisionsCond(filter, 1)
+
+	rows, err := r.db.Query(
+		ctx,
+		`SELECT
+		d.moderator_id, u.username, COUNT(*), COUNT(*) FILTER (WHERE d.validated), COUNT(*) FILTER (WHERE NOT d.validated),
+		COALESCE(PERCENTILE_CONT(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM d.created_at - `+decisionSubmittedAt+`)), 0),
+		MIN(d.created_at), MAX(d.created_at)
+		FROM `+moderationDecisionsFrom+`
+		LEFT JOIN cached_users u ON u.id = d.moderator_id
+		WHERE `+cond+`
+		GROUP BY d.moderator_id, u.username
+		ORDER BY COUNT(*) DESC, d.moderator_id`,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []*model.ModeratorStats{}
+	for rows.Next() {
+		var moderator model.ModeratorStats
+		if err := rows.Scan(
+			&moderator.ModeratorID,
+			&moderator.Username,
+			&moderator.Decisions,
+			&moderator.Approvals,
+			&moderator.Rejections,
+			&moderator.MedianTimeToDecision,
+			&moderator.FirstDecisionAt,
+			&moderator.LastDecisionAt,
+		); err != nil {
+			return nil, err
+		}
+
+		stats = append(stats, &moderator)
+	}
+
+	return stats, rows.Err()
+}
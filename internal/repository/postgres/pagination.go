@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"fmt"
+
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+// Ordering of a keyset paginated query. Paged queries select keyset columns after the item columns
+// and fetch one extra row to find out if there's a next page
+type keyset struct {
+	score string // empty if ordered by time only
+	time string
+	id string
+	asc bool
+}
+
+func (k keyset) columns() string {
+	score := "0::float8"
+	if k.score != "" {
+		score = "(" + k.score + ")::float8"
+	}
+
+	return score + ", " + k.time + ", " + k.id
+}
+
+// Condition selecting rows after the cursor, its args are numbered from argN
+func (k keyset) after(cursor *model.Cursor, argN int) (string, []any) {
+	if cursor == nil {
+		return "TRUE", nil
+	}
+
+	op := "<"
+	if k.asc {
+		op = ">"
+	}
+
+	return fmt.Sprintf("(%s) %s ($%d, $%d, $%d)", k.columns(), op, argN, argN+1, argN+2), []any{cursor.Score, cursor.CreatedAt, cursor.ID}
+}
+
+// Condition selecting rows up to and including the cursor, its args are numbered from argN
+func (k keyset) until(cursor *model.Cursor, argN int) (string, []any) {
+	if cursor == nil {
+		return "TRUE", nil
+	}
+
+	op := ">="
+	if k.asc {
+		op = "<="
+	}
+
+	return fmt.Sprintf("(%s) %s ($%d, $%d, $%d)", k.columns(), op, argN, argN+1, argN+2), []any{cursor.Score, cursor.CreatedAt, cursor.ID}
+}
+
+func (k keyset) orderBy() string {
+	dir := " DESC"
+	if k.asc {
+		dir = " ASC"
+	}
+
+	order := k.time + dir + ", " + k.id + dir
+	if k.score != "" {
+		order = k.score + dir + ", " + order
+	}
+
+	return order
+}
+
+// Scans rows of a keyset paginated query fetched with limit+1 and returns the cursor to the next page
+func collectPage[T any](rows pgx.Rows, limit int, scan func(rows pgx.Rows, keysetDest ...any) (T, error)) ([]T, *model.Cursor, error) {
+	items := []T{}
+	cursors := []model.Cursor{}
+	for rows.Next() {
+		var cursor model.Cursor
+		item, err := scan(rows, &cursor.Score, &cursor.CreatedAt, &cursor.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		items = append(items, item)
+		cursors = append(cursors, cursor)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	if len(items) <= limit {
+		return items, nil, nil
+	}
+
+	return items[:limit], &cursors[limit-1], nil
+}
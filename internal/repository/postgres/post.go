@@ -2,15 +2,30 @@ package postgres
 
 import (
 	"context"
+	"maps"
+	"slices"
 	"strconv"
 	"time"
 
 	"github.com/BloggingApp/post-service/internal/model"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
 )
 
+// Condition that every post shown to readers must satisfy
+const visiblePostCond = "p.validated AND p.deleted_at IS NULL AND p.hidden_at IS NULL AND NOT p.draft AND p.publish_at IS NULL"
+
+// Columns scanned by scanAuthorPost, tags are aggregated so there's one row per post
+const authorPostColumns = "p.id, p.author_id, p.title, p.content, p.feed_view, p.views, p.likes, p.reactions, p.comments, p.mentions, p.created_at, p.updated_at, p.validated, p.validation_status_msg, p.draft, p.publish_at, p.deleted_at, p.hidden_at, ARRAY(SELECT pt.tag FROM post_tags pt WHERE pt.post_id = p.id ORDER BY pt.tag)"
+
+// Posts lists ordered from newest to oldest
+var postsByCreatedAt = keyset{time: "p.created_at", id: "p.id"}
+
+// Columns scanned by scanFullPost, tags are aggregated so there's one row per post
+const fullPostColumns = "p.id, p.author_id, p.title, p.content, p.feed_view, p.views, p.likes, p.reactions, p.comments, p.mentions, p.created_at, p.updated_at, p.validated, p.validation_status_msg, u.username, u.display_name, u.avatar_url, ARRAY(SELECT pt.tag FROM post_tags pt WHERE pt.post_id = p.id ORDER BY pt.tag)"
+
 type postRepo struct {
 	db *pgxpool.Pool
 	logger *zap.Logger
@@ -29,6 +44,8 @@ func (r *postRepo) Create(ctx context.Context, post model.Post, tags []string) (
 	post.UpdatedAt = now
 	post.Views = 0
 	post.Likes = 0
+	post.Reactions = model.ReactionCounts{}
+	post.Mentions = []model.Mention{}
 
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
@@ -38,22 +55,25 @@ func (r *postRepo) Create(ctx context.Context, post model.Post, tags []string) (
 
 	if err := tx.QueryRow(
 		ctx,
-		"INSERT INTO posts(author_id, title, content, feed_view, views, likes) VALUES($1, $2, $3, $4, $5, $6) RETURNING id",
+		"INSERT INTO posts(author_id, title, content, feed_view, views, likes, draft, publish_at, queued_at) VALUES($1, $2, $3, $4, $5, $6, $7, $8, NOW()) RETURNING id",
 		post.AuthorID,
 		post.Title,
 		post.Content,
 		post.FeedView,
 		post.Views,
 		post.Likes,
+		post.Draft,
+		post.PublishAt,
 	).Scan(&post.ID); err != nil {
 		return nil, err
 	}
 
-	for _, tag := range tags {
-		_, err := tx.Exec(ctx, "INSERT INTO post_tags(post_id, tag) VALUES($1, $2)", post.ID, tag)
-		if err != nil {
-			return nil, err
-		}
+	if err := setPostTags(ctx, tx, post.ID, tags); err != nil {
+		return nil, err
+	}
+
+	if err := refreshSearchVector(ctx, tx, post.ID); err != nil {
+		return nil, err
 	}
 
 	if err := tx.Commit(ctx); err != nil {
@@ -67,11 +87,11 @@ func (r *postRepo) FindByID(ctx context.Context, id int64) (*model.FullPost, err
 	rows, err := r.db.Query(
 		ctx,
 		`SELECT
-		p.id, p.author_id, p.title, p.content, p.feed_view, p.views, p.likes, p.created_at, p.updated_at, u.username, u.display_name, u.avatar_url, t.tag
+		p.id, p.author_id, p.title, p.content, p.feed_view, p.views, p.likes, p.reactions, p.comments, p.mentions, p.created_at, p.updated_at, u.username, u.display_name, u.avatar_url, t.tag
 		FROM posts p
 		JOIN cached_users u ON p.author_id = u.id
 		LEFT JOIN post_tags t ON p.id = t.post_id
-		WHERE p.validated AND p.id = $1`,
+		WHERE `+visiblePostCond+` AND p.id = $1`,
 		id,
 	)
 	if err != nil {
@@ -89,6 +109,9 @@ func (r *postRepo) FindByID(ctx context.Context, id int64) (*model.FullPost, err
 			feedView string
 			views int64
 			likes int64
+			reactions model.ReactionCounts
+			comments int64
+			mentions []model.Mention
 			createdAt time.Time
 			updatedAt time.Time
 			username string
@@ -104,6 +127,9 @@ func (r *postRepo) FindByID(ctx context.Context, id int64) (*model.FullPost, err
 			&feedView,
 			&views,
 			&likes,
+			&reactions,
+			&comments,
+			&mentions,
 			&createdAt,
 			&updatedAt,
 			&username,
@@ -125,6 +151,9 @@ func (r *postRepo) FindByID(ctx context.Context, id int64) (*model.FullPost, err
 					FeedView: feedView,
 					Views: views,
 					Likes: likes,
+					Reactions: reactions,
+					Comments: comments,
+					Mentions: mentions,
 					CreatedAt: createdAt,
 					UpdatedAt: updatedAt,
 					Validated: true,
@@ -161,825 +190,1097 @@ func (r *postRepo) FindByID(ctx context.Context, id int64) (*model.FullPost, err
 	return posts[0], nil
 }
 
-func (r *postRepo) FindAuthorPosts(ctx context.Context, authorID uuid.UUID, limit, offset int) ([]*model.AuthorPost, error) {
-	maxLimit(&limit)
+func (r *postRepo) FindAuthorPosts(ctx context.Context, authorID uuid.UUID, cursor *model.Cursor, limit int) ([]*model.AuthorPost, *model.Cursor, error) {
+	after, args := postsByCreatedAt.after(cursor, 3)
 
 	rows, err := r.db.Query(
 		ctx,
 		`
 		SELECT
-		p.id, p.author_id, p.title, p.content, p.feed_view, p.views, p.likes, p.created_at, p.updated_at, t.tag
+		`+authorPostColumns+`, `+postsByCreatedAt.columns()+`
 		FROM posts p
-		LEFT JOIN post_tags t ON p.id = t.post_id
-		WHERE p.validated AND p.author_id = $1
-		ORDER BY p.created_at DESC
+		WHERE `+visiblePostCond+` AND p.author_id = $1 AND `+after+`
+		ORDER BY `+postsByCreatedAt.orderBy()+`
 		LIMIT $2
-		OFFSET $3
 		`,
-		authorID,
-		limit,
-		offset,
+		append([]any{authorID, limit + 1}, args...)...,
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
-	postsMap := make(map[int64]*model.AuthorPost)
-	for rows.Next() {
-		var (
-			id int64
-			authorID uuid.UUID
-			title string
-			content string
-			feedView string
-			views int64
-			likes int64
-			createdAt time.Time
-			updatedAt time.Time
-			tag *string
-		)
-		if err := rows.Scan(
-			&id,
-			&authorID,
-			&title,
-			&content,
-			&feedView,
-			&views,
-			&likes,
-			&createdAt,
-			&updatedAt,
-			&tag,
-		); err != nil {
-			return nil, err
-		}
-
-		post, ok := postsMap[id]
-		if !ok {
-			post = &model.AuthorPost{
-				Post: model.Post{
-					ID: id,
-					AuthorID: authorID,
-					Title: title,
-					Content: content,
-					FeedView: feedView,
-					Views: views,
-					Likes: likes,
-					CreatedAt: createdAt,
-					UpdatedAt: updatedAt,
-					Validated: true,
-				},
-				Tags: []string{},
-			}
-			postsMap[post.Post.ID] = post
-		}
-
-		if tag != nil {
-			postsMap[post.Post.ID].Tags = append(postsMap[post.Post.ID].Tags, *tag)
-		}
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	var posts []*model.AuthorPost
-	for _, post := range postsMap {
-		posts = append(posts, post)
-	}
-
-	return posts, nil
+	return collectPage(rows, limit, scanAuthorPost)
 }
 
-func (r *postRepo) FindUserNotValidatedPosts(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*model.AuthorPost, error) {
-	maxLimit(&limit)
+func (r *postRepo) FindUserNotValidatedPosts(ctx context.Context, userID uuid.UUID, cursor *model.Cursor, limit int) ([]*model.AuthorPost, *model.Cursor, error) {
+	after, args := postsByCreatedAt.after(cursor, 3)
 
 	rows, err := r.db.Query(
 		ctx,
 		`
 		SELECT
-		p.id, p.author_id, p.title, p.content, p.feed_view, p.views, p.likes, p.created_at, p.updated_at, p.not_validated_msg, t.tag
+		`+authorPostColumns+`, `+postsByCreatedAt.columns()+`
 		FROM posts p
-		LEFT JOIN post_tags t ON p.id = t.post_id
-		WHERE !p.validated AND p.author_id = $1
-		ORDER BY p.created_at DESC
+		WHERE NOT p.validated AND p.deleted_at IS NULL AND NOT p.draft AND p.author_id = $1 AND `+after+`
+		ORDER BY `+postsByCreatedAt.orderBy()+`
 		LIMIT $2
-		OFFSET $3
 		`,
-		userID,
-		limit,
-		offset,
+		append([]any{userID, limit + 1}, args...)...,
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
-		postsMap := make(map[int64]*model.AuthorPost)
-	for rows.Next() {
-		var (
-			id int64
-			authorID uuid.UUID
-			title string
-			content string
-			feedView string
-			views int64
-			likes int64
-			createdAt time.Time
-			updatedAt time.Time
-			validationStatusMsg *string
-			tag *string
-		)
-		if err := rows.Scan(
-			&id,
-			&authorID,
-			&title,
-			&content,
-			&feedView,
-			&views,
-			&likes,
-			&createdAt,
-			&updatedAt,
-			&validationStatusMsg,
-			&tag,
-		); err != nil {
-			return nil, err
-		}
-
-		post, ok := postsMap[id]
-		if !ok {
-			post = &model.AuthorPost{
-				Post: model.Post{
-					ID: id,
-					AuthorID: authorID,
-					Title: title,
-					Content: content,
-					FeedView: feedView,
-					Views: views,
-					Likes: likes,
-					CreatedAt: createdAt,
-					UpdatedAt: updatedAt,
-					Validated: false,
-					ValidationStatusMsg: validationStatusMsg,
-				},
-				Tags: []string{},
-			}
-			postsMap[post.Post.ID] = post
-		}
-
-		if tag != nil {
-			postsMap[post.Post.ID].Tags = append(postsMap[post.Post.ID].Tags, *tag)
-		}
-	}
+	return collectPage(rows, limit, scanAuthorPost)
+}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
+// Moderation queue with auto-moderation results, oldest posts first. Posts approved by notApprovedBy are skipped, nil skips none
+func (r *postRepo) FindNotValidatedPosts(ctx context.Context, notApprovedBy *uuid.UUID, cursor *model.Cursor, limit int) ([]*model.QueuedPost, *model.Cursor, error) {
+	order := keyset{time: "p.created_at", id: "p.id", asc: true}
+	after, args := order.after(cursor, 3)
 
-	var posts []*model.AuthorPost
-	for _, post := range postsMap {
-		posts = append(posts, post)
+	rows, err := r.db.Query(
+		ctx,
+		`SELECT `+fullPostColumns+`, `+autoModerationColumns+`, `+order.columns()+`
+		FROM posts p
+		JOIN cached_users u ON p.author_id = u.id
+		LEFT JOIN post_auto_moderation am ON am.post_id = p.id
+		WHERE NOT p.validated AND p.deleted_at IS NULL AND NOT p.draft AND `+after+`
+		AND ($2::uuid IS NULL OR NOT EXISTS (SELECT 1 FROM post_approvals a WHERE a.post_id = p.id AND a.moderator_id = $2))
+		ORDER BY `+order.orderBy()+`
+		LIMIT $1`,
+		append([]any{limit + 1, notApprovedBy}, args...)...,
+	)
+	if err != nil {
+		return nil, nil, err
 	}
+	defer rows.Close()
 
-	return posts, nil
+	return collectPage(rows, limit, scanQueuedPost)
 }
 
-func (r *postRepo) FindNotValidatedPosts(ctx context.Context, limit, offset int) ([]*model.FullPost, error) {
-	maxLimit(&limit)
+// Returns posts having any of the tags, or all of them if matchAll is true. Tags must be unique
+func (r *postRepo) SearchByTags(ctx context.Context, tags []string, matchAll bool, cursor *model.Cursor, limit int) ([]*model.FullPost, *model.Cursor, error) {
+	tagsCond := "EXISTS (SELECT 1 FROM post_tags t WHERE t.post_id = p.id AND t.tag = ANY($1))"
+	if matchAll {
+		tagsCond = "(SELECT COUNT(DISTINCT t.tag) FROM post_tags t WHERE t.post_id = p.id AND t.tag = ANY($1)) = cardinality($1::text[])"
+	}
+	after, args := postsByCreatedAt.after(cursor, 3)
 
 	rows, err := r.db.Query(
 		ctx,
-		`SELECT
-		p.id, p.author_id, p.title, p.content, p.feed_view, p.views, p.likes, p.created_at, p.updated_at, p.not_validated_msg, u.username, u.display_name, u.avatar_url, t.tag
+		`SELECT `+fullPostColumns+`, `+postsByCreatedAt.columns()+`
 		FROM posts p
 		JOIN cached_users u ON p.author_id = u.id
-		LEFT JOIN post_tags t ON p.id = t.post_id
-		WHERE !p.validated
-		LIMIT $2
-		OFFSET $3`,
-		limit,
-		offset,
+		WHERE `+visiblePostCond+` AND `+tagsCond+` AND `+after+`
+		ORDER BY `+postsByCreatedAt.orderBy()+`
+		LIMIT $2`,
+		append([]any{tags, limit + 1}, args...)...,
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
-	postsMap := make(map[int64]*model.FullPost)
-	for rows.Next() {
-		var (
-			id int64
-			authorID uuid.UUID
-			title string
-			content string
-			feedView string
-			views int64
-			likes int64
-			createdAt time.Time
-			updatedAt time.Time
-			validationStatusMsg *string
-			username string
-			displayName *string
-			avatarURL *string
-			tag *string
-		)
-		if err := rows.Scan(
-			&id,
-			&authorID,
-			&title,
-			&content,
-			&feedView,
-			&views,
-			&likes,
-			&createdAt,
-			&updatedAt,
-			&validationStatusMsg,
-			&username,
-			&displayName,
-			&avatarURL,
-			&tag,
-		); err != nil {
-			return nil, err
-		}
+	return collectPage(rows, limit, scanFullPost)
+}
 
-		post, ok := postsMap[id]
-		if !ok {
-			post = &model.FullPost{
-				Post: model.Post{
-					ID: id,
-					AuthorID: authorID,
-					Title: title,
-					Content: content,
-					FeedView: feedView,
-					Views: views,
-					Likes: likes,
-					CreatedAt: createdAt,
-					UpdatedAt: updatedAt,
-					Validated: false,
-					ValidationStatusMsg: validationStatusMsg,
-				},
-				Author: model.UserAuthor{
-					Username: username,
-					DisplayName: displayName,
-					AvatarURL: avatarURL,
-				},
-				Tags: []string{},
-			}
-			postsMap[id] = post
-		}
+func (r *postRepo) IncrViews(ctx context.Context, id int64) error {
+	_, err := r.db.Exec(ctx, "UPDATE posts SET views = views + 1 WHERE id = $1", id)
+	return err
+}
 
-		if tag != nil {
-			postsMap[post.Post.ID].Tags = append(postsMap[post.Post.ID].Tags, *tag)
-		}
-	}
+func (r *postRepo) Like(ctx context.Context, postID int64, userID uuid.UUID) bool {
+	cmd, err := r.db.Exec(ctx, "INSERT INTO post_likes(post_id, user_id) VALUES($1, $2) ON CONFLICT DO NOTHING", postID, userID)
+	return err == nil && cmd.RowsAffected() == 1
+}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
+func (r *postRepo) IncrPostLikesBy(ctx context.Context, postID int64, n int64) error {
+	_, err := r.db.Exec(ctx, `
+		UPDATE posts
+		SET likes = CASE
+			WHEN likes + $1 >= 0 THEN likes + $1
+			ELSE 0
+		END
+		WHERE id = $2
+	`, n, postID)
+	return err
+}
 
-	var posts []*model.FullPost
-	for _, post := range postsMap {
-		posts = append(posts, post)
+func (r *postRepo) IncrPostCommentsBy(ctx context.Context, postID int64, n int64) error {
+	_, err := r.db.Exec(ctx, "UPDATE posts SET comments = GREATEST(comments + $1, 0) WHERE id = $2", n, postID)
+	return err
+}
+
+func (r *postRepo) Unlike(ctx context.Context, postID int64, userID uuid.UUID) bool {
+	cmd, err := r.db.Exec(ctx, "DELETE FROM post_likes WHERE post_id = $1 AND user_id = $2", postID, userID)
+	return err == nil && cmd.RowsAffected() == 1
+}
+
+func (r *postRepo) IsLiked(ctx context.Context, postID int64, userID uuid.UUID) bool {
+	var exists bool
+	var err error
+
+	for retries := 0; retries < 3; retries++ {
+		err = r.db.QueryRow(ctx, "SELECT count(*) > 0 FROM post_likes WHERE post_id = $1 AND user_id = $2", postID, userID).Scan(&exists)
+		if err == nil {
+			break
+		}
+		if err.Error() == "conn busy" {
+			time.Sleep(time.Duration(retries+1) * time.Millisecond * 100)
+			continue
+		}
+		break
 	}
 
-	if len(posts) == 0 {
-		return []*model.FullPost{}, nil
+	if err != nil {
+		r.logger.Sugar().Errorf("failed to get is liked for user(%s): %s", userID.String(), err.Error())
+		return false
 	}
 
-	return posts, nil
+	return exists
 }
 
-func (r *postRepo) SearchByTags(ctx context.Context, tags []string, limit int, offset int) ([]*model.FullPost, error) {
-	if len(tags) == 0 {
-		return nil, nil
-	}
-
-	maxLimit(&limit)
+// Liked posts, most recently liked first
+func (r *postRepo) FindUserLikes(ctx context.Context, userID uuid.UUID, cursor *model.Cursor, limit int) ([]*model.FullPost, *model.Cursor, error) {
+	order := keyset{time: "l.created_at", id: "p.id"}
+	after, args := order.after(cursor, 3)
 
 	rows, err := r.db.Query(
 		ctx,
-		`SELECT
-		p.id, p.author_id, p.title, p.content, p.feed_view, p.views, p.likes, p.created_at, p.updated_at, u.username, u.display_name, u.avatar_url, t.tag
-		FROM posts p
+		`SELECT `+fullPostColumns+`, `+order.columns()+`
+		FROM post_likes l
+		JOIN posts p ON `+visiblePostCond+` AND l.post_id = p.id
 		JOIN cached_users u ON p.author_id = u.id
-		LEFT JOIN post_tags t ON p.id = t.post_id
-		WHERE p.validated AND t.tag = ANY($1)
-		ORDER BY p.likes DESC, p.views DESC, p.created_at DESC
-		LIMIT $2
-		OFFSET $3`,
-		tags,
-		limit,
-		offset,
+		WHERE l.user_id = $1 AND `+after+`
+		ORDER BY `+order.orderBy()+`
+		LIMIT $2`,
+		append([]any{userID, limit + 1}, args...)...,
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
-	postsMap := make(map[int64]*model.FullPost)
-	for rows.Next() {
-		var (
-			id int64
-			authorID uuid.UUID
-			title string
-			content string
-			feedView string
-			views int64
-			likes int64
-			createdAt time.Time
-			updatedAt time.Time
-			username string
-			displayName *string
-			avatarURL *string
-			tag *string
-		)
-		if err := rows.Scan(
-			&id,
-			&authorID,
-			&title,
-			&content,
-			&feedView,
-			&views,
-			&likes,
-			&createdAt,
-			&updatedAt,
-			&username,
-			&displayName,
-			&avatarURL,
-			&tag,
-		); err != nil {
+	return collectPage(rows, limit, scanFullPost)
+}
+
+func (r *postRepo) Update(ctx context.Context, id int64, authorID uuid.UUID, fields map[string]any, revalidate bool) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := lockPostVersion(ctx, tx, id, authorID)
+	if err != nil {
+		return err
+	}
+
+	if _, err := updatePost(ctx, tx, *current, fields, revalidate); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Applies fields to the locked post and records the applied revision. Returns the new version of the post
+func updatePost(ctx context.Context, tx pgx.Tx, current model.PostRevision, fields map[string]any, revalidate bool) (model.PostRevision, error) {
+	id := current.PostID
+	next, changedFields := applyRevisionFields(current, fields)
+	if len(changedFields) == 0 {
+		return current, nil
+	}
+
+	if err := ensureBaselineRevision(ctx, tx, current); err != nil {
+		return current, err
+	}
+
+	moderatedChanged := slices.Contains(changedFields, "title") || slices.Contains(changedFields, "content")
+	query := "UPDATE posts SET title = $1, content = $2, feed_view = $3, updated_at = $4"
+	if revalidate && moderatedChanged {
+		query += ", validated = FALSE, validation_status_msg = NULL, queued_at = $4"
+	}
+	query += " WHERE id = $5"
+
+	next.CreatedAt = time.Now()
+	if _, err := tx.Exec(ctx, query, next.Title, next.Content, next.FeedView, next.CreatedAt, id); err != nil {
+		return current, err
+	}
+
+	// Approvals were given to the previous version
+	if moderatedChanged {
+		if _, err := tx.Exec(ctx, "DELETE FROM post_approvals WHERE post_id = $1", id); err != nil {
+			return current, err
+		}
+	}
+
+	if slices.Contains(changedFields, "tags") {
+		if err := setPostTags(ctx, tx, id, next.Tags); err != nil {
+			return current, err
+		}
+	}
+
+	if err := refreshSearchVector(ctx, tx, id); err != nil {
+		return current, err
+	}
+
+	next.ChangedFields = changedFields
+	next.Status = model.REVISION_STATUS_APPLIED
+	if _, err := insertRevision(ctx, tx, next); err != nil {
+		return current, err
+	}
+
+	return next, nil
+}
+
+// Records the edit as a revision waiting for moderation without changing the live post.
+// The edit is applied on top of the latest pending revision, which is superseded by the new one.
+// Tags are not moderated and are updated in the same transaction
+func (r *postRepo) CreatePendingRevision(ctx context.Context, id int64, authorID uuid.UUID, fields map[string]any) (*model.PostRevision, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	current, err := lockPostVersion(ctx, tx, id, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	moderatedFields := maps.Clone(fields)
+	if tags, ok := moderatedFields["tags"]; ok {
+		delete(moderatedFields, "tags")
+
+		updated, err := updatePost(ctx, tx, *current, map[string]any{"tags": tags}, false)
+		if err != nil {
 			return nil, err
 		}
+		current = &updated
+	}
 
-		post, ok := postsMap[id]
-		if !ok {
-			post = &model.FullPost{
-				Post: model.Post{
-					ID: id,
-					AuthorID: authorID,
-					Title: title,
-					Content: content,
-					FeedView: feedView,
-					Views: views,
-					Likes: likes,
-					CreatedAt: createdAt,
-					UpdatedAt: updatedAt,
-					Validated: true,
-				},
-				Author: model.UserAuthor{
-					Username: username,
-					DisplayName: displayName,
-					AvatarURL: avatarURL,
-				},
-				Tags: []string{},
+	base := *current
+	if err := tx.QueryRow(
+		ctx,
+		"SELECT title, content, feed_view FROM post_revisions WHERE post_id = $1 AND status = $2 ORDER BY id DESC LIMIT 1",
+		id,
+		model.REVISION_STATUS_PENDING,
+	).Scan(
+		&base.Title,
+		&base.Content,
+		&base.FeedView,
+	); err != nil && err != pgx.ErrNoRows {
+		return nil, err
+	}
+
+	next, _ := applyRevisionFields(base, moderatedFields)
+	_, changedFields := applyRevisionFields(*current, map[string]any{
+		"title": next.Title,
+		"content": next.Content,
+		"feed_view": next.FeedView,
+	})
+	if len(changedFields) == 0 {
+		return nil, tx.Commit(ctx)
+	}
+
+	if err := ensureBaselineRevision(ctx, tx, *current); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(
+		ctx,
+		"UPDATE post_revisions SET status = $1 WHERE post_id = $2 AND status = $3",
+		model.REVISION_STATUS_SUPERSEDED,
+		id,
+		model.REVISION_STATUS_PENDING,
+	); err != nil {
+		return nil, err
+	}
+
+	next.ChangedFields = changedFields
+	next.Status = model.REVISION_STATUS_PENDING
+	next.CreatedAt = time.Now()
+	next.ID, err = insertRevision(ctx, tx, next)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &next, nil
+}
+
+// Applies (approved) or rejects a pending revision and records the moderator's decision.
+// beforeCommit runs right before the decision is committed, its error rolls the decision back
+func (r *postRepo) ReviewRevision(ctx context.Context, revisionID int64, moderatorID uuid.UUID, approved bool, statusMsg string, beforeCommit func(ctx context.Context) error) (*model.PostRevision, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var revision model.PostRevision
+	if err := tx.QueryRow(
+		ctx,
+		"SELECT id, post_id, author_id, title, content, feed_view, changed_fields, status, created_at FROM post_revisions WHERE id = $1 AND status = $2 FOR UPDATE",
+		revisionID,
+		model.REVISION_STATUS_PENDING,
+	).Scan(
+		&revision.ID,
+		&revision.PostID,
+		&revision.AuthorID,
+		&revision.Title,
+		&revision.Content,
+		&revision.FeedView,
+		&revision.ChangedFields,
+		&revision.Status,
+		&revision.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	revision.Status = model.REVISION_STATUS_REJECTED
+	if approved {
+		revision.Status = model.REVISION_STATUS_APPLIED
+
+		// Only fields changed by the revision are applied, so direct edits made meanwhile are kept
+		query := "UPDATE posts SET updated_at = $1"
+		args := []any{time.Now()}
+		for _, column := range revision.ChangedFields {
+			var value string
+			switch column {
+			case "title":
+				value = revision.Title
+			case "content":
+				value = revision.Content
+			case "feed_view":
+				value = revision.FeedView
+			default:
+				continue
 			}
-			postsMap[id] = post
+			args = append(args, value)
+			query += ", " + column + " = $" + strconv.Itoa(len(args))
 		}
+		args = append(args, revision.PostID)
+		query += " WHERE id = $" + strconv.Itoa(len(args))
 
-		if tag != nil {
-			postsMap[post.Post.ID].Tags = append(postsMap[post.Post.ID].Tags, *tag)
+		if _, err := tx.Exec(ctx, query, args...); err != nil {
+			return nil, err
+		}
+
+		if err := refreshSearchVector(ctx, tx, revision.PostID); err != nil {
+			return nil, err
 		}
 	}
 
-	if err := rows.Err(); err != nil {
+	if _, err := tx.Exec(ctx, "UPDATE post_revisions SET status = $1 WHERE id = $2", revision.Status, revision.ID); err != nil {
 		return nil, err
 	}
 
-	var posts []*model.FullPost
-	for _, post := range postsMap {
-		posts = append(posts, post)
+	if _, err := tx.Exec(
+		ctx,
+		"INSERT INTO post_validation_status_contribs(post_id, moderator_id, validated, validation_status_msg, revision_id, submitted_at) VALUES($1, $2, $3, $4, $5, $6)",
+		revision.PostID,
+		moderatorID,
+		approved,
+		statusMsg,
+		revision.ID,
+		revision.CreatedAt,
+	); err != nil {
+		return nil, err
 	}
 
-	if len(posts) == 0 {
-		return []*model.FullPost{}, nil
+	if err := beforeCommit(ctx); err != nil {
+		return nil, err
 	}
 
-	return posts, nil
-}
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
 
-func (r *postRepo) IncrViews(ctx context.Context, id int64) error {
-	_, err := r.db.Exec(ctx, "UPDATE posts SET views = views + 1 WHERE id = $1", id)
-	return err
+	return &revision, nil
 }
 
-func (r *postRepo) Like(ctx context.Context, postID int64, userID uuid.UUID) bool {
-	cmd, err := r.db.Exec(ctx, "INSERT INTO post_likes(post_id, user_id) VALUES($1, $2) ON CONFLICT DO NOTHING", postID, userID)
-	return err == nil && cmd.RowsAffected() == 1
-}
+// Locks the post row and returns its current version
+func lockPostVersion(ctx context.Context, tx pgx.Tx, id int64, authorID uuid.UUID) (*model.PostRevision, error) {
+	current := model.PostRevision{
+		PostID: id,
+		AuthorID: authorID,
+	}
+	if err := tx.QueryRow(
+		ctx,
+		"SELECT p.title, p.content, p.feed_view, ARRAY(SELECT t.tag FROM post_tags t WHERE t.post_id = p.id ORDER BY t.tag), p.updated_at FROM posts p WHERE p.id = $1 AND p.author_id = $2 FOR UPDATE",
+		id,
+		authorID,
+	).Scan(
+		&current.Title,
+		&current.Content,
+		&current.FeedView,
+		&current.Tags,
+		&current.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
 
-func (r *postRepo) IncrPostLikesBy(ctx context.Context, postID int64, n int64) error {
-	_, err := r.db.Exec(ctx, `
-		UPDATE posts
-		SET likes = CASE
-			WHEN likes + $1 >= 0 THEN likes + $1
-			ELSE 0
-		END
-		WHERE id = $2
-	`, n, postID)
-	return err
+	return &current, nil
 }
 
-func (r *postRepo) Unlike(ctx context.Context, postID int64, userID uuid.UUID) bool {
-	cmd, err := r.db.Exec(ctx, "DELETE FROM post_likes WHERE post_id = $1 AND user_id = $2", postID, userID)
-	return err == nil && cmd.RowsAffected() == 1
-}
+// Returns the version with fields applied and the names of the fields that actually changed
+func applyRevisionFields(version model.PostRevision, fields map[string]any) (model.PostRevision, []string) {
+	changedFields := []string{}
 
-func (r *postRepo) IsLiked(ctx context.Context, postID int64, userID uuid.UUID) bool {
-	var exists bool
-	var err error
+	// Tags are kept sorted, so they can be compared as is
+	if tags, ok := fields["tags"].([]string); ok && !slices.Equal(version.Tags, tags) {
+		version.Tags = tags
+		changedFields = append(changedFields, "tags")
+	}
 
-	for retries := 0; retries < 3; retries++ {
-		err = r.db.QueryRow(ctx, "SELECT count(*) > 0 FROM post_likes WHERE post_id = $1 AND user_id = $2", postID, userID).Scan(&exists)
-		if err == nil {
-			break
+	for _, column := range []string{"title", "content", "feed_view"} {
+		value, ok := fields[column].(string)
+		if !ok {
+			continue
 		}
-		if err.Error() == "conn busy" {
-			time.Sleep(time.Duration(retries+1) * time.Millisecond * 100)
+
+		var field *string
+		switch column {
+		case "title":
+			field = &version.Title
+		case "content":
+			field = &version.Content
+		case "feed_view":
+			field = &version.FeedView
+		}
+		if *field == value {
 			continue
 		}
-		break
-	}
 
-	if err != nil {
-		r.logger.Sugar().Errorf("failed to get is liked for user(%s): %s", userID.String(), err.Error())
-		return false
+		*field = value
+		changedFields = append(changedFields, column)
 	}
 
-	return exists
+	return version, changedFields
 }
 
-func (r *postRepo) FindUserLikes(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]*model.FullPost, error) {
-	maxLimit(&limit)
+// Posts edited before revisions existed get their current version saved as a baseline revision
+func ensureBaselineRevision(ctx context.Context, tx pgx.Tx, current model.PostRevision) error {
+	var hasRevisions bool
+	if err := tx.QueryRow(ctx, "SELECT count(*) > 0 FROM post_revisions WHERE post_id = $1", current.PostID).Scan(&hasRevisions); err != nil {
+		return err
+	}
+	if hasRevisions {
+		return nil
+	}
+
+	current.ChangedFields = []string{}
+	current.Status = model.REVISION_STATUS_APPLIED
+	_, err := insertRevision(ctx, tx, current)
+	return err
+}
 
-	rows, err := r.db.Query(
+func insertRevision(ctx context.Context, tx pgx.Tx, revision model.PostRevision) (int64, error) {
+	var id int64
+	err := tx.QueryRow(
 		ctx,
-		`SELECT
-		p.id, p.author_id, p.title, p.content, p.feed_view, p.views, p.likes, p.created_at, p.updated_at, u.username, u.display_name, u.avatar_url, t.tag
-		FROM post_likes l
-		JOIN posts p ON p.validated AND l.post_id = p.id
-		JOIN cached_users u ON p.author_id = u.id
-		LEFT JOIN post_tags t ON p.id = t.post_id
-		WHERE l.user_id = $1
-		ORDER BY l.created_at DESC
-		LIMIT $2
-		OFFSET $3`,
-		userID,
-		limit,
-		offset,
-	)
+		"INSERT INTO post_revisions(post_id, author_id, title, content, feed_view, tags, changed_fields, status, created_at) VALUES($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id",
+		revision.PostID,
+		revision.AuthorID,
+		revision.Title,
+		revision.Content,
+		revision.FeedView,
+		revision.Tags,
+		revision.ChangedFields,
+		revision.Status,
+		revision.CreatedAt,
+	).Scan(&id)
+	return id, err
+}
+
+// Records the moderator's decision. Rejection is applied right away, approval is counted and the post
+// is validated once it has requiredApprovals approvals of different moderators. Returns true if the decision
+// was applied and false if the post is waiting for more approvals.
+// Returns ErrPostAlreadyApprovedByModerator if the moderator has already approved the post.
+// beforeCommit runs right before the decision is committed, its error rolls the decision back
+func (r *postRepo) UpdateValidationStatus(ctx context.Context, id int64, moderatorID uuid.UUID, validated bool, validationStatusMsg string, requiredApprovals int, beforeCommit func(ctx context.Context) error) (bool, error) {
+	tx, err := r.db.Begin(ctx)
 	if err != nil {
-		return nil, err
+		return false, err
 	}
-	defer rows.Close()
+	defer tx.Rollback(ctx)
 
-	postsMap := make(map[int64]*model.FullPost)
-	for rows.Next() {
-		var (
-			id int64
-			authorID uuid.UUID
-			title string
-			content string
-			feedView string
-			views int64
-			likes int64
-			createdAt time.Time
-			updatedAt time.Time
-			username string
-			displayName *string
-			avatarURL *string
-			tag *string
-		)
-		if err := rows.Scan(
-			&id,
-			&authorID,
-			&title,
-			&content,
-			&feedView,
-			&views,
-			&likes,
-			&createdAt,
-			&updatedAt,
-			&username,
-			&displayName,
-			&avatarURL,
-			&tag,
-		); err != nil {
-			return nil, err
-		}
+	applied, err := recordValidationDecision(ctx, tx, id, moderatorID, validated, validationStatusMsg, requiredApprovals, nil)
+	if err != nil {
+		return false, err
+	}
 
-		post, ok := postsMap[id]
-		if !ok {
-			post = &model.FullPost{
-				Post: model.Post{
-					ID: id,
-					AuthorID: authorID,
-					Title: title,
-					Content: content,
-					FeedView: feedView,
-					Views: views,
-					Likes: likes,
-					CreatedAt: createdAt,
-					UpdatedAt: updatedAt,
-					Validated: true,
-				},
-				Author: model.UserAuthor{
-					Username: username,
-					DisplayName: displayName,
-					AvatarURL: avatarURL,
-				},
-				Tags: []string{},
-			}
-			postsMap[id] = post
+	if err := beforeCommit(ctx); err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, err
+	}
+
+	return applied, nil
+}
+
+// Records the decision in the transaction, see UpdateValidationStatus. submittedAt is when the decided
+// submission was made, nil is when the post entered the moderation queue
+func recordValidationDecision(ctx context.Context, tx pgx.Tx, id int64, moderatorID uuid.UUID, validated bool, validationStatusMsg string, requiredApprovals int, submittedAt *time.Time) (bool, error) {
+	// Concurrent approvals are counted one by one
+	var lockedID int64
+	if err := tx.QueryRow(ctx, "SELECT id FROM posts WHERE id = $1 FOR UPDATE", id).Scan(&lockedID); err != nil {
+		return false, err
+	}
+
+	if validated {
+		cmd, err := tx.Exec(ctx, "INSERT INTO post_approvals(post_id, moderator_id) VALUES($1, $2) ON CONFLICT DO NOTHING", id, moderatorID)
+		if err != nil {
+			return false, err
+		}
+		if cmd.RowsAffected() == 0 {
+			return false, ErrPostAlreadyApprovedByModerator
 		}
+	}
 
-		if tag != nil {
-			postsMap[post.Post.ID].Tags = append(postsMap[post.Post.ID].Tags, *tag)
+	// Submission time is kept with the decision, posts' created_at changes when scheduled posts are published
+	if _, err := tx.Exec(
+		ctx,
+		`INSERT INTO post_validation_status_contribs(post_id, moderator_id, validated, validation_status_msg, submitted_at)
+		SELECT $1, $2, $3, $4, COALESCE($5, p.queued_at, p.created_at) FROM posts p WHERE p.id = $1`,
+		id,
+		moderatorID,
+		validated,
+		validationStatusMsg,
+		submittedAt,
+	); err != nil {
+		return false, err
+	}
+
+	if validated {
+		var approvals int
+		if err := tx.QueryRow(ctx, "SELECT COUNT(*) FROM post_approvals WHERE post_id = $1", id).Scan(&approvals); err != nil {
+			return false, err
+		}
+		if approvals < requiredApprovals {
+			return false, nil
 		}
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
+	if _, err := tx.Exec(ctx, "UPDATE posts SET validated = $1, validation_status_msg = $2 WHERE id = $3", validated, validationStatusMsg, id); err != nil {
+		return false, err
 	}
 
-	var posts []*model.FullPost
-	for _, post := range postsMap {
-		posts = append(posts, post)
+	// Next moderation round starts without approvals
+	if _, err := tx.Exec(ctx, "DELETE FROM post_approvals WHERE post_id = $1", id); err != nil {
+		return false, err
 	}
 
-	if len(posts) == 0 {
-		return []*model.FullPost{}, nil
+	return true, nil
+}
+
+func (r *postRepo) Delete(ctx context.Context, id int64, authorID uuid.UUID) error {
+	cmd, err := r.db.Exec(ctx, "UPDATE posts SET deleted_at = $1 WHERE id = $2 AND author_id = $3 AND deleted_at IS NULL", time.Now(), id, authorID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return pgx.ErrNoRows
 	}
 
-	return posts, nil
+	return nil
 }
 
-func (r *postRepo) GetTrending(ctx context.Context, hours, limit int) ([]*model.FullPost, error) {
-	maxLimit(&limit)
+func (r *postRepo) Restore(ctx context.Context, id int64, authorID uuid.UUID) error {
+	cmd, err := r.db.Exec(ctx, "UPDATE posts SET deleted_at = NULL WHERE id = $1 AND author_id = $2 AND deleted_at IS NOT NULL", id, authorID)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
 
-	since := time.Now().Add(-time.Duration(hours) * time.Hour)
+// Deleted posts, most recently deleted first
+func (r *postRepo) FindAuthorDeletedPosts(ctx context.Context, authorID uuid.UUID, cursor *model.Cursor, limit int) ([]*model.AuthorPost, *model.Cursor, error) {
+	order := keyset{time: "p.deleted_at", id: "p.id"}
+	after, args := order.after(cursor, 3)
 
 	rows, err := r.db.Query(
 		ctx,
 		`
 		SELECT
-		p.id, p.author_id, p.title, p.content, p.feed_view, p.views, p.likes, p.created_at, p.updated_at,
-		u.username, u.display_name, u.avatar_url,
-		t.tag
+		`+authorPostColumns+`, `+order.columns()+`
 		FROM posts p
-		JOIN cached_users u ON p.author_id = u.id
-		LEFT JOIN post_tags t ON p.id = t.post_id
-		WHERE p.validated AND p.created_at >= $1
-		ORDER BY p.likes DESC, p.views DESC
+		WHERE p.deleted_at IS NOT NULL AND p.author_id = $1 AND `+after+`
+		ORDER BY `+order.orderBy()+`
 		LIMIT $2
 		`,
-		since, limit,
+		append([]any{authorID, limit + 1}, args...)...,
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
-	postsMap := make(map[int64]*model.FullPost)
-	for rows.Next() {
-		var (
-			id int64
-			authorID uuid.UUID
-			title string
-			content string
-			feedView string
-			views int64
-			likes int64
-			createdAt time.Time
-			updatedAt time.Time
-			username string
-			displayName *string
-			avatarURL *string
-			tag *string
-		)
-		if err := rows.Scan(
-			&id,
-			&authorID,
-			&title,
-			&content,
-			&feedView,
-			&views,
-			&likes,
-			&createdAt,
-			&updatedAt,
-			&username,
-			&displayName,
-			&avatarURL,
-			&tag,
-		); err != nil {
-			return nil, err
-		}
-
-		post, ok := postsMap[id]
-		if !ok {
-			post = &model.FullPost{
-				Post: model.Post{
-					ID: id,
-					AuthorID: authorID,
-					Title: title,
-					Content: content,
-					FeedView: feedView,
-					Views: views,
-					Likes: likes,
-					CreatedAt: createdAt,
-					UpdatedAt: updatedAt,
-					Validated: true,
-				},
-				Author: model.UserAuthor{
-					Username: username,
-					DisplayName: displayName,
-					AvatarURL: avatarURL,
-				},
-				Tags: []string{},
-			}
-			postsMap[id] = post
-		}
+	return collectPage(rows, limit, scanAuthorPost)
+}
 
-		if tag != nil {
-			postsMap[post.Post.ID].Tags = append(postsMap[post.Post.ID].Tags, *tag)
-		}
+func (r *postRepo) FindAuthorDeletedPost(ctx context.Context, id int64, authorID uuid.UUID) (*model.Post, error) {
+	var post model.Post
+	if err := r.db.QueryRow(
+		ctx,
+		"SELECT p.id, p.author_id, p.title, p.content, p.deleted_at FROM posts p WHERE p.id = $1 AND p.author_id = $2 AND p.deleted_at IS NOT NULL",
+		id,
+		authorID,
+	).Scan(
+		&post.ID,
+		&post.AuthorID,
+		&post.Title,
+		&post.Content,
+		&post.DeletedAt,
+	); err != nil {
+		return nil, err
 	}
 
-	if err := rows.Err(); err != nil {
+	return &post, nil
+}
+
+func (r *postRepo) FindDeletedBefore(ctx context.Context, before time.Time) ([]*model.Post, error) {
+	rows, err := r.db.Query(ctx, "SELECT p.id, p.author_id, p.title, p.content, p.deleted_at FROM posts p WHERE p.deleted_at < $1", before)
+	if err != nil {
 		return nil, err
 	}
+	defer rows.Close()
 
-	var posts []*model.FullPost
-	for _, post := range postsMap {
-		posts = append(posts, post)
+	var posts []*model.Post
+	for rows.Next() {
+		var post model.Post
+		if err := rows.Scan(
+			&post.ID,
+			&post.AuthorID,
+			&post.Title,
+			&post.Content,
+			&post.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		posts = append(posts, &post)
 	}
 
-	if len(posts) == 0 {
-		return []*model.FullPost{}, nil
+	if err := rows.Err(); err != nil {
+		return nil, err
 	}
 
 	return posts, nil
 }
 
-func (r *postRepo) SearchByTitle(ctx context.Context, title string, limit, offset int) ([]*model.FullPost, error) {
-	maxLimit(&limit)
+// Permanently deletes a soft-deleted post. Tags, likes and comments are removed by ON DELETE CASCADE
+func (r *postRepo) Purge(ctx context.Context, id int64) error {
+	_, err := r.db.Exec(ctx, "DELETE FROM posts WHERE id = $1 AND deleted_at IS NOT NULL", id)
+	return err
+}
 
-	search := "%" + title + "%"
+// Drafts, most recently updated first
+func (r *postRepo) FindAuthorDrafts(ctx context.Context, authorID uuid.UUID, cursor *model.Cursor, limit int) ([]*model.AuthorPost, *model.Cursor, error) {
+	order := keyset{time: "p.updated_at", id: "p.id"}
+	after, args := order.after(cursor, 3)
 
 	rows, err := r.db.Query(
 		ctx,
 		`
 		SELECT
-		p.id, p.author_id, p.title, p.content, p.feed_view, p.views, p.likes, p.created_at, p.updated_at
-		u.username, u.display_name, u.avatar_url,
-		t.tag
+		`+authorPostColumns+`, `+order.columns()+`
 		FROM posts p
-		JOIN cached_users u ON p.author_id = u.id
-		LEFT JOIN post_tags t ON p.id = t.post_id
-		WHERE p.validated AND p.title LIKE $1
-		ORDER BY p.created_at DESC, p.updated_at DESC
+		WHERE p.draft AND p.deleted_at IS NULL AND p.author_id = $1 AND `+after+`
+		ORDER BY `+order.orderBy()+`
 		LIMIT $2
-		OFFSET $3
-		`, search, limit, offset,
+		`,
+		append([]any{authorID, limit + 1}, args...)...,
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer rows.Close()
 
-		postsMap := make(map[int64]*model.FullPost)
-	for rows.Next() {
-		var (
-			id int64
-			authorID uuid.UUID
-			title string
-			content string
-			feedView string
-			views int64
-			likes int64
-			createdAt time.Time
-			updatedAt time.Time
-			username string
-			displayName *string
-			avatarURL *string
-			tag *string
-		)
-		if err := rows.Scan(
-			&id,
-			&authorID,
-			&title,
-			&content,
-			&feedView,
-			&views,
-			&likes,
-			&createdAt,
-			&updatedAt,
-			&username,
-			&displayName,
-			&avatarURL,
-			&tag,
-		); err != nil {
-			return nil, err
-		}
-
-		post, ok := postsMap[id]
-		if !ok {
-			post = &model.FullPost{
-				Post: model.Post{
-					ID: id,
-					AuthorID: authorID,
-					Title: title,
-					Content: content,
-					FeedView: feedView,
-					Views: views,
-					Likes: likes,
-					CreatedAt: createdAt,
-					UpdatedAt: updatedAt,
-					Validated: true,
-				},
-				Author: model.UserAuthor{
-					Username: username,
-					DisplayName: displayName,
-					AvatarURL: avatarURL,
-				},
-				Tags: []string{},
-			}
-			postsMap[id] = post
-		}
+	return collectPage(rows, limit, scanAuthorPost)
+}
 
-		if tag != nil {
-			postsMap[post.Post.ID].Tags = append(postsMap[post.Post.ID].Tags, *tag)
-		}
+// Returns author's post in any validation state, drafts and deleted posts are excluded
+func (r *postRepo) FindAuthorPost(ctx context.Context, id int64, authorID uuid.UUID) (*model.AuthorPost, error) {
+	rows, err := r.db.Query(
+		ctx,
+		`
+		SELECT
+		`+authorPostColumns+`
+		FROM posts p
+		WHERE NOT p.draft AND p.deleted_at IS NULL AND p.id = $1 AND p.author_id = $2
+		`,
+		id,
+		authorID,
+	)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
-	if err := rows.Err(); err != nil {
+	posts, err := collectAuthorPosts(rows)
+	if err != nil {
 		return nil, err
 	}
+	if len(posts) == 0 {
+		return nil, pgx.ErrNoRows
+	}
 
-	var posts []*model.FullPost
-	for _, post := range postsMap {
-		posts = append(posts, post)
+	return posts[0], nil
+}
+
+func (r *postRepo) FindAuthorDraft(ctx context.Context, id int64, authorID uuid.UUID) (*model.AuthorPost, error) {
+	rows, err := r.db.Query(
+		ctx,
+		`
+		SELECT
+		`+authorPostColumns+`
+		FROM posts p
+		WHERE p.draft AND p.deleted_at IS NULL AND p.id = $1 AND p.author_id = $2
+		`,
+		id,
+		authorID,
+	)
+	if err != nil {
+		return nil, err
 	}
+	defer rows.Close()
 
+	posts, err := collectAuthorPosts(rows)
+	if err != nil {
+		return nil, err
+	}
 	if len(posts) == 0 {
-		return []*model.FullPost{}, nil
+		return nil, pgx.ErrNoRows
 	}
 
-	return posts, nil
+	return posts[0], nil
 }
 
-func (r *postRepo) Update(ctx context.Context, id int64, authorID uuid.UUID, fields map[string]any) error {
+// Updates draft fields and, if tags is not nil, replaces draft tags
+func (r *postRepo) UpdateDraft(ctx context.Context, id int64, authorID uuid.UUID, fields map[string]any, tags []string) error {
 	allowedFields := []string{"title", "content", "feed_view"}
-	updates := map[string]any{}
-	for _, allowedField := range allowedFields {
-		for field, value := range fields {
-			if field == allowedField {
-				updates[field] = value
-			}
+
+	query := "UPDATE posts SET updated_at = $1"
+	args := []any{time.Now()}
+	for _, column := range allowedFields {
+		value, ok := fields[column]
+		if !ok {
+			continue
 		}
+		args = append(args, value)
+		query += ", " + column + " = $" + strconv.Itoa(len(args))
 	}
+	args = append(args, id, authorID)
+	query += " WHERE id = $" + strconv.Itoa(len(args)-1) + " AND author_id = $" + strconv.Itoa(len(args)) + " AND draft"
 
-	if len(updates) == 0 {
-		return nil
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
 	}
+	defer tx.Rollback(ctx)
 
-	query := "UPDATE posts SET updated_at = $1, "
-	args := []interface{}{}
-	i := 2
+	cmd, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if cmd.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
 
-	args = append(args, time.Now())
-	for column, value := range updates {
-		query += (column + " = $" + strconv.Itoa(i) + ", ")
-		args = append(args, value)
-		i += 1
+	if tags != nil {
+		if err := setPostTags(ctx, tx, id, tags); err != nil {
+			return err
+		}
 	}
 
-	query = query[:len(query)-2] + " WHERE id = $" + strconv.Itoa(i) + " AND author_id = $" + strconv.Itoa(i+1)
-	args = append(args, id, authorID)
+	if err := refreshSearchVector(ctx, tx, id); err != nil {
+		return err
+	}
 
-	_, err := r.db.Exec(ctx, query, args...)
-	return err
+	return tx.Commit(ctx)
 }
 
-func (r *postRepo) UpdateValidationStatus(ctx context.Context, id int64, moderatorID uuid.UUID, validated bool, validationStatusMsg string) error {
+// Turns a draft into a regular post that waits for validation.
+// If publishAt is not nil the post stays hidden until PublishDue is called after that time
+func (r *postRepo) Publish(ctx context.Context, id int64, authorID uuid.UUID, content string, publishAt *time.Time) (*model.Post, error) {
+	now := time.Now()
+
 	tx, err := r.db.Begin(ctx)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer tx.Rollback(ctx)
 
-	_, err = tx.Exec(ctx, "UPDATE posts SET validated = $1, validation_status_msg = $2 WHERE id = $3", validated, validationStatusMsg, id)
+	var post model.Post
+	if err := tx.QueryRow(
+		ctx,
+		`UPDATE posts SET draft = FALSE, validated = FALSE, content = $1, publish_at = $2, created_at = $3, updated_at = $3, queued_at = $3
+		WHERE id = $4 AND author_id = $5 AND draft AND deleted_at IS NULL
+		RETURNING id, author_id, title, content, feed_view, created_at, updated_at, publish_at`,
+		content,
+		publishAt,
+		now,
+		id,
+		authorID,
+	).Scan(
+		&post.ID,
+		&post.AuthorID,
+		&post.Title,
+		&post.Content,
+		&post.FeedView,
+		&post.CreatedAt,
+		&post.UpdatedAt,
+		&post.PublishAt,
+	); err != nil {
+		return nil, err
+	}
+
+	if err := refreshSearchVector(ctx, tx, post.ID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &post, nil
+}
+
+func (r *postRepo) DeleteDraft(ctx context.Context, id int64, authorID uuid.UUID) error {
+	cmd, err := r.db.Exec(ctx, "DELETE FROM posts WHERE id = $1 AND author_id = $2 AND draft", id, authorID)
 	if err != nil {
 		return err
 	}
+	if cmd.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+// Scans rows selected with authorPostColumns, keeping the order of the rows
+func collectAuthorPosts(rows pgx.Rows) ([]*model.AuthorPost, error) {
+	posts := []*model.AuthorPost{}
+	for rows.Next() {
+		post, err := scanAuthorPost(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+// Scans authorPostColumns followed by extra columns
+func scanAuthorPost(rows pgx.Rows, extraDest ...any) (*model.AuthorPost, error) {
+	var post model.AuthorPost
+	if err := rows.Scan(append(
+		[]any{
+			&post.Post.ID,
+			&post.Post.AuthorID,
+			&post.Post.Title,
+			&post.Post.Content,
+			&post.Post.FeedView,
+			&post.Post.Views,
+			&post.Post.Likes,
+			&post.Post.Reactions,
+			&post.Post.Comments,
+			&post.Post.Mentions,
+			&post.Post.CreatedAt,
+			&post.Post.UpdatedAt,
+			&post.Post.Validated,
+			&post.Post.ValidationStatusMsg,
+			&post.Post.Draft,
+			&post.Post.PublishAt,
+			&post.Post.DeletedAt,
+			&post.Post.HiddenAt,
+			&post.Tags,
+		},
+		extraDest...,
+	)...); err != nil {
+		return nil, err
+	}
+
+	return &post, nil
+}
+
+// Scheduled posts, the soonest to be published first
+func (r *postRepo) FindAuthorScheduledPosts(ctx context.Context, authorID uuid.UUID, cursor *model.Cursor, limit int) ([]*model.AuthorPost, *model.Cursor, error) {
+	order := keyset{time: "p.publish_at", id: "p.id", asc: true}
+	after, args := order.after(cursor, 3)
+
+	rows, err := r.db.Query(
+		ctx,
+		`
+		SELECT
+		`+authorPostColumns+`, `+order.columns()+`
+		FROM posts p
+		WHERE p.publish_at IS NOT NULL AND NOT p.draft AND p.deleted_at IS NULL AND p.author_id = $1 AND `+after+`
+		ORDER BY `+order.orderBy()+`
+		LIMIT $2
+		`,
+		append([]any{authorID, limit + 1}, args...)...,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	return collectPage(rows, limit, scanAuthorPost)
+}
 
-	_, err = tx.Exec(ctx, "INSERT INTO post_validation_status_contribs(post_id, moderator_id, validated, validation_status_msg) VALUES($1, $2, $3, $4)", id, moderatorID, validated, validationStatusMsg)
+func (r *postRepo) Reschedule(ctx context.Context, id int64, authorID uuid.UUID, publishAt time.Time) error {
+	cmd, err := r.db.Exec(
+		ctx,
+		"UPDATE posts SET publish_at = $1 WHERE id = $2 AND author_id = $3 AND publish_at IS NOT NULL AND NOT draft AND deleted_at IS NULL",
+		publishAt,
+		id,
+		authorID,
+	)
 	if err != nil {
 		return err
 	}
+	if cmd.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
 
-	if err := tx.Commit(ctx); err != nil {
+	return nil
+}
+
+// Cancels scheduled publishing by moving the post back to drafts
+func (r *postRepo) CancelSchedule(ctx context.Context, id int64, authorID uuid.UUID) error {
+	cmd, err := r.db.Exec(
+		ctx,
+		"UPDATE posts SET publish_at = NULL, draft = TRUE, updated_at = $1 WHERE id = $2 AND author_id = $3 AND publish_at IS NOT NULL AND NOT draft AND deleted_at IS NULL",
+		time.Now(),
+		id,
+		authorID,
+	)
+	if err != nil {
 		return err
 	}
+	if cmd.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
 
 	return nil
 }
+
+// Makes scheduled posts whose time has come visible and returns them
+func (r *postRepo) PublishDue(ctx context.Context, now time.Time) ([]*model.Post, error) {
+	rows, err := r.db.Query(
+		ctx,
+		`UPDATE posts SET created_at = publish_at, publish_at = NULL
+		WHERE publish_at <= $1 AND NOT draft AND deleted_at IS NULL
+		RETURNING id, author_id, title, created_at, validated`,
+		now,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []*model.Post
+	for rows.Next() {
+		var post model.Post
+		if err := rows.Scan(
+			&post.ID,
+			&post.AuthorID,
+			&post.Title,
+			&post.CreatedAt,
+			&post.Validated,
+		); err != nil {
+			return nil, err
+		}
+
+		posts = append(posts, &post)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// Returns any not deleted post regardless of its validation or publishing state
+func (r *postRepo) FindAnyByID(ctx context.Context, id int64) (*model.Post, error) {
+	var post model.Post
+	if err := r.db.QueryRow(
+		ctx,
+		`SELECT p.id, p.author_id, p.title, p.content, p.feed_view, p.views, p.likes, p.reactions, p.comments, p.mentions, p.created_at, p.updated_at, p.validated, p.validation_status_msg, p.draft, p.publish_at, p.hidden_at
+		FROM posts p
+		WHERE p.id = $1 AND p.deleted_at IS NULL`,
+		id,
+	).Scan(
+		&post.ID,
+		&post.AuthorID,
+		&post.Title,
+		&post.Content,
+		&post.FeedView,
+		&post.Views,
+		&post.Likes,
+		&post.Reactions,
+		&post.Comments,
+		&post.Mentions,
+		&post.CreatedAt,
+		&post.UpdatedAt,
+		&post.Validated,
+		&post.ValidationStatusMsg,
+		&post.Draft,
+		&post.PublishAt,
+		&post.HiddenAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return &post, nil
+}
+
+// Scans fullPostColumns followed by extra columns
+func scanFullPost(rows pgx.Rows, extraDest ...any) (*model.FullPost, error) {
+	var post model.FullPost
+	if err := rows.Scan(append(
+		[]any{
+			&post.Post.ID,
+			&post.Post.AuthorID,
+			&post.Post.Title,
+			&post.Post.Content,
+			&post.Post.FeedView,
+			&post.Post.Views,
+			&post.Post.Likes,
+			&post.Post.Reactions,
+			&post.Post.Comments,
+			&post.Post.Mentions,
+			&post.Post.CreatedAt,
+			&post.Post.UpdatedAt,
+			&post.Post.Validated,
+			&post.Post.ValidationStatusMsg,
+			&post.Author.Username,
+			&post.Author.DisplayName,
+			&post.Author.AvatarURL,
+			&post.Tags,
+		},
+		extraDest...,
+	)...); err != nil {
+		return nil, err
+	}
+
+	return &post, nil
+}
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/google/uuid"
+)
+
+// Returns the newest visible posts of the authors, used to build feed timelines
+func (r *postRepo) FindTimelineEntries(ctx context.Context, authorIDs []uuid.UUID, limit int) ([]*model.TimelineEntry, error) {
+	rows, err := r.db.Query(
+		ctx,
+		`SELECT p.id, p.created_at
+		FROM posts p
+		WHERE `+visiblePostCond+` AND p.author_id = ANY($1)
+		ORDER BY `+postsByCreatedAt.orderBy()+`
+		LIMIT $2`,
+		authorIDs,
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*model.TimelineEntry{}
+	for rows.Next() {
+		var entry model.TimelineEntry
+		if err := rows.Scan(&entry.PostID, &entry.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, &entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// Returns visible posts that are either in postIDs (taken from a timeline) or written by authorIDs, newest first.
+// Posts older than until aren't returned
+func (r *postRepo) FindFeed(ctx context.Context, postIDs []int64, authorIDs []uuid.UUID, cursor, until *model.Cursor, limit int) ([]*model.FullPost, *model.Cursor, error) {
+	after, args := postsByCreatedAt.after(cursor, 4)
+	upTo, untilArgs := postsByCreatedAt.until(until, 4+len(args))
+
+	rows, err := r.db.Query(
+		ctx,
+		`SELECT `+fullPostColumns+`, `+postsByCreatedAt.columns()+`
+		FROM posts p
+		JOIN cached_users u ON p.author_id = u.id
+		WHERE `+visiblePostCond+` AND (p.id = ANY($1) OR p.author_id = ANY($2)) AND `+after+` AND `+upTo+`
+		ORDER BY `+postsByCreatedAt.orderBy()+`
+		LIMIT $3`,
+		append(append([]any{postIDs, authorIDs, limit + 1}, args...), untilArgs...)...,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	return collectPage(rows, limit, scanFullPost)
+}
@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+// Words of the post's title and feed view ORed into a tsquery
+const relatedTextQuery = "NULLIF(replace(plainto_tsquery('" + textSearchConfig + "', s.title || ' ' || s.feed_view)::text, '&', '|'), '')::tsquery"
+
+// Returns IDs of visible posts related to the post, most related first.
+// Candidates share a tag, the author, a liker or words with the post. Every signal contributes
+// at most maxCandidates of its most recent posts and co-likes are read from at most maxCoLikers
+// recent likers and their recent likes, so the cost doesn't grow with popular tags or posts
+// Weights are cast to float8, otherwise their types are inferred from the integer signals and fractions are truncated
+func (r *postRepo) FindRelatedIDs(ctx context.Context, postID int64, weights model.RelatedPostsWeights, limit, maxCandidates, maxCoLikers int) ([]int64, error) {
+	rows, err := r.db.Query(
+		ctx,
+		`
+		WITH s AS (
+			SELECT p.id, p.author_id, p.title, p.feed_view FROM posts p WHERE p.id = $1
+		),
+		q AS (
+			SELECT `+relatedTextQuery+` AS query FROM s
+		),
+		tags AS (
+			SELECT b.post_id AS id, COUNT(*) AS shared
+			FROM post_tags a
+			CROSS JOIN LATERAL (
+				SELECT x.post_id FROM post_tags x WHERE x.tag = a.tag AND x.post_id <> a.post_id ORDER BY x.post_id DESC LIMIT $7
+			) b
+			WHERE a.post_id = $1
+			GROUP BY b.post_id
+		),
+		co_likes AS (
+			SELECT l2.post_id AS id, COUNT(*) AS shared
+			FROM (SELECT l.user_id FROM post_likes l WHERE l.post_id = $1 ORDER BY l.created_at DESC LIMIT $8) l1
+			CROSS JOIN LATERAL (
+				SELECT x.post_id FROM post_likes x WHERE x.user_id = l1.user_id AND x.post_id <> $1 ORDER BY x.created_at DESC LIMIT $8
+			) l2
+			GROUP BY l2.post_id
+		),
+		candidates AS (
+			SELECT id FROM tags
+			UNION
+			SELECT id FROM co_likes
+			UNION
+			(SELECT p.id FROM posts p JOIN s ON p.author_id = s.author_id ORDER BY p.created_at DESC LIMIT $7)
+			UNION
+			(SELECT p.id FROM posts p CROSS JOIN q WHERE p.search_vector @@ q.query ORDER BY p.created_at DESC LIMIT $7)
+		)
+		SELECT p.id
+		FROM candidates c
+		JOIN posts p ON p.id = c.id
+		CROSS JOIN s
+		CROSS JOIN q
+		LEFT JOIN tags t ON t.id = p.id
+		LEFT JOIN co_likes cl ON cl.id = p.id
+		WHERE `+visiblePostCond+` AND p.id <> s.id
+		ORDER BY
+			$2::float8 * COALESCE(t.shared, 0) +
+			$3::float8 * (p.author_id = s.author_id)::int +
+			$4::float8 * ln(1 + COALESCE(cl.shared, 0)) +
+			$5::float8 * COALESCE(ts_rank_cd(p.search_vector, q.query), 0)
+			DESC,
+			p.created_at DESC
+		LIMIT $6
+		`,
+		postID,
+		weights.Tags,
+		weights.Author,
+		weights.CoLikes,
+		weights.Text,
+		limit,
+		maxCandidates,
+		maxCoLikers,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[int64])
+}
+
+// Returns visible posts whose related posts may have changed since the time: new, edited or liked posts
+// and posts in ids. Zero time returns every visible post
+func (r *postRepo) FindRelatedRecomputeIDs(ctx context.Context, since time.Time, ids []int64) ([]int64, error) {
+	rows, err := r.db.Query(
+		ctx,
+		`SELECT p.id FROM posts p
+		WHERE `+visiblePostCond+` AND (
+			p.id = ANY($2) OR
+			p.created_at > $1 OR
+			p.updated_at > $1 OR
+			EXISTS (SELECT 1 FROM post_likes l WHERE l.post_id = p.id AND l.created_at > $1)
+		)`,
+		since,
+		ids,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[int64])
+}
@@ -0,0 +1,181 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type postRevisionRepo struct {
+	db *pgxpool.Pool
+}
+
+func newPostRevisionRepo(db *pgxpool.Pool) PostRevision {
+	return &postRevisionRepo{
+		db: db,
+	}
+}
+
+// Edit history of the author's post, newest first
+func (r *postRevisionRepo) FindPostRevisions(ctx context.Context, postID int64, authorID uuid.UUID, cursor *model.Cursor, limit int) ([]*model.PostRevision, *model.Cursor, error) {
+	order := keyset{time: "r.created_at", id: "r.id"}
+	after, args := order.after(cursor, 4)
+
+	rows, err := r.db.Query(
+		ctx,
+		`SELECT
+		r.id, r.post_id, r.author_id, r.title, r.content, r.feed_view, r.tags, r.changed_fields, r.status, r.created_at,
+		`+order.columns()+`
+		FROM post_revisions r
+		JOIN posts p ON p.id = r.post_id
+		WHERE r.post_id = $1 AND p.author_id = $2 AND `+after+`
+		ORDER BY `+order.orderBy()+`
+		LIMIT $3`,
+		append([]any{postID, authorID, limit + 1}, args...)...,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	return collectPage(rows, limit, func(rows pgx.Rows, keysetDest ...any) (*model.PostRevision, error) {
+		var revision model.PostRevision
+		if err := rows.Scan(append(
+			[]any{
+				&revision.ID,
+				&revision.PostID,
+				&revision.AuthorID,
+				&revision.Title,
+				&revision.Content,
+				&revision.FeedView,
+				&revision.Tags,
+				&revision.ChangedFields,
+				&revision.Status,
+				&revision.CreatedAt,
+			},
+			keysetDest...,
+		)...); err != nil {
+			return nil, err
+		}
+
+		return &revision, nil
+	})
+}
+
+func (r *postRevisionRepo) FindByID(ctx context.Context, postID, revisionID int64, authorID uuid.UUID) (*model.PostRevision, error) {
+	var revision model.PostRevision
+	if err := r.db.QueryRow(
+		ctx,
+		`SELECT
+		r.id, r.post_id, r.author_id, r.title, r.content, r.feed_view, r.tags, r.changed_fields, r.status, r.created_at
+		FROM post_revisions r
+		JOIN posts p ON p.id = r.post_id
+		WHERE r.id = $1 AND r.post_id = $2 AND p.author_id = $3`,
+		revisionID,
+		postID,
+		authorID,
+	).Scan(
+		&revision.ID,
+		&revision.PostID,
+		&revision.AuthorID,
+		&revision.Title,
+		&revision.Content,
+		&revision.FeedView,
+		&revision.Tags,
+		&revision.ChangedFields,
+		&revision.Status,
+		&revision.CreatedAt,
+	); err != nil {
+		return nil, err
+	}
+
+	return &revision, nil
+}
+
+func (r *postRevisionRepo) FindPostContents(ctx context.Context, postID int64) ([]string, error) {
+	rows, err := r.db.Query(ctx, "SELECT DISTINCT content FROM post_revisions WHERE post_id = $1", postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	contents := []string{}
+	for rows.Next() {
+		var content string
+		if err := rows.Scan(&content); err != nil {
+			return nil, err
+		}
+
+		contents = append(contents, content)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return contents, nil
+}
+
+// Post of the pending revision, pgx.ErrNoRows if the revision isn't pending
+func (r *postRevisionRepo) FindPendingPostID(ctx context.Context, revisionID int64) (int64, error) {
+	var postID int64
+	err := r.db.QueryRow(
+		ctx,
+		"SELECT post_id FROM post_revisions WHERE id = $1 AND status = $2",
+		revisionID,
+		model.REVISION_STATUS_PENDING,
+	).Scan(&postID)
+	return postID, err
+}
+
+// Pending edits of validated posts, oldest first
+func (r *postRevisionRepo) FindPendingRevisions(ctx context.Context, cursor *model.Cursor, limit int) ([]*model.PostRevision, *model.Cursor, error) {
+	order := keyset{time: "r.created_at", id: "r.id", asc: true}
+	after, args := order.after(cursor, 3)
+
+	rows, err := r.db.Query(
+		ctx,
+		`SELECT
+		r.id, r.post_id, r.author_id, r.title, r.content, r.feed_view, r.tags, r.changed_fields, r.status, r.created_at,
+		`+autoModerationColumns+`,
+		`+order.columns()+`
+		FROM post_revisions r
+		JOIN posts p ON p.id = r.post_id
+		LEFT JOIN revision_auto_moderation am ON am.revision_id = r.id
+		WHERE r.status = $1 AND p.deleted_at IS NULL AND `+after+`
+		ORDER BY `+order.orderBy()+`
+		LIMIT $2`,
+		append([]any{model.REVISION_STATUS_PENDING, limit + 1}, args...)...,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	return collectPage(rows, limit, func(rows pgx.Rows, keysetDest ...any) (*model.PostRevision, error) {
+		var revision model.PostRevision
+		var autoModeration autoModerationDest
+		dest := []any{
+			&revision.ID,
+			&revision.PostID,
+			&revision.AuthorID,
+			&revision.Title,
+			&revision.Content,
+			&revision.FeedView,
+			&revision.Tags,
+			&revision.ChangedFields,
+			&revision.Status,
+			&revision.CreatedAt,
+		}
+		dest = append(dest, autoModeration.dest()...)
+		if err := rows.Scan(append(dest, keysetDest...)...); err != nil {
+			return nil, err
+		}
+		revision.AutoModeration = autoModeration.result()
+
+		return &revision, nil
+	})
+}
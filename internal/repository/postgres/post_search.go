@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/jackc/pgx/v5"
+)
+
+// Text search configuration used both for posts.search_vector and for queries
+const textSearchConfig = "english"
+
+// Title and tags weigh the most, then the feed view, then the content
+const searchVectorExpr = `
+	setweight(to_tsvector('` + textSearchConfig + `', p.title), 'A') ||
+	setweight(to_tsvector('` + textSearchConfig + `', COALESCE((SELECT string_agg(t.tag, ' ') FROM post_tags t WHERE t.post_id = p.id), '')), 'A') ||
+	setweight(to_tsvector('` + textSearchConfig + `', p.feed_view), 'B') ||
+	setweight(to_tsvector('` + textSearchConfig + `', p.content), 'C')`
+
+const searchHeadlineOptions = "StartSel=<mark>, StopSel=</mark>, MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=\" ... \""
+
+// Must be called in every transaction that changes title, feed view, content or tags of posts
+func refreshSearchVector(ctx context.Context, tx pgx.Tx, ids ...int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, "UPDATE posts p SET search_vector = "+searchVectorExpr+" WHERE p.id = ANY($1)", ids)
+	return err
+}
+
+// Sets search vectors of posts written before they were introduced, returns the number of updated posts.
+// Called in batches until it returns 0
+func (r *postRepo) BackfillSearchVectors(ctx context.Context, limit int) (int64, error) {
+	cmd, err := r.db.Exec(
+		ctx,
+		"UPDATE posts p SET search_vector = "+searchVectorExpr+" WHERE p.id IN (SELECT id FROM posts WHERE search_vector IS NULL ORDER BY id LIMIT $1)",
+		limit,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return cmd.RowsAffected(), nil
+}
+
+// Built concurrently so posts stay writable. A failed concurrent build leaves an invalid index that
+// IF NOT EXISTS would skip, so it's dropped and built again
+func (r *postRepo) CreateSearchIndex(ctx context.Context) error {
+	var invalid bool
+	if err := r.db.QueryRow(
+		ctx,
+		"SELECT EXISTS (SELECT 1 FROM pg_index WHERE indexrelid = to_regclass('posts_search_vector_idx') AND NOT indisvalid)",
+	).Scan(&invalid); err != nil {
+		return err
+	}
+
+	if invalid {
+		if _, err := r.db.Exec(ctx, "DROP INDEX CONCURRENTLY IF EXISTS posts_search_vector_idx"); err != nil {
+			return err
+		}
+	}
+
+	_, err := r.db.Exec(ctx, "CREATE INDEX CONCURRENTLY IF NOT EXISTS posts_search_vector_idx ON posts USING GIN (search_vector)")
+	return err
+}
+
+// Ranked full-text search over visible posts, tsQuery must be valid input for to_tsquery
+func (r *postRepo) Search(ctx context.Context, tsQuery string, cursor *model.Cursor, limit int) ([]*model.PostSearchResult, *model.Cursor, error) {
+	order := keyset{score: "ts_rank_cd(p.search_vector, q.query)", time: "p.created_at", id: "p.id"}
+	after, args := order.after(cursor, 3)
+
+	rows, err := r.db.Query(
+		ctx,
+		`
+		WITH q AS (SELECT to_tsquery('`+textSearchConfig+`', $1) AS query)
+		SELECT
+		`+fullPostColumns+`,
+		ts_rank_cd(p.search_vector, q.query),
+		ts_headline('`+textSearchConfig+`', p.title, q.query, 'StartSel=<mark>, StopSel=</mark>, HighlightAll=true'),
+		ts_headline('`+textSearchConfig+`', p.feed_view || ' ' || p.content, q.query, '`+searchHeadlineOptions+`'),
+		`+order.columns()+`
+		FROM posts p
+		CROSS JOIN q
+		JOIN cached_users u ON p.author_id = u.id
+		WHERE `+visiblePostCond+` AND p.search_vector @@ q.query AND `+after+`
+		ORDER BY `+order.orderBy()+`
+		LIMIT $2
+		`,
+		append([]any{tsQuery, limit + 1}, args...)...,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	return collectPage(rows, limit, func(rows pgx.Rows, keysetDest ...any) (*model.PostSearchResult, error) {
+		var result model.PostSearchResult
+		post, err := scanFullPost(rows, append([]any{&result.Rank, &result.TitleHighlight, &result.Snippet}, keysetDest...)...)
+		if err != nil {
+			return nil, err
+		}
+
+		result.FullPost = *post
+		return &result, nil
+	})
+}
@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/BloggingApp/post-service/internal/model"
+)
+
+// Returns engagement of visible posts created after since
+func (r *postRepo) FindEngagementSince(ctx context.Context, since time.Time) ([]*model.PostEngagement, error) {
+	rows, err := r.db.Query(
+		ctx,
+		`SELECT
+		p.id, p.likes, p.views,
+		p.comments,
+		p.created_at,
+		ARRAY(SELECT pt.tag FROM post_tags pt WHERE pt.post_id = p.id)
+		FROM posts p
+		WHERE `+visiblePostCond+` AND p.created_at >= $1`,
+		since,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	engagements := []*model.PostEngagement{}
+	for rows.Next() {
+		var engagement model.PostEngagement
+		if err := rows.Scan(
+			&engagement.PostID,
+			&engagement.Likes,
+			&engagement.Views,
+			&engagement.Comments,
+			&engagement.CreatedAt,
+			&engagement.Tags,
+		); err != nil {
+			return nil, err
+		}
+
+		engagements = append(engagements, &engagement)
+	}
+
+	return engagements, rows.Err()
+}
+
+// Returns visible posts in the order of ids, missing ones are skipped
+func (r *postRepo) FindByIDs(ctx context.Context, ids []int64) ([]*model.FullPost, error) {
+	rows, err := r.db.Query(
+		ctx,
+		`SELECT `+fullPostColumns+`
+		FROM unnest($1::bigint[]) WITH ORDINALITY x(id, n)
+		JOIN posts p ON p.id = x.id
+		JOIN cached_users u ON p.author_id = u.id
+		WHERE `+visiblePostCond+`
+		ORDER BY x.n`,
+		ids,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	posts := []*model.FullPost{}
+	for rows.Next() {
+		post, err := scanFullPost(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		posts = append(posts, post)
+	}
+
+	return posts, rows.Err()
+}
+
+// Sets comments counts of posts to the number of their comments that aren't deleted, minus changes
+// that are still pending in redis. Returns the number of corrected posts
+func (r *postRepo) RecountComments(ctx context.Context, pending map[int64]int64) (int64, error) {
+	postIDs := make([]int64, 0, len(pending))
+	deltas := make([]int64, 0, len(pending))
+	for postID, delta := range pending {
+		postIDs = append(postIDs, postID)
+		deltas = append(deltas, delta)
+	}
+
+	cmd, err := r.db.Exec(
+		ctx,
+		`WITH counts AS (
+			SELECT p.id, GREATEST(
+				(SELECT COUNT(*) FROM comments c WHERE c.post_id = p.id AND c.deleted_at IS NULL) - COALESCE(d.delta, 0),
+				0
+			) AS comments
+			FROM posts p
+			LEFT JOIN unnest($1::bigint[], $2::bigint[]) d(post_id, delta) ON d.post_id = p.id
+		)
+		UPDATE posts p SET comments = counts.comments
+		FROM counts
+		WHERE counts.id = p.id AND p.comments <> counts.comments`,
+		postIDs,
+		deltas,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return cmd.RowsAffected(), nil
+}
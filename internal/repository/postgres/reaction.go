@@ -0,0 +1,193 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type reactionRepo struct {
+	db *pgxpool.Pool
+}
+
+func newReactionRepo(db *pgxpool.Pool) Reaction {
+	return &reactionRepo{
+		db: db,
+	}
+}
+
+// Tables of a reaction target
+type reactionTable struct {
+	reactions string // user reactions table
+	column    string // target ID column of the reactions table
+	counts    string // table with the reactions counts column
+	visible   string // selects the target ID if users can react to it, $1 is the target ID
+}
+
+var reactionTables = map[string]reactionTable{
+	model.REACTION_TARGET_POST: {
+		reactions: "post_reactions",
+		column: "post_id",
+		counts: "posts",
+		visible: "SELECT p.id FROM posts p WHERE p.id = $1 AND " + visiblePostCond,
+	},
+	model.REACTION_TARGET_COMMENT: {
+		reactions: "comment_reactions",
+		column: "comment_id",
+		counts: "comments",
+		visible: "SELECT c.id FROM comments c JOIN posts p ON p.id = c.post_id WHERE c.id = $1 AND c.deleted_at IS NULL AND c.hidden_at IS NULL AND " + visiblePostCond,
+	},
+}
+
+func reactionTableOf(target string) (reactionTable, error) {
+	table, ok := reactionTables[target]
+	if !ok {
+		return reactionTable{}, fmt.Errorf("unknown reaction target: %s", target)
+	}
+	return table, nil
+}
+
+// Sets user's reaction to the target replacing the previous one, which is returned (empty if there was none).
+// Returns ErrReactionTargetIsNotVisible if the target doesn't exist or is hidden
+func (r *reactionRepo) React(ctx context.Context, target string, targetID int64, userID uuid.UUID, reactionType string) (string, error) {
+	table, err := reactionTableOf(target)
+	if err != nil {
+		return "", err
+	}
+
+	// Insert may conflict with a concurrent reaction of the same user, then it's replaced
+	for attempt := 0; attempt < 2; attempt++ {
+		var previous string
+		err := r.db.QueryRow(
+			ctx,
+			`UPDATE `+table.reactions+` r
+			SET type = $3, created_at = NOW()
+			FROM (SELECT type FROM `+table.reactions+` WHERE `+table.column+` = $1 AND user_id = $2 FOR UPDATE) old
+			WHERE r.`+table.column+` = $1 AND r.user_id = $2
+			RETURNING old.type`,
+			targetID,
+			userID,
+			reactionType,
+		).Scan(&previous)
+		if err == nil {
+			return previous, nil
+		}
+		if !errors.Is(err, pgx.ErrNoRows) {
+			return "", err
+		}
+
+		cmd, err := r.db.Exec(
+			ctx,
+			`INSERT INTO `+table.reactions+`(`+table.column+`, user_id, type)
+			SELECT id, $2, $3 FROM (`+table.visible+`) t(id)
+			ON CONFLICT DO NOTHING`,
+			targetID,
+			userID,
+			reactionType,
+		)
+		if err != nil {
+			return "", err
+		}
+		if cmd.RowsAffected() == 1 {
+			return "", nil
+		}
+
+		var visible bool
+		if err := r.db.QueryRow(ctx, "SELECT EXISTS ("+table.visible+")", targetID).Scan(&visible); err != nil {
+			return "", err
+		}
+		if !visible {
+			return "", ErrReactionTargetIsNotVisible
+		}
+	}
+
+	return "", fmt.Errorf("failed to set reaction of user(%s) to %s(%d)", userID.String(), target, targetID)
+}
+
+// Removes user's reaction to the target and returns its type, empty if there was none
+func (r *reactionRepo) Unreact(ctx context.Context, target string, targetID int64, userID uuid.UUID) (string, error) {
+	table, err := reactionTableOf(target)
+	if err != nil {
+		return "", err
+	}
+
+	var previous string
+	err = r.db.QueryRow(
+		ctx,
+		"DELETE FROM "+table.reactions+" WHERE "+table.column+" = $1 AND user_id = $2 RETURNING type",
+		targetID,
+		userID,
+	).Scan(&previous)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", nil
+	}
+
+	return previous, err
+}
+
+// Returns user's reactions to the targets by target ID, targets without reactions are missing
+func (r *reactionRepo) FindUserReactions(ctx context.Context, target string, targetIDs []int64, userID uuid.UUID) (map[int64]string, error) {
+	table, err := reactionTableOf(target)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(
+		ctx,
+		"SELECT "+table.column+", type FROM "+table.reactions+" WHERE "+table.column+" = ANY($1) AND user_id = $2",
+		targetIDs,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reactions := make(map[int64]string, len(targetIDs))
+	for rows.Next() {
+		var (
+			targetID int64
+			reactionType string
+		)
+		if err := rows.Scan(&targetID, &reactionType); err != nil {
+			return nil, err
+		}
+		reactions[targetID] = reactionType
+	}
+
+	return reactions, rows.Err()
+}
+
+// Adds deltas to the target's reactions counts, counts don't go below zero
+func (r *reactionRepo) IncrReactionsBy(ctx context.Context, target string, targetID int64, deltas model.ReactionCounts) error {
+	table, err := reactionTableOf(target)
+	if err != nil {
+		return err
+	}
+
+	types := make([]string, 0, len(deltas))
+	values := make([]int64, 0, len(deltas))
+	for reactionType, delta := range deltas {
+		types = append(types, reactionType)
+		values = append(values, delta)
+	}
+
+	_, err = r.db.Exec(
+		ctx,
+		`UPDATE `+table.counts+`
+		SET reactions = reactions || COALESCE((
+			SELECT jsonb_object_agg(d.type, GREATEST(COALESCE((reactions->>d.type)::bigint, 0) + d.delta, 0))
+			FROM unnest($2::text[], $3::bigint[]) AS d(type, delta)
+		), '{}'::jsonb)
+		WHERE id = $1`,
+		targetID,
+		types,
+		values,
+	)
+	return err
+}
@@ -0,0 +1,313 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type reportRepo struct {
+	db *pgxpool.Pool
+}
+
+func newReportRepo(db *pgxpool.Pool) Report {
+	return &reportRepo{
+		db: db,
+	}
+}
+
+// Tables of a report target
+type reportTable struct {
+	table   string // table with the hidden_at column
+	visible string // locks the target and selects its post ID if it can be reported, $1 is the target ID
+	find    string // locks the target in any state and selects its post ID and author ID, $1 is the target ID
+}
+
+var reportTables = map[string]reportTable{
+	model.REPORT_TARGET_POST: {
+		table: "posts",
+		visible: "SELECT p.id FROM posts p WHERE p.id = $1 AND " + visiblePostCond + " FOR UPDATE",
+		find: "SELECT p.id, p.author_id FROM posts p WHERE p.id = $1 FOR UPDATE",
+	},
+	model.REPORT_TARGET_COMMENT: {
+		table: "comments",
+		visible: "SELECT c.post_id FROM comments c JOIN posts p ON p.id = c.post_id WHERE c.id = $1 AND c.deleted_at IS NULL AND c.hidden_at IS NULL AND " + visiblePostCond + " FOR UPDATE OF c",
+		find: "SELECT c.post_id, c.author_id FROM comments c WHERE c.id = $1 FOR UPDATE",
+	},
+}
+
+func reportTableOf(target string) (reportTable, error) {
+	table, ok := reportTables[target]
+	if !ok {
+		return reportTable{}, fmt.Errorf("unknown report target: %s", target)
+	}
+	return table, nil
+}
+
+// Reports the target, a reporter has at most one open report of a target. Returns nil report if the reporter
+// has already reported it. The target is hidden when it gets hideThreshold open reports (never if zero),
+// the second returned value is true if the report hid it.
+// Returns ErrReportTargetIsNotVisible if the target doesn't exist or is hidden
+func (r *reportRepo) Create(ctx context.Context, report model.Report, hideThreshold int) (*model.Report, bool, error) {
+	table, err := reportTableOf(report.Target)
+	if err != nil {
+		return nil, false, err
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback(ctx)
+
+	// Target stays locked so concurrent reports don't miss the threshold
+	if err := tx.QueryRow(ctx, table.visible, report.TargetID).Scan(&report.PostID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, false, ErrReportTargetIsNotVisible
+		}
+		return nil, false, err
+	}
+
+	err = tx.QueryRow(
+		ctx,
+		`INSERT INTO reports(target, target_id, post_id, reporter_id, reason, details)
+		VALUES($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (target, target_id, reporter_id) WHERE resolved_at IS NULL DO NOTHING
+		RETURNING id, created_at`,
+		report.Target,
+		report.TargetID,
+		report.PostID,
+		report.ReporterID,
+		report.Reason,
+		report.Details,
+	).Scan(&report.ID, &report.CreatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	hidden := false
+	if hideThreshold > 0 {
+		var openReports int
+		if err := tx.QueryRow(
+			ctx,
+			"SELECT COUNT(*) FROM reports WHERE target = $1 AND target_id = $2 AND resolved_at IS NULL",
+			report.Target,
+			report.TargetID,
+		).Scan(&openReports); err != nil {
+			return nil, false, err
+		}
+
+		if openReports >= hideThreshold {
+			if _, err := tx.Exec(ctx, "UPDATE "+table.table+" SET hidden_at = NOW() WHERE id = $1", report.TargetID); err != nil {
+				return nil, false, err
+			}
+			hidden = true
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, false, err
+	}
+
+	return &report, hidden, nil
+}
+
+// Open reports queue order: most reported first, then the longest waiting. Ties are broken by the target,
+// posts and comments are told apart by the lowest bit of the keyset ID
+var reportQueueOrder = keyset{score: "-o.reports", time: "o.first_reported_at", id: "o.target_id * 2 + (o.target = 'post')::int", asc: true}
+
+// Returns targets with open reports, most reported first and the longest waiting first among equally reported.
+// Target is model.REPORT_TARGET_* or empty for all targets
+func (r *reportRepo) FindQueue(ctx context.Context, target string, cursor *model.Cursor, limit int) ([]*model.ReportedTarget, *model.Cursor, error) {
+	after, args := reportQueueOrder.after(cursor, 3)
+
+	rows, err := r.db.Query(
+		ctx,
+		`WITH open AS (
+			SELECT r.target, r.target_id, COUNT(*) AS reports, MIN(r.created_at) AS first_reported_at, MAX(r.created_at) AS last_reported_at
+			FROM reports r
+			WHERE r.resolved_at IS NULL AND ($1 = '' OR r.target = $1)
+			GROUP BY r.target, r.target_id
+		)
+		SELECT
+		o.target, o.target_id, COALESCE(p.id, c.post_id), COALESCE(p.author_id, c.author_id), COALESCE(p.title, c.content), o.reports,
+		(
+			SELECT jsonb_object_agg(x.reason, x.reports)
+			FROM (
+				SELECT r.reason, COUNT(*) AS reports
+				FROM reports r
+				WHERE r.target = o.target AND r.target_id = o.target_id AND r.resolved_at IS NULL
+				GROUP BY r.reason
+			) x
+		),
+		o.first_reported_at, o.last_reported_at, COALESCE(p.hidden_at, c.hidden_at),
+		`+reportQueueOrder.columns()+`
+		FROM open o
+		LEFT JOIN posts p ON o.target = 'post' AND p.id = o.target_id
+		LEFT JOIN comments c ON o.target = 'comment' AND c.id = o.target_id
+		WHERE (p.id IS NOT NULL OR c.id IS NOT NULL) AND `+after+`
+		ORDER BY `+reportQueueOrder.orderBy()+`
+		LIMIT $2`,
+		append([]any{target, limit + 1}, args...)...,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	return collectPage(rows, limit, func(rows pgx.Rows, keysetDest ...any) (*model.ReportedTarget, error) {
+		var reported model.ReportedTarget
+		if err := rows.Scan(append(
+			[]any{
+				&reported.Target,
+				&reported.TargetID,
+				&reported.PostID,
+				&reported.AuthorID,
+				&reported.Content,
+				&reported.Reports,
+				&reported.Reasons,
+				&reported.FirstReportedAt,
+				&reported.LastReportedAt,
+				&reported.HiddenAt,
+			},
+			keysetDest...,
+		)...); err != nil {
+			return nil, err
+		}
+
+		return &reported, nil
+	})
+}
+
+// Returns open reports of the target, oldest first
+func (r *reportRepo) FindTargetReports(ctx context.Context, target string, targetID int64) ([]*model.Report, error) {
+	rows, err := r.db.Query(
+		ctx,
+		`SELECT id, target, target_id, post_id, reporter_id, reason, details, created_at
+		FROM reports
+		WHERE target = $1 AND target_id = $2 AND resolved_at IS NULL
+		ORDER BY created_at, id`,
+		target,
+		targetID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	reports := []*model.Report{}
+	for rows.Next() {
+		var report model.Report
+		if err := rows.Scan(
+			&report.ID,
+			&report.Target,
+			&report.TargetID,
+			&report.PostID,
+			&report.ReporterID,
+			&report.Reason,
+			&report.Details,
+			&report.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		reports = append(reports, &report)
+	}
+
+	return reports, rows.Err()
+}
+
+// Resolves open reports of the target with the action and records the resolution.
+// Dismissed and warned targets are shown again, hidden and deleted ones stay hidden.
+// Posts are deleted here, comments have to be deleted after that so the deletion is recorded.
+// Returns pgx.ErrNoRows if the target has no open reports
+func (r *reportRepo) Resolve(ctx context.Context, target string, targetID int64, moderatorID uuid.UUID, action, note string) (*model.ReportResolution, error) {
+	table, err := reportTableOf(target)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	resolution := model.ReportResolution{
+		Target: target,
+		TargetID: targetID,
+		ModeratorID: moderatorID,
+		Action: action,
+		Note: note,
+		ReporterIDs: []uuid.UUID{},
+	}
+
+	if err := tx.QueryRow(ctx, table.find, targetID).Scan(&resolution.PostID, &resolution.AuthorID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.QueryRow(
+		ctx,
+		"INSERT INTO report_resolutions(target, target_id, moderator_id, action, note) VALUES($1, $2, $3, $4, $5) RETURNING id, created_at",
+		target,
+		targetID,
+		moderatorID,
+		action,
+		note,
+	).Scan(&resolution.ID, &resolution.CreatedAt); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(
+		ctx,
+		"UPDATE reports SET resolved_at = $1, resolution_id = $2 WHERE target = $3 AND target_id = $4 AND resolved_at IS NULL RETURNING reporter_id",
+		resolution.CreatedAt,
+		resolution.ID,
+		target,
+		targetID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	for rows.Next() {
+		var reporterID uuid.UUID
+		if err := rows.Scan(&reporterID); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		resolution.ReporterIDs = append(resolution.ReporterIDs, reporterID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(resolution.ReporterIDs) == 0 {
+		return nil, pgx.ErrNoRows
+	}
+
+	query := "UPDATE " + table.table + " SET hidden_at = NULL WHERE id = $1"
+	if action == model.REPORT_ACTION_HIDE || action == model.REPORT_ACTION_DELETE {
+		query = "UPDATE " + table.table + " SET hidden_at = COALESCE(hidden_at, NOW()) WHERE id = $1"
+	}
+	if action == model.REPORT_ACTION_DELETE && target == model.REPORT_TARGET_POST {
+		// Stays hidden if the author restores it
+		query = "UPDATE posts SET hidden_at = COALESCE(hidden_at, NOW()), deleted_at = COALESCE(deleted_at, NOW()) WHERE id = $1"
+	}
+	if _, err := tx.Exec(ctx, query, targetID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return &resolution, nil
+}
@@ -2,6 +2,7 @@ package postgres
 
 import (
 	"context"
+	"time"
 
 	"github.com/BloggingApp/post-service/internal/model"
 	"github.com/google/uuid"
@@ -20,27 +21,79 @@ func maxLimit(limit *int) {
 type Post interface {
 	Create(ctx context.Context, post model.Post, tags []string) (*model.Post, error)
 	FindByID(ctx context.Context, id int64) (*model.FullPost, error)
-	FindAuthorPosts(ctx context.Context, authorID uuid.UUID, limit int, offset int) ([]*model.AuthorPost, error)
-	FindUserNotValidatedPosts(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*model.AuthorPost, error)
-	FindNotValidatedPosts(ctx context.Context, limit, offset int) ([]*model.FullPost, error)
-	SearchByTags(ctx context.Context, tags []string, limit int, offset int) ([]*model.FullPost, error)
+	FindAuthorPosts(ctx context.Context, authorID uuid.UUID, cursor *model.Cursor, limit int) ([]*model.AuthorPost, *model.Cursor, error)
+	FindUserNotValidatedPosts(ctx context.Context, userID uuid.UUID, cursor *model.Cursor, limit int) ([]*model.AuthorPost, *model.Cursor, error)
+	FindNotValidatedPosts(ctx context.Context, notApprovedBy *uuid.UUID, cursor *model.Cursor, limit int) ([]*model.QueuedPost, *model.Cursor, error)
+	SearchByTags(ctx context.Context, tags []string, matchAll bool, cursor *model.Cursor, limit int) ([]*model.FullPost, *model.Cursor, error)
 	IncrViews(ctx context.Context, id int64) error
 	Like(ctx context.Context, postID int64, userID uuid.UUID) bool
 	IncrPostLikesBy(ctx context.Context, postID, n int64) error
+	IncrPostCommentsBy(ctx context.Context, postID, n int64) error
 	Unlike(ctx context.Context, postID int64, userID uuid.UUID) bool
 	IsLiked(ctx context.Context, postID int64, userID uuid.UUID) bool
-	FindUserLikes(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*model.FullPost, error)
-	GetTrending(ctx context.Context, hours, limit int) ([]*model.FullPost, error)
-	SearchByTitle(ctx context.Context, title string, limit, offset int) ([]*model.FullPost, error)
-	Update(ctx context.Context, id int64, authorID uuid.UUID, fields map[string]interface{}) error
-	UpdateValidationStatus(ctx context.Context, id int64, moderatorID uuid.UUID, validated bool, validationStatusMsg string) error
+	FindUserLikes(ctx context.Context, userID uuid.UUID, cursor *model.Cursor, limit int) ([]*model.FullPost, *model.Cursor, error)
+	FindEngagementSince(ctx context.Context, since time.Time) ([]*model.PostEngagement, error)
+	FindByIDs(ctx context.Context, ids []int64) ([]*model.FullPost, error)
+	FindRelatedIDs(ctx context.Context, postID int64, weights model.RelatedPostsWeights, limit, maxCandidates, maxCoLikers int) ([]int64, error)
+	FindRelatedRecomputeIDs(ctx context.Context, since time.Time, ids []int64) ([]int64, error)
+	Search(ctx context.Context, tsQuery string, cursor *model.Cursor, limit int) ([]*model.PostSearchResult, *model.Cursor, error)
+	BackfillSearchVectors(ctx context.Context, limit int) (int64, error)
+	CreateSearchIndex(ctx context.Context) error
+	RecountComments(ctx context.Context, pending map[int64]int64) (int64, error)
+	Update(ctx context.Context, id int64, authorID uuid.UUID, fields map[string]interface{}, revalidate bool) error
+	CreatePendingRevision(ctx context.Context, id int64, authorID uuid.UUID, fields map[string]any) (*model.PostRevision, error)
+	ReviewRevision(ctx context.Context, revisionID int64, moderatorID uuid.UUID, approved bool, statusMsg string, beforeCommit func(ctx context.Context) error) (*model.PostRevision, error)
+	FindAnyByID(ctx context.Context, id int64) (*model.Post, error)
+	FindAuthorPost(ctx context.Context, id int64, authorID uuid.UUID) (*model.AuthorPost, error)
+	UpdateValidationStatus(ctx context.Context, id int64, moderatorID uuid.UUID, validated bool, validationStatusMsg string, requiredApprovals int, beforeCommit func(ctx context.Context) error) (bool, error)
+	Delete(ctx context.Context, id int64, authorID uuid.UUID) error
+	Restore(ctx context.Context, id int64, authorID uuid.UUID) error
+	FindAuthorDeletedPosts(ctx context.Context, authorID uuid.UUID, cursor *model.Cursor, limit int) ([]*model.AuthorPost, *model.Cursor, error)
+	FindAuthorDeletedPost(ctx context.Context, id int64, authorID uuid.UUID) (*model.Post, error)
+	FindDeletedBefore(ctx context.Context, before time.Time) ([]*model.Post, error)
+	Purge(ctx context.Context, id int64) error
+	FindAuthorDrafts(ctx context.Context, authorID uuid.UUID, cursor *model.Cursor, limit int) ([]*model.AuthorPost, *model.Cursor, error)
+	FindAuthorDraft(ctx context.Context, id int64, authorID uuid.UUID) (*model.AuthorPost, error)
+	UpdateDraft(ctx context.Context, id int64, authorID uuid.UUID, fields map[string]any, tags []string) error
+	Publish(ctx context.Context, id int64, authorID uuid.UUID, content string, publishAt *time.Time) (*model.Post, error)
+	DeleteDraft(ctx context.Context, id int64, authorID uuid.UUID) error
+	FindAuthorScheduledPosts(ctx context.Context, authorID uuid.UUID, cursor *model.Cursor, limit int) ([]*model.AuthorPost, *model.Cursor, error)
+	Reschedule(ctx context.Context, id int64, authorID uuid.UUID, publishAt time.Time) error
+	CancelSchedule(ctx context.Context, id int64, authorID uuid.UUID) error
+	PublishDue(ctx context.Context, now time.Time) ([]*model.Post, error)
+	FindTimelineEntries(ctx context.Context, authorIDs []uuid.UUID, limit int) ([]*model.TimelineEntry, error)
+	FindFeed(ctx context.Context, postIDs []int64, authorIDs []uuid.UUID, cursor, until *model.Cursor, limit int) ([]*model.FullPost, *model.Cursor, error)
+}
+
+type PostRevision interface {
+	FindPostRevisions(ctx context.Context, postID int64, authorID uuid.UUID, cursor *model.Cursor, limit int) ([]*model.PostRevision, *model.Cursor, error)
+	FindByID(ctx context.Context, postID, revisionID int64, authorID uuid.UUID) (*model.PostRevision, error)
+	FindPostContents(ctx context.Context, postID int64) ([]string, error)
+	FindPendingRevisions(ctx context.Context, cursor *model.Cursor, limit int) ([]*model.PostRevision, *model.Cursor, error)
+	FindPendingPostID(ctx context.Context, revisionID int64) (int64, error)
+}
+
+type Tag interface {
+	FindTags(ctx context.Context, prefix string, limit, offset int) ([]*model.Tag, error)
+	Autocomplete(ctx context.Context, prefix string, limit int) ([]string, error)
+	Resolve(ctx context.Context, slugs []string) ([]string, []string, error)
+	Exists(ctx context.Context, slug string) (bool, error)
+	Merge(ctx context.Context, from []string, to string) ([]int64, error)
+	SetBanned(ctx context.Context, slug string, banned bool) ([]int64, error)
+	FindUsedSlugs(ctx context.Context) ([]string, error)
+	RemoveFromPosts(ctx context.Context, slugs []string) ([]int64, error)
 }
 
 type Comment interface {
 	Create(ctx context.Context, comment model.Comment) (*model.Comment, error)
-	FindPostComments(ctx context.Context, postID int64, limit int, offset int) ([]*model.FullComment, error)
-	FindCommentReplies(ctx context.Context, postID int64, commentID int64, limit int, offset int) ([]*model.FullComment, error)
-	Delete(ctx context.Context, postID int64, commentID int64, authorID uuid.UUID) error
+	FindPostComments(ctx context.Context, postID int64, cursor *model.Cursor, limit int) ([]*model.FullComment, *model.Cursor, error)
+	FindCommentReplies(ctx context.Context, postID int64, commentID int64, cursor *model.Cursor, limit int) ([]*model.FullComment, *model.Cursor, error)
+	FindTree(ctx context.Context, postID int64, parentID *int64, sort string, cursor *model.Cursor, maxDepth, breadth int) ([]*model.CommentTreeEntry, error)
+	Delete(ctx context.Context, postID int64, commentID int64, userID uuid.UUID, asModerator bool, reason string) error
+	Edit(ctx context.Context, postID, commentID int64, authorID uuid.UUID, content string, editableSince time.Time) (*model.Comment, error)
+	FindRevisions(ctx context.Context, postID, commentID int64, userID uuid.UUID, asModerator bool) ([]*model.CommentRevision, error)
+	DeleteTombstonesRevisions(ctx context.Context) (int64, error)
+	FindAuthorID(ctx context.Context, commentID int64) (uuid.UUID, error)
 	Like(ctx context.Context, commentID int64, userID uuid.UUID) bool
 	IncrCommentLikesBy(ctx context.Context, commentID int64, n int64) error
 	Unlike(ctx context.Context, commentID int64, userID uuid.UUID) bool
@@ -51,18 +104,106 @@ type UserCache interface {
 	Create(ctx context.Context, cachedUser model.CachedUser) error
 	Update(ctx context.Context, id uuid.UUID, updates map[string]interface{}) error
 	FindByID(ctx context.Context, id uuid.UUID) (*model.CachedUser, error)
+	FindByUsernames(ctx context.Context, usernames []string) ([]*model.CachedUser, error)
+}
+
+type Bookmark interface {
+	Create(ctx context.Context, postID int64, userID uuid.UUID) (bool, error)
+	Delete(ctx context.Context, postID int64, userID uuid.UUID) (bool, error)
+	IsBookmarked(ctx context.Context, postID int64, userID uuid.UUID) (bool, error)
+	FindUserBookmarks(ctx context.Context, userID uuid.UUID, cursor *model.Cursor, limit int) ([]*model.FullPost, *model.Cursor, error)
+	CreateCollection(ctx context.Context, userID uuid.UUID, name string, public bool) (*model.BookmarkCollection, error)
+	FindCollection(ctx context.Context, id int64) (*model.BookmarkCollection, error)
+	FindUserCollections(ctx context.Context, userID uuid.UUID, publicOnly bool) ([]*model.BookmarkCollection, error)
+	UpdateCollection(ctx context.Context, id int64, userID uuid.UUID, fields map[string]any) error
+	DeleteCollection(ctx context.Context, id int64, userID uuid.UUID) error
+	FindCollectionPosts(ctx context.Context, collectionID int64, cursor *model.Cursor, limit int) ([]*model.FullPost, *model.Cursor, error)
+	AddToCollection(ctx context.Context, collectionID int64, userID uuid.UUID, postID int64) error
+	RemoveFromCollection(ctx context.Context, collectionID int64, userID uuid.UUID, postID int64) error
+	ReorderCollection(ctx context.Context, collectionID int64, userID uuid.UUID, postIDs []int64) error
+}
+
+// Targets are model.REACTION_TARGET_* values
+type Reaction interface {
+	React(ctx context.Context, target string, targetID int64, userID uuid.UUID, reactionType string) (string, error)
+	Unreact(ctx context.Context, target string, targetID int64, userID uuid.UUID) (string, error)
+	FindUserReactions(ctx context.Context, target string, targetIDs []int64, userID uuid.UUID) (map[int64]string, error)
+	IncrReactionsBy(ctx context.Context, target string, targetID int64, deltas model.ReactionCounts) error
+}
+
+// Targets are model.MENTION_TARGET_* values
+type Mention interface {
+	Save(ctx context.Context, target string, targetID int64, mentions []model.Mention, record bool) ([]uuid.UUID, error)
+}
+
+// Targets are model.REPORT_TARGET_* values
+type Report interface {
+	Create(ctx context.Context, report model.Report, hideThreshold int) (*model.Report, bool, error)
+	FindQueue(ctx context.Context, target string, cursor *model.Cursor, limit int) ([]*model.ReportedTarget, *model.Cursor, error)
+	FindTargetReports(ctx context.Context, target string, targetID int64) ([]*model.Report, error)
+	Resolve(ctx context.Context, target string, targetID int64, moderatorID uuid.UUID, action, note string) (*model.ReportResolution, error)
+}
+
+type Appeal interface {
+	FindAppealableRejection(ctx context.Context, postID int64, authorID uuid.UUID) (*model.ModerationDecision, error)
+	Create(ctx context.Context, appeal model.PostAppeal) (*model.PostAppeal, error)
+	Withdraw(ctx context.Context, id int64) error
+	FindPostAppeals(ctx context.Context, postID int64, authorID uuid.UUID) ([]*model.PostAppeal, error)
+	FindQueue(ctx context.Context, moderatorID uuid.UUID, cursor *model.Cursor, limit int) ([]*model.AppealedPost, *model.Cursor, error)
+	FindPendingPostID(ctx context.Context, id int64) (int64, error)
+	Decide(ctx context.Context, id int64, moderatorID uuid.UUID, overturned bool, decisionMsg string, requiredApprovals int, beforeCommit func(ctx context.Context) error) (*model.PostAppeal, bool, error)
+}
+
+type AutoModeration interface {
+	FindAuthorHistory(ctx context.Context, authorID uuid.UUID) (*model.AuthorHistory, error)
+	Save(ctx context.Context, postID int64, result model.AutoModerationResult) error
+	SaveRevision(ctx context.Context, revisionID int64, result model.AutoModerationResult) error
+}
+
+type ModerationAudit interface {
+	FindDecisions(ctx context.Context, filter model.ModerationDecisionsFilter, cursor *model.Cursor, limit int) ([]*model.ModerationDecision, *model.Cursor, error)
+	FindModeratorStats(ctx context.Context, filter model.ModerationDecisionsFilter) ([]*model.ModeratorStats, error)
+}
+
+type Follow interface {
+	Create(ctx context.Context, follow model.Follow) error
+	Delete(ctx context.Context, followerID, followeeID uuid.UUID) error
+	FindFollowees(ctx context.Context, followerID uuid.UUID) ([]*model.Followee, error)
+	FindFollowers(ctx context.Context, followeeID uuid.UUID) ([]uuid.UUID, error)
+	CountFollowers(ctx context.Context, followeeID uuid.UUID) (int64, error)
+	RecountFollowers(ctx context.Context) (int64, error)
 }
 
 type PostgresRepository struct {
 	Post
+	PostRevision
+	Tag
 	Comment
 	UserCache
+	Follow
+	Bookmark
+	Reaction
+	Mention
+	Report
+	ModerationAudit
+	Appeal
+	AutoModeration
 }
 
 func New(db *pgxpool.Pool, logger *zap.Logger) *PostgresRepository {
 	return &PostgresRepository{
 		Post: newPostRepo(db, logger),
+		PostRevision: newPostRevisionRepo(db),
+		Tag: newTagRepo(db),
 		Comment: newCommentRepo(db, logger),
 		UserCache: newUserCacheRepo(db),
+		Follow: newFollowRepo(db),
+		Bookmark: newBookmarkRepo(db),
+		Reaction: newReactionRepo(db),
+		Mention: newMentionRepo(db),
+		Report: newReportRepo(db),
+		ModerationAudit: newModerationAuditRepo(db),
+		Appeal: newAppealRepo(db),
+		AutoModeration: newAutoModerationRepo(db),
 	}
 }
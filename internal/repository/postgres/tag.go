@@ -0,0 +1,314 @@
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type tagRepo struct {
+	db *pgxpool.Pool
+}
+
+func newTagRepo(db *pgxpool.Pool) Tag {
+	return &tagRepo{
+		db: db,
+	}
+}
+
+// Escapes LIKE wildcards so the prefix is matched literally
+func likePrefix(prefix string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix) + "%"
+}
+
+// Returns tags of visible posts ordered by posts count, empty prefix matches every tag.
+// Counts are aggregated per request and change between pages, so this list is paginated with offsets
+func (r *tagRepo) FindTags(ctx context.Context, prefix string, limit, offset int) ([]*model.Tag, error) {
+	maxLimit(&limit)
+
+	rows, err := r.db.Query(
+		ctx,
+		`SELECT
+		t.tag,
+		COUNT(*),
+		COUNT(*) FILTER (WHERE p.created_at > NOW() - INTERVAL '7 days'),
+		MAX(p.created_at)
+		FROM post_tags t
+		JOIN posts p ON p.id = t.post_id
+		WHERE `+visiblePostCond+` AND t.tag LIKE $1
+		GROUP BY t.tag
+		ORDER BY COUNT(*) DESC, MAX(p.created_at) DESC, t.tag
+		LIMIT $2
+		OFFSET $3`,
+		likePrefix(prefix),
+		limit,
+		offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []*model.Tag{}
+	for rows.Next() {
+		var tag model.Tag
+		if err := rows.Scan(&tag.Tag, &tag.PostsCount, &tag.RecentPostsCount, &tag.LastPostAt); err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, &tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// Returns names of the most used tags starting with prefix
+func (r *tagRepo) Autocomplete(ctx context.Context, prefix string, limit int) ([]string, error) {
+	rows, err := r.db.Query(
+		ctx,
+		`SELECT t.tag
+		FROM post_tags t
+		JOIN posts p ON p.id = t.post_id
+		WHERE `+visiblePostCond+` AND t.tag LIKE $1
+		GROUP BY t.tag
+		ORDER BY COUNT(*) DESC, t.tag
+		LIMIT $2`,
+		likePrefix(prefix),
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := []string{}
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return tags, rows.Err()
+}
+
+// Maps normalized tags to their canonical tags through aliases, keeping the order.
+// Canonical tags that are banned are returned separately
+func (r *tagRepo) Resolve(ctx context.Context, slugs []string) ([]string, []string, error) {
+	rows, err := r.db.Query(
+		ctx,
+		`SELECT COALESCE(a.tag, s.slug), COALESCE(t.banned, FALSE)
+		FROM unnest($1::text[]) WITH ORDINALITY s(slug, n)
+		LEFT JOIN tag_aliases a ON a.alias = s.slug
+		LEFT JOIN tags t ON t.slug = COALESCE(a.tag, s.slug)
+		ORDER BY s.n`,
+		slugs,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	canonical := []string{}
+	banned := []string{}
+	for rows.Next() {
+		var (
+			slug string
+			isBanned bool
+		)
+		if err := rows.Scan(&slug, &isBanned); err != nil {
+			return nil, nil, err
+		}
+
+		if isBanned {
+			banned = append(banned, slug)
+			continue
+		}
+		canonical = append(canonical, slug)
+	}
+
+	return canonical, banned, rows.Err()
+}
+
+func (r *tagRepo) Exists(ctx context.Context, slug string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM tags WHERE slug = $1)", slug).Scan(&exists)
+	return exists, err
+}
+
+// Moves posts from the tags to the target tag and turns the tags into its aliases.
+// Aliases of the merged tags are repointed to the target. Returns IDs of affected posts
+func (r *tagRepo) Merge(ctx context.Context, from []string, to string) ([]int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, "INSERT INTO tags(slug) VALUES($1) ON CONFLICT DO NOTHING", to); err != nil {
+		return nil, err
+	}
+
+	postIDs, err := findTagsPostIDs(ctx, tx, from)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(
+		ctx,
+		`INSERT INTO post_tags(post_id, tag)
+		SELECT DISTINCT t.post_id, $2::text FROM post_tags t
+		WHERE t.tag = ANY($1) AND NOT EXISTS (SELECT 1 FROM post_tags x WHERE x.post_id = t.post_id AND x.tag = $2)`,
+		from,
+		to,
+	); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM post_tags WHERE tag = ANY($1)", from); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, "UPDATE tag_aliases SET tag = $2 WHERE tag = ANY($1)", from, to); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(
+		ctx,
+		"INSERT INTO tag_aliases(alias, tag) SELECT unnest($1::text[]), $2 ON CONFLICT (alias) DO UPDATE SET tag = EXCLUDED.tag",
+		from,
+		to,
+	); err != nil {
+		return nil, err
+	}
+
+	// Target is a canonical tag now, so it can't stay an alias
+	if _, err := tx.Exec(ctx, "DELETE FROM tag_aliases WHERE alias = $1", to); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM tags WHERE slug = ANY($1)", from); err != nil {
+		return nil, err
+	}
+
+	if err := refreshSearchVector(ctx, tx, postIDs...); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return postIDs, nil
+}
+
+// Banned tag is removed from all posts and can't be added again until unbanned.
+// Returns IDs of affected posts
+func (r *tagRepo) SetBanned(ctx context.Context, slug string, banned bool) ([]int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(
+		ctx,
+		"INSERT INTO tags(slug, banned) VALUES($1, $2) ON CONFLICT (slug) DO UPDATE SET banned = EXCLUDED.banned",
+		slug,
+		banned,
+	); err != nil {
+		return nil, err
+	}
+
+	postIDs := []int64{}
+	if banned {
+		postIDs, err = findTagsPostIDs(ctx, tx, []string{slug})
+		if err != nil {
+			return nil, err
+		}
+
+		if _, err := tx.Exec(ctx, "DELETE FROM post_tags WHERE tag = $1", slug); err != nil {
+			return nil, err
+		}
+
+		if err := refreshSearchVector(ctx, tx, postIDs...); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return postIDs, nil
+}
+
+// Returns every tag attached to posts, including tags written before normalization
+func (r *tagRepo) FindUsedSlugs(ctx context.Context) ([]string, error) {
+	rows, err := r.db.Query(ctx, "SELECT DISTINCT tag FROM post_tags")
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[string])
+}
+
+// Detaches the tags from all posts. Returns IDs of affected posts
+func (r *tagRepo) RemoveFromPosts(ctx context.Context, slugs []string) ([]int64, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	postIDs, err := findTagsPostIDs(ctx, tx, slugs)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(ctx, "DELETE FROM post_tags WHERE tag = ANY($1)", slugs); err != nil {
+		return nil, err
+	}
+
+	if err := refreshSearchVector(ctx, tx, postIDs...); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+
+	return postIDs, nil
+}
+
+func findTagsPostIDs(ctx context.Context, tx pgx.Tx, tags []string) ([]int64, error) {
+	rows, err := tx.Query(ctx, "SELECT DISTINCT post_id FROM post_tags WHERE tag = ANY($1)", tags)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, pgx.RowTo[int64])
+}
+
+// Replaces post tags, tags must be canonical
+func setPostTags(ctx context.Context, tx pgx.Tx, postID int64, tags []string) error {
+	if _, err := tx.Exec(ctx, "DELETE FROM post_tags WHERE post_id = $1", postID); err != nil {
+		return err
+	}
+
+	if len(tags) == 0 {
+		return nil
+	}
+
+	if _, err := tx.Exec(ctx, "INSERT INTO tags(slug) SELECT unnest($1::text[]) ON CONFLICT DO NOTHING", tags); err != nil {
+		return err
+	}
+
+	_, err := tx.Exec(ctx, "INSERT INTO post_tags(post_id, tag) SELECT $1, unnest($2::text[])", postID, tags)
+	return err
+}
@@ -84,3 +84,32 @@ func (r *userCacheRepo) FindByID(ctx context.Context, id uuid.UUID) (*model.Cach
 
 	return &user, nil
 }
+
+// Returns cached users with the usernames, compared case-insensitively
+func (r *userCacheRepo) FindByUsernames(ctx context.Context, usernames []string) ([]*model.CachedUser, error) {
+	rows, err := r.db.Query(
+		ctx,
+		"SELECT u.id, u.username, u.display_name, u.avatar_url FROM cached_users u WHERE LOWER(u.username) = ANY($1)",
+		usernames,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []*model.CachedUser{}
+	for rows.Next() {
+		var user model.CachedUser
+		if err := rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.DisplayName,
+			&user.AvatarURL,
+		); err != nil {
+			return nil, err
+		}
+		users = append(users, &user)
+	}
+
+	return users, rows.Err()
+}
@@ -8,53 +8,102 @@ import (
 
 const (
 	POST_KEY = "post:%d" // <postID>
-	AUTHOR_POSTS_KEY = "author:%s-posts:%d:%d" // <authorID>:<limit>:<offset>
-	USER_NOT_VALIDATED_POSTS_KEY = "user:%s-not-validated-posts:%d:%d" // <userID>:<limit>:<offset>
-	NOT_VALIDATED_POSTS_KEY = "not-validated-posts:%d:%d" // <limit>:<offset>
+	AUTHOR_POSTS_KEY = "author:%s-posts:%s:%d" // <authorID>:<cursor>:<limit>
+	AUTHOR_POSTS_KEY_PATTERN = "author:*-posts:*"
+	USER_NOT_VALIDATED_POSTS_KEY = "user:%s-not-validated-posts:%s:%d" // <userID>:<cursor>:<limit>
+	NOT_VALIDATED_POSTS_KEY = "not-validated-posts:%s:%d" // <cursor>:<limit>
 	USER_CACHE_KEY = "user-cache:%s" // <userID>
-	POST_COMMENTS_KEY = "post:%d-comments:%d:%d" // <postID>:<limit>:<offset>
-	COMMENT_REPLIES_KEY = "post:%d-comment:%d-replies:%d:%d" // <postID>:<commentID>:<limit>:<offset>
-	USER_LIKES_KEY = "user:%s-likes:%d:%d" // <userID>:<limit>:<offset>
+	POST_COMMENTS_KEY = "post:%d-comments:%s:%d" // <postID>:<cursor>:<limit>
+	POST_COMMENTS_KEY_PATTERN = "post:%d-comments:*" // <postID>
+	POST_COMMENTS_TREE_KEY = "post:%d-comments-tree:%d:%s:%s:%d:%d" // <postID>:<parentID, 0 for top level>:<sort>:<cursor>:<depth>:<breadth>
+	POST_COMMENTS_TREE_KEY_PATTERN = "post:%d-comments-tree:*" // <postID>
+	COMMENT_REPLIES_KEY = "post:%d-comment:%d-replies:%s:%d" // <postID>:<commentID>:<cursor>:<limit>
+	POST_COMMENT_REPLIES_KEY_PATTERN = "post:%d-comment:*-replies:*" // <postID>
+	USER_LIKES_KEY = "user:%s-likes:%s:%d" // <userID>:<cursor>:<limit>
 	IS_LIKED_POST_KEY = "user:%s-is-liked-post:%d" // <userID>:<postID>
 	POST_LIKES_KEY = "post-likes:%d" // <postID>
 	POST_LIKES_KEY_PATTERN = "post-likes:*"
+	POST_COMMENTS_COUNT_KEY = "post-comments-count:%d" // <postID>
+	POST_COMMENTS_COUNT_KEY_PATTERN = "post-comments-count:*"
 	COMMENT_LIKES_KEY = "comment-likes:%d" // <commentID>
 	COMMENT_LIKES_KEY_PATTERN = "comment-likes:*"
 	IS_LIKED_COMMENT_KEY = "user:%s-is-liked-comment:%d" // <userID>:<commentID>
-	TRENDING_POSTS_KEY = "trending-posts:%d" // <limit>
-	SEARCH_POSTS_RESULT_BY_TITLE_KEY = "search-posts-result-by-title:%s:%d:%d" // <title>:<limit>:<offset>
+	TRENDING_POSTS_KEY = "trending-posts:%s" // <window>
+	TRENDING_TAG_POSTS_KEY = "trending-posts:%s:tag:%s" // <window>:<tag>
+	TRENDING_TAG_POSTS_KEY_PATTERN = "trending-posts:%s:tag:*" // <window>
+	DRAFT_AUTOSAVE_KEY = "draft-autosave:%d" // <postID>
+	DRAFT_AUTOSAVE_KEY_PATTERN = "draft-autosave:*"
+	TAGS_POSTS_KEY = "tags-posts:%s:%s:%s:%d" // <mode>:<comma separated tags>:<cursor>:<limit>
+	TAGS_POSTS_KEY_PATTERN = "tags-posts:*"
+	TAGS_KEY = "tags:%s:%d:%d" // <prefix>:<limit>:<offset>
+	TAGS_KEY_PATTERN = "tags:*"
+	TAGS_AUTOCOMPLETE_KEY = "tags-autocomplete:%s" // <prefix>
+	TAGS_AUTOCOMPLETE_KEY_PATTERN = "tags-autocomplete:*"
+	SEARCH_POSTS_RESULT_KEY = "search-posts-result:%s:%s:%d" // <normalizedQuery>:<cursor>:<limit>
+	SEARCH_POSTS_RESULT_KEY_PATTERN = "search-posts-result:*"
+	USER_TIMELINE_KEY = "user:%s-timeline" // <userID>
+	RELATED_POSTS_KEY = "post:%d-related" // <postID>
+	RELATED_POSTS_STALE_KEY = "related-posts:stale" // set of post IDs to recompute
+	RELATED_POSTS_COMPUTED_KEY = "related-posts:computed" // post IDs scored by when their related posts were computed
+	RELATED_POSTS_RECOMPUTED_AT_KEY = "related-posts:recomputed-at"
+	USER_BOOKMARKS_KEY = "user:%s-bookmarks:%s:%d" // <userID>:<cursor>:<limit>
+	USER_BOOKMARKS_KEY_PATTERN = "user:%s-bookmarks:*" // <userID>
+	IS_BOOKMARKED_POST_KEY = "user:%s-is-bookmarked-post:%d" // <userID>:<postID>
+	USER_BOOKMARK_COLLECTIONS_KEY = "user:%s-bookmark-collections:%t" // <userID>:<publicOnly>
+	BOOKMARK_COLLECTION_POSTS_KEY = "bookmark-collection:%d-posts:%s:%d" // <collectionID>:<cursor>:<limit>
+	BOOKMARK_COLLECTION_POSTS_KEY_PATTERN = "bookmark-collection:%d-posts:*" // <collectionID>
+	REACTIONS_KEY = "%s-reactions:%d" // <target>:<targetID>, hash of not flushed per-type deltas
+	REACTIONS_KEY_PATTERN = "%s-reactions:*" // <target>
+	USER_REACTION_KEY = "user:%s-reaction-%s:%d" // <userID>:<target>:<targetID>
+	MODERATION_CLAIM_KEY = "moderation-claim:%d" // <postID>, moderator ID of the lease
 )
 
 func PostKey(postID int64) string {
 	return fmt.Sprintf(POST_KEY, postID)
 }
 
-func AuthorPostsKey(authorID string, limit, offset int) string {
-	return fmt.Sprintf(AUTHOR_POSTS_KEY, authorID, limit, offset)
+func AuthorPostsKey(authorID string, cursor string, limit int) string {
+	return fmt.Sprintf(AUTHOR_POSTS_KEY, authorID, cursor, limit)
 }
 
-func UserNotValidatedPostsKey(userID string, limit, offset int) string {
-	return fmt.Sprintf(USER_NOT_VALIDATED_POSTS_KEY, userID, limit, offset)
+func UserNotValidatedPostsKey(userID string, cursor string, limit int) string {
+	return fmt.Sprintf(USER_NOT_VALIDATED_POSTS_KEY, userID, cursor, limit)
 }
 
-func NotValidatedPostsKey(limit, offset int) string {
-	return fmt.Sprintf(NOT_VALIDATED_POSTS_KEY, limit, offset)
+func NotValidatedPostsKey(cursor string, limit int) string {
+	return fmt.Sprintf(NOT_VALIDATED_POSTS_KEY, cursor, limit)
 }
 
 func UserCacheKey(userID string) string {
 	return fmt.Sprintf(USER_CACHE_KEY, userID)
 }
 
-func PostCommentsKey(postID int64, limit, offset int) string {
-	return fmt.Sprintf(POST_COMMENTS_KEY, postID, limit, offset)
+func PostCommentsKey(postID int64, cursor string, limit int) string {
+	return fmt.Sprintf(POST_COMMENTS_KEY, postID, cursor, limit)
 }
 
-func CommentRepliesKey(postID int64, commentID int64, limit, offset int) string {
-	return fmt.Sprintf(COMMENT_REPLIES_KEY, postID, commentID, limit, offset)
+func PostCommentsKeyPattern(postID int64) string {
+	return fmt.Sprintf(POST_COMMENTS_KEY_PATTERN, postID)
 }
 
-func UserLikesKey(userID string, limit, offset int) string {
-	return fmt.Sprintf(USER_LIKES_KEY, userID, limit, offset)
+func PostCommentsTreeKey(postID, parentID int64, sort, cursor string, depth, breadth int) string {
+	return fmt.Sprintf(POST_COMMENTS_TREE_KEY, postID, parentID, sort, cursor, depth, breadth)
+}
+
+func PostCommentsTreeKeyPattern(postID int64) string {
+	return fmt.Sprintf(POST_COMMENTS_TREE_KEY_PATTERN, postID)
+}
+
+func CommentRepliesKey(postID int64, commentID int64, cursor string, limit int) string {
+	return fmt.Sprintf(COMMENT_REPLIES_KEY, postID, commentID, cursor, limit)
+}
+
+func PostCommentRepliesKeyPattern(postID int64) string {
+	return fmt.Sprintf(POST_COMMENT_REPLIES_KEY_PATTERN, postID)
+}
+
+func UserLikesKey(userID string, cursor string, limit int) string {
+	return fmt.Sprintf(USER_LIKES_KEY, userID, cursor, limit)
 }
 
 func IsLikedPostKey(userID string, postID int64) string {
@@ -77,6 +126,22 @@ func GetPostIDFromPostLikesKey(key string) (int64, error) {
 	return int64(postID), nil
 }
 
+func PostCommentsCountKey(postID int64) string {
+	return fmt.Sprintf(POST_COMMENTS_COUNT_KEY, postID)
+}
+
+func GetPostIDFromPostCommentsCountKey(key string) (int64, error) {
+	parts := strings.Split(key, ":")
+	if len(parts) < 2 {
+		return 0, fmt.Errorf("no part with post ID")
+	}
+	postID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, err
+	}
+	return int64(postID), nil
+}
+
 func CommentLikesKey(commentID int64) string {
 	return fmt.Sprintf(COMMENT_LIKES_KEY, commentID)
 }
@@ -97,10 +162,106 @@ func IsLikedCommentKey(userID string, commentID int64) string {
 	return fmt.Sprintf(IS_LIKED_COMMENT_KEY, userID, commentID)
 }
 
-func TrendingPostsKey(limit int) string {
-	return fmt.Sprintf(TRENDING_POSTS_KEY, limit)
+func TrendingPostsKey(window string) string {
+	return fmt.Sprintf(TRENDING_POSTS_KEY, window)
+}
+
+func TrendingTagPostsKey(window, tag string) string {
+	return fmt.Sprintf(TRENDING_TAG_POSTS_KEY, window, tag)
+}
+
+func TrendingTagPostsKeyPattern(window string) string {
+	return fmt.Sprintf(TRENDING_TAG_POSTS_KEY_PATTERN, window)
+}
+
+func TagsPostsKey(mode string, tags []string, cursor string, limit int) string {
+	return fmt.Sprintf(TAGS_POSTS_KEY, mode, strings.Join(tags, ","), cursor, limit)
+}
+
+func TagsKey(prefix string, limit, offset int) string {
+	return fmt.Sprintf(TAGS_KEY, prefix, limit, offset)
+}
+
+func TagsAutocompleteKey(prefix string) string {
+	return fmt.Sprintf(TAGS_AUTOCOMPLETE_KEY, prefix)
+}
+
+func SearchPostsResultKey(normalizedQuery string, cursor string, limit int) string {
+	return fmt.Sprintf(SEARCH_POSTS_RESULT_KEY, normalizedQuery, cursor, limit)
+}
+
+func DraftAutosaveKey(postID int64) string {
+	return fmt.Sprintf(DRAFT_AUTOSAVE_KEY, postID)
+}
+
+func GetPostIDFromDraftAutosaveKey(key string) (int64, error) {
+	parts := strings.Split(key, ":")
+	if len(parts) < 2 {
+		return 0, fmt.Errorf("no part with post ID")
+	}
+	postID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, err
+	}
+	return int64(postID), nil
+}
+
+func UserTimelineKey(userID string) string {
+	return fmt.Sprintf(USER_TIMELINE_KEY, userID)
+}
+
+func RelatedPostsKey(postID int64) string {
+	return fmt.Sprintf(RELATED_POSTS_KEY, postID)
+}
+
+func UserBookmarksKey(userID string, cursor string, limit int) string {
+	return fmt.Sprintf(USER_BOOKMARKS_KEY, userID, cursor, limit)
+}
+
+func UserBookmarksKeyPattern(userID string) string {
+	return fmt.Sprintf(USER_BOOKMARKS_KEY_PATTERN, userID)
+}
+
+func IsBookmarkedPostKey(userID string, postID int64) string {
+	return fmt.Sprintf(IS_BOOKMARKED_POST_KEY, userID, postID)
+}
+
+func UserBookmarkCollectionsKey(userID string, publicOnly bool) string {
+	return fmt.Sprintf(USER_BOOKMARK_COLLECTIONS_KEY, userID, publicOnly)
+}
+
+func BookmarkCollectionPostsKey(collectionID int64, cursor string, limit int) string {
+	return fmt.Sprintf(BOOKMARK_COLLECTION_POSTS_KEY, collectionID, cursor, limit)
+}
+
+func BookmarkCollectionPostsKeyPattern(collectionID int64) string {
+	return fmt.Sprintf(BOOKMARK_COLLECTION_POSTS_KEY_PATTERN, collectionID)
+}
+
+func ReactionsKey(target string, targetID int64) string {
+	return fmt.Sprintf(REACTIONS_KEY, target, targetID)
+}
+
+func ReactionsKeyPattern(target string) string {
+	return fmt.Sprintf(REACTIONS_KEY_PATTERN, target)
+}
+
+func GetTargetIDFromReactionsKey(key string) (int64, error) {
+	parts := strings.Split(key, ":")
+	if len(parts) < 2 {
+		return 0, fmt.Errorf("no part with target ID")
+	}
+	targetID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, err
+	}
+	return int64(targetID), nil
+}
+
+func UserReactionKey(userID string, target string, targetID int64) string {
+	return fmt.Sprintf(USER_REACTION_KEY, userID, target, targetID)
 }
 
-func SearchPostsResultByTitleKey(title string, limit, offset int) string {
-	return fmt.Sprintf(SEARCH_POSTS_RESULT_BY_TITLE_KEY, title, limit, offset)
+func ModerationClaimKey(postID int64) string {
+	return fmt.Sprintf(MODERATION_CLAIM_KEY, postID)
 }
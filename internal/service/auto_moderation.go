@@ -0,0 +1,432 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/google/uuid"
+	"github.com/spf13/viper"
+)
+
+const (
+	DEFAULT_NEW_ACCOUNT_PERIOD = time.Hour * 72
+	AUTO_APPROVED_STATUS_MSG = "approved automatically"
+)
+
+var REGEXP_TO_GET_LINKS = regexp.MustCompile(`https?://[^\s"'<>()\[\]]+`)
+
+// Post checked by auto-moderation rules
+type autoModerationInput struct {
+	text    string // title, feed view and content
+	links   []*url.URL
+	history *model.AuthorHistory
+}
+
+// Returns the flag raised by the post or nil, rules read their params from "auto-moderation.<rule>" config
+type autoModerationRule func(input autoModerationInput) *model.AutoModerationFlag
+
+// Rules run on every checked post, new rules are added here
+var autoModerationRules = []autoModerationRule{
+	checkBannedWords,
+	checkLinksCount,
+	checkBlockedDomains,
+	checkCaps,
+	checkRepetition,
+	checkNewAccount,
+}
+
+// Rule's flag score, "auto-moderation.<rule>.score" in config
+func ruleScore(rule string, def float64) float64 {
+	return floatParam("auto-moderation."+rule+".score", def)
+}
+
+// Banned words of the loaded config split into words, banned phrases have several
+type bannedWords struct {
+	config  string
+	phrases [][]string
+}
+
+var bannedWordsCache atomic.Pointer[bannedWords]
+
+// Splits the banned words once per config, "auto-moderation.banned-words.words" in config
+func loadBannedWords() [][]string {
+	words := viper.GetStringSlice("auto-moderation.banned-words.words")
+	config := strings.Join(words, "\n")
+	if cached := bannedWordsCache.Load(); cached != nil && cached.config == config {
+		return cached.phrases
+	}
+
+	phrases := [][]string{}
+	for _, word := range words {
+		if phrase := splitWords(word); len(phrase) != 0 {
+			phrases = append(phrases, phrase)
+		}
+	}
+
+	bannedWordsCache.Store(&bannedWords{config: config, phrases: phrases})
+	return phrases
+}
+
+// Lower case words of the text, words are runs of letters and digits in any script
+func splitWords(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Banned words are matched as whole words, so they aren't found inside longer words
+func checkBannedWords(input autoModerationInput) *model.AutoModerationFlag {
+	phrases := loadBannedWords()
+	if len(phrases) == 0 {
+		return nil
+	}
+
+	words := splitWords(input.text)
+	found := []string{}
+	for _, phrase := range phrases {
+		for i := 0; i+len(phrase) <= len(words); i++ {
+			if slices.Equal(words[i:i+len(phrase)], phrase) {
+				if match := strings.Join(phrase, " "); !slices.Contains(found, match) {
+					found = append(found, match)
+				}
+				break
+			}
+		}
+	}
+	if len(found) == 0 {
+		return nil
+	}
+
+	return &model.AutoModerationFlag{
+		Rule: "banned-words",
+		Score: ruleScore("banned-words", 0.5) * float64(len(found)),
+		Details: "banned words: " + strings.Join(found, ", "),
+	}
+}
+
+func checkLinksCount(input autoModerationInput) *model.AutoModerationFlag {
+	max := int(floatParam("auto-moderation.links.max", 10))
+	if len(input.links) <= max {
+		return nil
+	}
+
+	return &model.AutoModerationFlag{
+		Rule: "links",
+		Score: ruleScore("links", 0.3),
+		Details: fmt.Sprintf("%d links, at most %d allowed", len(input.links), max),
+	}
+}
+
+func checkBlockedDomains(input autoModerationInput) *model.AutoModerationFlag {
+	domains := viper.GetStringSlice("auto-moderation.blocked-domains.domains")
+	if len(domains) == 0 {
+		return nil
+	}
+
+	blocked := []string{}
+	for _, link := range input.links {
+		host := strings.ToLower(link.Hostname())
+		for _, domain := range domains {
+			domain = strings.ToLower(strings.TrimSpace(domain))
+			// Subdomains of blocked domains are blocked too
+			if domain != "" && (host == domain || strings.HasSuffix(host, "."+domain)) {
+				blocked = append(blocked, host)
+				break
+			}
+		}
+	}
+	if len(blocked) == 0 {
+		return nil
+	}
+
+	return &model.AutoModerationFlag{
+		Rule: "blocked-domains",
+		Score: ruleScore("blocked-domains", 1),
+		Details: "links to blocked domains: " + strings.Join(blocked, ", "),
+	}
+}
+
+// Flags posts mostly written in capital letters
+func checkCaps(input autoModerationInput) *model.AutoModerationFlag {
+	letters, upper := 0, 0
+	for _, r := range input.text {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+
+	if letters < int(floatParam("auto-moderation.caps.min-letters", 50)) {
+		return nil
+	}
+
+	ratio := float64(upper) / float64(letters)
+	maxRatio := floatParam("auto-moderation.caps.max-ratio", 0.7)
+	if ratio <= maxRatio {
+		return nil
+	}
+
+	return &model.AutoModerationFlag{
+		Rule: "caps",
+		Score: ruleScore("caps", 0.3),
+		Details: fmt.Sprintf("%.0f%% of letters are capital, at most %.0f%% allowed", ratio*100, maxRatio*100),
+	}
+}
+
+// Flags long runs of the same character and posts made of one word repeated
+func checkRepetition(input autoModerationInput) *model.AutoModerationFlag {
+	maxRun := int(floatParam("auto-moderation.repetition.max-char-run", 10))
+	run, longest := 0, 0
+	var prev rune
+	for _, r := range input.text {
+		if r == prev && !unicode.IsSpace(r) {
+			run++
+		} else {
+			run = 1
+		}
+		prev = r
+		longest = max(longest, run)
+	}
+	if longest > maxRun {
+		return &model.AutoModerationFlag{
+			Rule: "repetition",
+			Score: ruleScore("repetition", 0.4),
+			Details: fmt.Sprintf("character repeated %d times in a row", longest),
+		}
+	}
+
+	words := splitWords(input.text)
+	if len(words) < int(floatParam("auto-moderation.repetition.min-words", 20)) {
+		return nil
+	}
+
+	counts := make(map[string]int)
+	top, topCount := "", 0
+	for _, word := range words {
+		// Short words are repeated in any text
+		if len([]rune(word)) < 4 {
+			continue
+		}
+		counts[word]++
+		if counts[word] > topCount {
+			top, topCount = word, counts[word]
+		}
+	}
+
+	share := float64(topCount) / float64(len(words))
+	if share <= floatParam("auto-moderation.repetition.max-word-share", 0.3) {
+		return nil
+	}
+
+	return &model.AutoModerationFlag{
+		Rule: "repetition",
+		Score: ruleScore("repetition", 0.4),
+		Details: fmt.Sprintf("%q is %.0f%% of words", top, share*100),
+	}
+}
+
+// Authors whose first post is recent have lower limits
+func checkNewAccount(input autoModerationInput) *model.AutoModerationFlag {
+	period := viper.GetDuration("auto-moderation.new-accounts.period")
+	if period <= 0 {
+		period = DEFAULT_NEW_ACCOUNT_PERIOD
+	}
+	if input.history.FirstPostAt != nil && time.Since(*input.history.FirstPostAt) >= period {
+		return nil
+	}
+
+	exceeded := []string{}
+	if maxLinks := int(floatParam("auto-moderation.new-accounts.max-links", 2)); len(input.links) > maxLinks {
+		exceeded = append(exceeded, fmt.Sprintf("%d links, at most %d allowed", len(input.links), maxLinks))
+	}
+	if maxPosts := int64(floatParam("auto-moderation.new-accounts.max-posts-per-day", 3)); input.history.RecentPosts > maxPosts {
+		exceeded = append(exceeded, fmt.Sprintf("%d posts in a day, at most %d allowed", input.history.RecentPosts, maxPosts))
+	}
+	if len(exceeded) == 0 {
+		return nil
+	}
+
+	return &model.AutoModerationFlag{
+		Rule: "new-accounts",
+		Score: ruleScore("new-accounts", 0.4),
+		Details: "new account: " + strings.Join(exceeded, ", "),
+	}
+}
+
+// Trusted authors' posts without flags are approved automatically
+func isTrustedAuthor(history *model.AuthorHistory) bool {
+	minValidated := int64(floatParam("auto-moderation.trusted-authors.min-validated-posts", 10))
+	if minValidated <= 0 || history.ValidatedPosts < minValidated {
+		return false
+	}
+
+	rejectionRate := float64(history.Rejections) / float64(history.ValidatedPosts+history.Rejections)
+	return rejectionRate <= floatParam("auto-moderation.trusted-authors.max-rejection-rate", 0.05)
+}
+
+// Links to other sites, links to this service and its file storage are skipped
+func externalLinks(text string) []*url.URL {
+	internal := []string{}
+	for _, key := range []string{"app.url", "file-storage.origin"} {
+		if u, err := url.Parse(viper.GetString(key)); err == nil && u.Hostname() != "" {
+			internal = append(internal, strings.ToLower(u.Hostname()))
+		}
+	}
+
+	links := []*url.URL{}
+	for _, match := range REGEXP_TO_GET_LINKS.FindAllString(text, -1) {
+		u, err := url.Parse(match)
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+
+		isInternal := false
+		for _, host := range internal {
+			if strings.ToLower(u.Hostname()) == host {
+				isInternal = true
+				break
+			}
+		}
+		if !isInternal {
+			links = append(links, u)
+		}
+	}
+
+	return links
+}
+
+// Runs the rules on the post. Returns nil if auto-moderation is disabled ("auto-moderation.enabled") or failed,
+// the post is left for moderators then
+func (s *postService) checkAutoModeration(ctx context.Context, authorID uuid.UUID, title, feedView, content string) *model.AutoModerationResult {
+	if !viper.GetBool("auto-moderation.enabled") {
+		return nil
+	}
+
+	history, err := s.repo.Postgres.AutoModeration.FindAuthorHistory(ctx, authorID)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find user(%s)'s moderation history from postgres: %s", authorID.String(), err.Error())
+		return nil
+	}
+
+	text := strings.Join([]string{title, feedView, content}, "\n")
+	input := autoModerationInput{
+		text: text,
+		links: externalLinks(text),
+		history: history,
+	}
+
+	result := model.AutoModerationResult{
+		Decision: model.AUTO_MODERATION_REVIEW,
+		Flags: []model.AutoModerationFlag{},
+		CheckedAt: time.Now(),
+	}
+	details := []string{}
+	for _, rule := range autoModerationRules {
+		if flag := rule(input); flag != nil {
+			result.Flags = append(result.Flags, *flag)
+			result.RiskScore += flag.Score
+			details = append(details, flag.Details)
+		}
+	}
+	result.RiskScore = min(result.RiskScore, 1)
+
+	switch {
+	case len(result.Flags) > 0 && result.RiskScore >= floatParam("auto-moderation.reject-score", 1):
+		result.Decision = model.AUTO_MODERATION_REJECT
+		result.Reason = "rejected automatically: " + strings.Join(details, "; ")
+	case len(result.Flags) == 0 && isTrustedAuthor(history):
+		result.Decision = model.AUTO_MODERATION_APPROVE
+	}
+
+	return &result
+}
+
+// Checks the post and saves the result for moderators
+func (s *postService) autoModerate(ctx context.Context, postID int64, authorID uuid.UUID, title, feedView, content string) *model.AutoModerationResult {
+	result := s.checkAutoModeration(ctx, authorID, title, feedView, content)
+	if result == nil {
+		return nil
+	}
+
+	if err := s.repo.Postgres.AutoModeration.Save(ctx, postID, *result); err != nil {
+		s.logger.Sugar().Errorf("failed to save post(%d) auto-moderation result: %s", postID, err.Error())
+		return nil
+	}
+
+	return result
+}
+
+// Checks the pending edit and saves the result with the revision, the live post keeps its own result
+func (s *postService) autoModerateRevision(ctx context.Context, revisionID int64, authorID uuid.UUID, title, feedView, content string) *model.AutoModerationResult {
+	result := s.checkAutoModeration(ctx, authorID, title, feedView, content)
+	if result == nil {
+		return nil
+	}
+
+	if err := s.repo.Postgres.AutoModeration.SaveRevision(ctx, revisionID, *result); err != nil {
+		s.logger.Sugar().Errorf("failed to save revision(%d) auto-moderation result: %s", revisionID, err.Error())
+		return nil
+	}
+
+	return result
+}
+
+// Status message sent to the author with an automatic decision
+func autoModerationStatusMsg(result *model.AutoModerationResult) string {
+	if result.Decision == model.AUTO_MODERATION_APPROVE {
+		return AUTO_APPROVED_STATUS_MSG
+	}
+	return result.Reason
+}
+
+// Applies an automatic approval or rejection of the post, reviews are left for moderators.
+// Returns true if the post's validation status was updated
+func (s *postService) applyAutoModeration(ctx context.Context, postID int64, result *model.AutoModerationResult) bool {
+	if result == nil || result.Decision == model.AUTO_MODERATION_REVIEW {
+		return false
+	}
+
+	approved := result.Decision == model.AUTO_MODERATION_APPROVE
+	statusMsg := autoModerationStatusMsg(result)
+
+	// Counts as one approval, posts needing more are left for moderators
+	applied, err := s.UpdateValidationStatus(ctx, postID, model.AUTO_MODERATOR_ID, approved, statusMsg)
+	if err != nil {
+		if errors.Is(err, ErrPostAlreadyApprovedByModerator) {
+			return false
+		}
+
+		s.logger.Sugar().Errorf("failed to apply post(%d) auto-moderation decision(%s): %s", postID, result.Decision, err.Error())
+		return false
+	}
+
+	return applied
+}
+
+// Applies an automatic approval or rejection of the pending edit, returns true if it was applied
+func (s *postService) applyRevisionAutoModeration(ctx context.Context, revisionID int64, result *model.AutoModerationResult) bool {
+	if result == nil || result.Decision == model.AUTO_MODERATION_REVIEW {
+		return false
+	}
+
+	approved := result.Decision == model.AUTO_MODERATION_APPROVE
+	statusMsg := autoModerationStatusMsg(result)
+
+	if err := s.ReviewRevision(ctx, revisionID, model.AUTO_MODERATOR_ID, approved, statusMsg); err != nil {
+		s.logger.Sugar().Errorf("failed to apply revision(%d) auto-moderation decision(%s): %s", revisionID, result.Decision, err.Error())
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/spf13/viper"
+)
+
+// Sets config for the test and restores it after
+func setConfig(t *testing.T, key string, value any) {
+	t.Helper()
+	prev := viper.Get(key)
+	viper.Set(key, value)
+	t.Cleanup(func() {
+		viper.Set(key, prev)
+	})
+}
+
+func testLinks(t *testing.T, links ...string) []*url.URL {
+	t.Helper()
+	result := []*url.URL{}
+	for _, link := range links {
+		u, err := url.Parse(link)
+		if err != nil {
+			t.Fatalf("failed to parse %q: %s", link, err.Error())
+		}
+		result = append(result, u)
+	}
+	return result
+}
+
+func manyLinks(t *testing.T, n int) []*url.URL {
+	links := []string{}
+	for i := 0; i < n; i++ {
+		links = append(links, fmt.Sprintf("https://site%d.com", i))
+	}
+	return testLinks(t, links...)
+}
+
+// Checks that the rule raised a flag with the details or didn't raise one if details is empty
+func assertFlag(t *testing.T, flag *model.AutoModerationFlag, rule, details string) {
+	t.Helper()
+	if details == "" {
+		if flag != nil {
+			t.Fatalf("expected no flag, got %+v", *flag)
+		}
+		return
+	}
+
+	if flag == nil {
+		t.Fatalf("expected %q flag with %q, got none", rule, details)
+	}
+	if flag.Rule != rule {
+		t.Errorf("expected rule %q, got %q", rule, flag.Rule)
+	}
+	if !strings.Contains(flag.Details, details) {
+		t.Errorf("expected details to contain %q, got %q", details, flag.Details)
+	}
+}
+
+func TestCheckBannedWords(t *testing.T) {
+	setConfig(t, "auto-moderation.banned-words.words", []string{"spam", "Buy Now", "спам"})
+
+	tests := []struct {
+		name    string
+		text    string
+		details string
+		score   float64
+	}{
+		{name: "whole word", text: "this is SPAM!", details: "banned words: spam", score: 0.5},
+		{name: "inside longer word", text: "a spammer and antispam", details: ""},
+		{name: "phrase across punctuation", text: "buy, now!", details: "banned words: buy now", score: 0.5},
+		{name: "phrase with a word between", text: "buy it now", details: ""},
+		{name: "non-latin word", text: "это спам.", details: "banned words: спам", score: 0.5},
+		{name: "repeated word counted once", text: "spam spam spam", details: "banned words: spam", score: 0.5},
+		{name: "several words", text: "spam, buy now", details: "banned words: spam, buy now", score: 1},
+		{name: "clean text", text: "nothing to see here", details: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flag := checkBannedWords(autoModerationInput{text: tt.text})
+			assertFlag(t, flag, "banned-words", tt.details)
+			if flag != nil && flag.Score != tt.score {
+				t.Errorf("expected score %v, got %v", tt.score, flag.Score)
+			}
+		})
+	}
+}
+
+func TestCheckBannedWordsReloadsConfig(t *testing.T) {
+	setConfig(t, "auto-moderation.banned-words.words", []string{"spam"})
+	assertFlag(t, checkBannedWords(autoModerationInput{text: "spam"}), "banned-words", "spam")
+
+	viper.Set("auto-moderation.banned-words.words", []string{"scam"})
+	assertFlag(t, checkBannedWords(autoModerationInput{text: "spam"}), "banned-words", "")
+	assertFlag(t, checkBannedWords(autoModerationInput{text: "scam"}), "banned-words", "scam")
+}
+
+func TestCheckLinksCount(t *testing.T) {
+	tests := []struct {
+		name    string
+		links   int
+		details string
+	}{
+		{name: "no links", links: 0, details: ""},
+		{name: "at the limit", links: 10, details: ""},
+		{name: "over the limit", links: 11, details: "11 links, at most 10 allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFlag(t, checkLinksCount(autoModerationInput{links: manyLinks(t, tt.links)}), "links", tt.details)
+		})
+	}
+}
+
+func TestCheckBlockedDomains(t *testing.T) {
+	setConfig(t, "auto-moderation.blocked-domains.domains", []string{"Evil.com", " "})
+
+	tests := []struct {
+		name    string
+		links   []string
+		details string
+	}{
+		{name: "blocked domain", links: []string{"https://evil.com/page"}, details: "evil.com"},
+		{name: "case insensitive", links: []string{"https://EVIL.com"}, details: "evil.com"},
+		{name: "subdomain", links: []string{"http://www.evil.com"}, details: "www.evil.com"},
+		{name: "domain ending with blocked name", links: []string{"https://notevil.com"}, details: ""},
+		{name: "allowed domain", links: []string{"https://example.com/evil.com"}, details: ""},
+		{name: "no links", links: nil, details: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFlag(t, checkBlockedDomains(autoModerationInput{links: testLinks(t, tt.links...)}), "blocked-domains", tt.details)
+		})
+	}
+}
+
+func TestCheckCaps(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		details string
+	}{
+		{name: "too few letters", text: "HELLO WORLD!!!", details: ""},
+		{name: "all capital", text: strings.Repeat("LOUD ", 15), details: "100% of letters are capital"},
+		{name: "normal text", text: strings.Repeat("Normal sentence. ", 5), details: ""},
+		{name: "at the max ratio", text: strings.Repeat("ABCDEFGhij", 10), details: ""},
+		{name: "non-latin capital", text: strings.Repeat("ПРИВЕТ ", 10), details: "100% of letters are capital"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFlag(t, checkCaps(autoModerationInput{text: tt.text}), "caps", tt.details)
+		})
+	}
+}
+
+func TestCheckRepetition(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		details string
+	}{
+		{name: "character run at the limit", text: "hm" + strings.Repeat("!", 10), details: ""},
+		{name: "character run over the limit", text: "hm" + strings.Repeat("!", 11), details: "character repeated 11 times in a row"},
+		{name: "spaces aren't repeated characters", text: "a" + strings.Repeat(" ", 30) + "b", details: ""},
+		{name: "one word repeated", text: strings.Repeat("Money! ", 20), details: `"money" is 100% of words`},
+		{name: "too few words", text: strings.Repeat("money ", 19), details: ""},
+		{name: "short words aren't counted", text: strings.Repeat("it is ", 20), details: ""},
+		{
+			name: "varied text",
+			text: "the quick brown fox jumps over the lazy dog while seven wizards quietly judge boxing matches " +
+				"between purple monkeys and sleepy turtles near the river",
+			details: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertFlag(t, checkRepetition(autoModerationInput{text: tt.text}), "repetition", tt.details)
+		})
+	}
+}
+
+func TestCheckNewAccount(t *testing.T) {
+	recent := time.Now().Add(-time.Hour)
+	old := time.Now().Add(-DEFAULT_NEW_ACCOUNT_PERIOD - time.Hour)
+
+	tests := []struct {
+		name    string
+		links   int
+		history model.AuthorHistory
+		details string
+	}{
+		{name: "first post within limits", links: 2, history: model.AuthorHistory{}, details: ""},
+		{name: "first post with too many links", links: 3, history: model.AuthorHistory{}, details: "3 links, at most 2 allowed"},
+		{name: "new account posting too often", history: model.AuthorHistory{FirstPostAt: &recent, RecentPosts: 4}, details: "4 posts in a day, at most 3 allowed"},
+		{
+			name: "new account over both limits",
+			links: 5,
+			history: model.AuthorHistory{FirstPostAt: &recent, RecentPosts: 10},
+			details: "5 links, at most 2 allowed, 10 posts in a day",
+		},
+		{name: "old account", links: 5, history: model.AuthorHistory{FirstPostAt: &old, RecentPosts: 10}, details: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := tt.history
+			assertFlag(t, checkNewAccount(autoModerationInput{links: manyLinks(t, tt.links), history: &history}), "new-accounts", tt.details)
+		})
+	}
+}
+
+func TestIsTrustedAuthor(t *testing.T) {
+	tests := []struct {
+		name    string
+		history model.AuthorHistory
+		trusted bool
+	}{
+		{name: "no posts", history: model.AuthorHistory{}, trusted: false},
+		{name: "too few validated posts", history: model.AuthorHistory{ValidatedPosts: 9}, trusted: false},
+		{name: "enough validated posts", history: model.AuthorHistory{ValidatedPosts: 10}, trusted: true},
+		{name: "low rejection rate", history: model.AuthorHistory{ValidatedPosts: 100, Rejections: 5}, trusted: true},
+		{name: "high rejection rate", history: model.AuthorHistory{ValidatedPosts: 100, Rejections: 6}, trusted: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := tt.history
+			if trusted := isTrustedAuthor(&history); trusted != tt.trusted {
+				t.Errorf("expected %v, got %v", tt.trusted, trusted)
+			}
+		})
+	}
+}
+
+func TestIsTrustedAuthorDisabled(t *testing.T) {
+	setConfig(t, "auto-moderation.trusted-authors.min-validated-posts", 0)
+
+	if isTrustedAuthor(&model.AuthorHistory{ValidatedPosts: 1000}) {
+		t.Error("expected no trusted authors when min-validated-posts is 0")
+	}
+}
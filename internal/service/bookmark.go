@@ -0,0 +1,334 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/BloggingApp/post-service/internal/dto"
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/BloggingApp/post-service/internal/repository"
+	"github.com/BloggingApp/post-service/internal/repository/postgres"
+	"github.com/BloggingApp/post-service/internal/repository/redisrepo"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const BOOKMARKS_CACHE_TTL = time.Minute * 5
+
+type bookmarkService struct {
+	logger *zap.Logger
+	repo *repository.Repository
+	rdb *redis.Client
+}
+
+func newBookmarkService(logger *zap.Logger, repo *repository.Repository, rdb *redis.Client) Bookmark {
+	return &bookmarkService{
+		logger: logger,
+		repo: repo,
+		rdb: rdb,
+	}
+}
+
+// Set 'unbookmark' value to true if you want to remove a bookmark
+func (s *bookmarkService) Bookmark(ctx context.Context, postID int64, userID uuid.UUID, unbookmark bool) error {
+	var (
+		affected bool
+		err error
+	)
+	if unbookmark {
+		affected, err = s.repo.Postgres.Bookmark.Delete(ctx, postID, userID)
+	} else {
+		affected, err = s.repo.Postgres.Bookmark.Create(ctx, postID, userID)
+	}
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to set user(%s) bookmark for post(%d): %s", userID.String(), postID, err.Error())
+		return ErrInternal
+	}
+	if !affected {
+		return ErrFailedToBookmarkThePost
+	}
+
+	if err := s.rdb.Set(ctx, redisrepo.IsBookmarkedPostKey(userID.String(), postID), !unbookmark, time.Minute).Err(); err != nil {
+		s.logger.Sugar().Errorf("failed to set user(%s) is bookmarked for post(%d) in redis: %s", userID.String(), postID, err.Error())
+	}
+
+	patterns := []string{redisrepo.UserBookmarksKeyPattern(userID.String())}
+	if unbookmark {
+		// Post is removed from the user's collections too
+		collections, err := s.repo.Postgres.Bookmark.FindUserCollections(ctx, userID, false)
+		if err != nil {
+			s.logger.Sugar().Errorf("failed to find user(%s) bookmark collections: %s", userID.String(), err.Error())
+		}
+		for _, collection := range collections {
+			patterns = append(patterns, redisrepo.BookmarkCollectionPostsKeyPattern(collection.ID))
+		}
+		s.deleteUserCollectionsCache(ctx, userID)
+	}
+	s.deleteByPatterns(ctx, patterns...)
+
+	return nil
+}
+
+func (s *bookmarkService) IsBookmarked(ctx context.Context, postID int64, userID uuid.UUID) bool {
+	isBookmarkedCache, err := s.rdb.Get(ctx, redisrepo.IsBookmarkedPostKey(userID.String(), postID)).Bool()
+	if err == nil {
+		return isBookmarkedCache
+	}
+	if err != redis.Nil {
+		s.logger.Sugar().Errorf("failed to get if user(%s) bookmarked post(%d) from redis: %s", userID.String(), postID, err.Error())
+		return false
+	}
+
+	isBookmarked, err := s.repo.Postgres.Bookmark.IsBookmarked(ctx, postID, userID)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to get if user(%s) bookmarked post(%d) from postgres: %s", userID.String(), postID, err.Error())
+		return false
+	}
+
+	if err := s.rdb.Set(ctx, redisrepo.IsBookmarkedPostKey(userID.String(), postID), isBookmarked, time.Minute).Err(); err != nil {
+		s.logger.Sugar().Errorf("failed to set if user(%s) bookmarked post(%d) in redis: %s", userID.String(), postID, err.Error())
+	}
+
+	return isBookmarked
+}
+
+func (s *bookmarkService) FindUserBookmarks(ctx context.Context, userID uuid.UUID, cursor string, limit int) (*dto.Page[*model.FullPost], error) {
+	limit = pageSize(limit, PAGE_POSTS)
+	after, err := dto.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	cachedPage, err := redisrepo.Get[dto.Page[*model.FullPost]](s.rdb, ctx, redisrepo.UserBookmarksKey(userID.String(), cursor, limit))
+	if err == nil && cachedPage != nil {
+		return cachedPage, nil
+	}
+	if err != nil && err != redis.Nil {
+		s.logger.Sugar().Errorf("failed to get user(%s) bookmarks from redis: %s", userID.String(), err.Error())
+		return nil, ErrInternal
+	}
+
+	posts, next, err := s.repo.Postgres.Bookmark.FindUserBookmarks(ctx, userID, after, limit)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find user(%s) bookmarks from postgres: %s", userID.String(), err.Error())
+		return nil, ErrInternal
+	}
+	page := dto.NewPage(posts, next)
+
+	if err := redisrepo.SetJSON(s.rdb, ctx, redisrepo.UserBookmarksKey(userID.String(), cursor, limit), page, BOOKMARKS_CACHE_TTL); err != nil {
+		s.logger.Sugar().Errorf("failed to set user(%s) bookmarks in redis: %s", userID.String(), err.Error())
+		return nil, ErrInternal
+	}
+
+	return page, nil
+}
+
+func (s *bookmarkService) CreateCollection(ctx context.Context, userID uuid.UUID, req dto.CreateBookmarkCollectionRequest) (*model.BookmarkCollection, error) {
+	collection, err := s.repo.Postgres.Bookmark.CreateCollection(ctx, userID, req.Name, req.Public)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to create user(%s) bookmark collection: %s", userID.String(), err.Error())
+		return nil, ErrInternal
+	}
+
+	s.deleteUserCollectionsCache(ctx, userID)
+
+	return collection, nil
+}
+
+// Private collections are returned only if publicOnly is false
+func (s *bookmarkService) FindUserCollections(ctx context.Context, userID uuid.UUID, publicOnly bool) ([]*model.BookmarkCollection, error) {
+	cachedCollections, err := redisrepo.GetMany[model.BookmarkCollection](s.rdb, ctx, redisrepo.UserBookmarkCollectionsKey(userID.String(), publicOnly))
+	if err == nil {
+		return cachedCollections, nil
+	}
+	if err != redis.Nil {
+		s.logger.Sugar().Errorf("failed to get user(%s) bookmark collections from redis: %s", userID.String(), err.Error())
+		return nil, ErrInternal
+	}
+
+	collections, err := s.repo.Postgres.Bookmark.FindUserCollections(ctx, userID, publicOnly)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find user(%s) bookmark collections from postgres: %s", userID.String(), err.Error())
+		return nil, ErrInternal
+	}
+
+	if err := redisrepo.SetJSON(s.rdb, ctx, redisrepo.UserBookmarkCollectionsKey(userID.String(), publicOnly), collections, BOOKMARKS_CACHE_TTL); err != nil {
+		s.logger.Sugar().Errorf("failed to set user(%s) bookmark collections in redis: %s", userID.String(), err.Error())
+		return nil, ErrInternal
+	}
+
+	return collections, nil
+}
+
+// Private collections are visible only to their owners, viewerID is uuid.Nil for anonymous users
+func (s *bookmarkService) FindCollectionPosts(ctx context.Context, id int64, viewerID uuid.UUID, cursor string, limit int) (*dto.Page[*model.FullPost], error) {
+	limit = pageSize(limit, PAGE_POSTS)
+	after, err := dto.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := s.findViewableCollection(ctx, id, viewerID); err != nil {
+		return nil, err
+	}
+
+	cachedPage, err := redisrepo.Get[dto.Page[*model.FullPost]](s.rdb, ctx, redisrepo.BookmarkCollectionPostsKey(id, cursor, limit))
+	if err == nil && cachedPage != nil {
+		return cachedPage, nil
+	}
+	if err != nil && err != redis.Nil {
+		s.logger.Sugar().Errorf("failed to get bookmark collection(%d) posts from redis: %s", id, err.Error())
+		return nil, ErrInternal
+	}
+
+	posts, next, err := s.repo.Postgres.Bookmark.FindCollectionPosts(ctx, id, after, limit)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find bookmark collection(%d) posts from postgres: %s", id, err.Error())
+		return nil, ErrInternal
+	}
+	page := dto.NewPage(posts, next)
+
+	if err := redisrepo.SetJSON(s.rdb, ctx, redisrepo.BookmarkCollectionPostsKey(id, cursor, limit), page, BOOKMARKS_CACHE_TTL); err != nil {
+		s.logger.Sugar().Errorf("failed to set bookmark collection(%d) posts in redis: %s", id, err.Error())
+		return nil, ErrInternal
+	}
+
+	return page, nil
+}
+
+func (s *bookmarkService) FindCollection(ctx context.Context, id int64, viewerID uuid.UUID) (*model.BookmarkCollection, error) {
+	return s.findViewableCollection(ctx, id, viewerID)
+}
+
+func (s *bookmarkService) findViewableCollection(ctx context.Context, id int64, viewerID uuid.UUID) (*model.BookmarkCollection, error) {
+	collection, err := s.repo.Postgres.Bookmark.FindCollection(ctx, id)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrBookmarkCollectionNotFound
+		}
+
+		s.logger.Sugar().Errorf("failed to find bookmark collection(%d) from postgres: %s", id, err.Error())
+		return nil, ErrInternal
+	}
+
+	if !collection.Public && collection.UserID != viewerID {
+		return nil, ErrBookmarkCollectionNotFound
+	}
+
+	return collection, nil
+}
+
+func (s *bookmarkService) UpdateCollection(ctx context.Context, id int64, userID uuid.UUID, req dto.UpdateBookmarkCollectionRequest) error {
+	fields := make(map[string]any)
+	if req.Name != nil {
+		fields["name"] = *req.Name
+	}
+	if req.Public != nil {
+		fields["public"] = *req.Public
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+
+	if err := s.repo.Postgres.Bookmark.UpdateCollection(ctx, id, userID, fields); err != nil {
+		return s.collectionError(err, id, "update")
+	}
+
+	s.deleteUserCollectionsCache(ctx, userID)
+
+	return nil
+}
+
+func (s *bookmarkService) DeleteCollection(ctx context.Context, id int64, userID uuid.UUID) error {
+	if err := s.repo.Postgres.Bookmark.DeleteCollection(ctx, id, userID); err != nil {
+		return s.collectionError(err, id, "delete")
+	}
+
+	s.deleteUserCollectionsCache(ctx, userID)
+	s.deleteByPatterns(ctx, redisrepo.BookmarkCollectionPostsKeyPattern(id))
+
+	return nil
+}
+
+// Bookmarks the post if it isn't bookmarked yet
+func (s *bookmarkService) AddToCollection(ctx context.Context, id int64, userID uuid.UUID, postID int64) error {
+	if err := s.repo.Postgres.Bookmark.AddToCollection(ctx, id, userID, postID); err != nil {
+		if errors.Is(err, postgres.ErrPostIsNotVisible) {
+			return ErrPostNotFound
+		}
+		return s.collectionError(err, id, "add post to")
+	}
+
+	if err := s.rdb.Set(ctx, redisrepo.IsBookmarkedPostKey(userID.String(), postID), true, time.Minute).Err(); err != nil {
+		s.logger.Sugar().Errorf("failed to set user(%s) is bookmarked for post(%d) in redis: %s", userID.String(), postID, err.Error())
+	}
+
+	s.deleteUserCollectionsCache(ctx, userID)
+	s.deleteByPatterns(ctx, redisrepo.BookmarkCollectionPostsKeyPattern(id), redisrepo.UserBookmarksKeyPattern(userID.String()))
+
+	return nil
+}
+
+// Post stays bookmarked
+func (s *bookmarkService) RemoveFromCollection(ctx context.Context, id int64, userID uuid.UUID, postID int64) error {
+	if err := s.repo.Postgres.Bookmark.RemoveFromCollection(ctx, id, userID, postID); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrPostNotFound
+		}
+		return s.collectionError(err, id, "remove post from")
+	}
+
+	s.deleteUserCollectionsCache(ctx, userID)
+	s.deleteByPatterns(ctx, redisrepo.BookmarkCollectionPostsKeyPattern(id))
+
+	return nil
+}
+
+func (s *bookmarkService) ReorderCollection(ctx context.Context, id int64, userID uuid.UUID, postIDs []int64) error {
+	if err := s.repo.Postgres.Bookmark.ReorderCollection(ctx, id, userID, postIDs); err != nil {
+		return s.collectionError(err, id, "reorder")
+	}
+
+	s.deleteUserCollectionsCache(ctx, userID)
+	s.deleteByPatterns(ctx, redisrepo.BookmarkCollectionPostsKeyPattern(id))
+
+	return nil
+}
+
+func (s *bookmarkService) collectionError(err error, id int64, action string) error {
+	if err == pgx.ErrNoRows {
+		return ErrBookmarkCollectionNotFound
+	}
+
+	s.logger.Sugar().Errorf("failed to %s bookmark collection(%d): %s", action, id, err.Error())
+	return ErrInternal
+}
+
+func (s *bookmarkService) deleteUserCollectionsCache(ctx context.Context, userID uuid.UUID) {
+	if err := s.rdb.Del(ctx, redisrepo.UserBookmarkCollectionsKey(userID.String(), false), redisrepo.UserBookmarkCollectionsKey(userID.String(), true)).Err(); err != nil {
+		s.logger.Sugar().Errorf("failed to delete user(%s) bookmark collections from redis: %s", userID.String(), err.Error())
+	}
+}
+
+func (s *bookmarkService) deleteByPatterns(ctx context.Context, patterns ...string) {
+	var keys []string
+	for _, pattern := range patterns {
+		patternKeys, err := s.rdb.Keys(ctx, pattern).Result()
+		if err != nil && err != redis.Nil {
+			s.logger.Sugar().Errorf("failed to get keys with pattern(%s) from redis: %s", pattern, err.Error())
+			continue
+		}
+		keys = append(keys, patternKeys...)
+	}
+
+	if len(keys) == 0 {
+		return
+	}
+
+	if err := s.rdb.Del(ctx, keys...).Err(); err != nil {
+		s.logger.Sugar().Errorf("failed to delete bookmark caches from redis: %s", err.Error())
+	}
+}
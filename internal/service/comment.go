@@ -2,16 +2,22 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/BloggingApp/post-service/internal/dto"
 	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/BloggingApp/post-service/internal/rabbitmq"
 	"github.com/BloggingApp/post-service/internal/repository"
+	"github.com/BloggingApp/post-service/internal/repository/postgres"
 	"github.com/BloggingApp/post-service/internal/repository/redisrepo"
 	"github.com/go-co-op/gocron/v2"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
 
@@ -19,10 +25,11 @@ type commentService struct {
 	logger *zap.Logger
 	repo *repository.Repository
 	rdb *redis.Client
+	rabbitmq *rabbitmq.MQConn
 	scheduler gocron.Scheduler
 }
 
-func newCommentService(logger *zap.Logger, repo *repository.Repository, rdb *redis.Client) Comment {
+func newCommentService(logger *zap.Logger, repo *repository.Repository, rdb *redis.Client, rabbitmq *rabbitmq.MQConn) Comment {
 	scheduler, err := gocron.NewScheduler()
 	if err != nil {
 		panic(err)
@@ -32,6 +39,7 @@ func newCommentService(logger *zap.Logger, repo *repository.Repository, rdb *red
 		logger: logger,
 		repo: repo,
 		rdb: rdb,
+		rabbitmq: rabbitmq,
 		scheduler: scheduler,
 	}
 }
@@ -50,70 +58,235 @@ func (s *commentService) Create(ctx context.Context, authorID uuid.UUID, dto dto
 		return nil, ErrInternal
 	}
 
+	s.updatePostCachedComments(ctx, createdComment.PostID, 1)
+
+	s.publishCommentNotifications(ctx, createdComment)
+	if mentions := s.saveCommentMentions(ctx, createdComment); mentions != nil {
+		createdComment.Mentions = mentions
+	}
+
 	return createdComment, nil
 }
 
-func (s *commentService) FindPostComments(ctx context.Context, postID int64, limit int, offset int) ([]*model.FullComment, error) {
-	maxLimit(&limit)
+// Notifies the post author about the comment and the parent comment author about the reply.
+// Users aren't notified about their own comments, the post author gets one message if they're both
+func (s *commentService) publishCommentNotifications(ctx context.Context, comment *model.Comment) {
+	post, err := s.repo.Postgres.Post.FindAnyByID(ctx, comment.PostID)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find post(%d) to notify about comment(%d): %s", comment.PostID, comment.ID, err.Error())
+		return
+	}
 
-	commentsCache, err := redisrepo.GetMany[model.FullComment](s.rdb, ctx, redisrepo.PostCommentsKey(postID, limit, offset))
-	if err == nil {
-		return commentsCache, nil
+	notifyPostAuthor := post.AuthorID != comment.AuthorID
+	if comment.ParentID != nil {
+		parentAuthorID, err := s.repo.Postgres.Comment.FindAuthorID(ctx, *comment.ParentID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			s.logger.Sugar().Errorf("failed to find comment(%d) author to notify about reply(%d): %s", *comment.ParentID, comment.ID, err.Error())
+		}
+		if err == nil && parentAuthorID != comment.AuthorID {
+			s.publish(rabbitmq.COMMENT_REPLIED_QUEUE, dto.MQCommentRepliedMsg{
+				CommentID: comment.ID,
+				ParentID: *comment.ParentID,
+				PostID: post.ID,
+				PostTitle: post.Title,
+				UserID: parentAuthorID,
+				CommenterID: comment.AuthorID,
+				Content: comment.Content,
+				CreatedAt: comment.CreatedAt,
+			})
+			notifyPostAuthor = notifyPostAuthor && parentAuthorID != post.AuthorID
+		}
 	}
-	if err != redis.Nil {
+
+	if notifyPostAuthor {
+		s.publish(rabbitmq.COMMENT_CREATED_QUEUE, dto.MQCommentCreatedMsg{
+			CommentID: comment.ID,
+			PostID: post.ID,
+			PostTitle: post.Title,
+			UserID: post.AuthorID,
+			CommenterID: comment.AuthorID,
+			Content: comment.Content,
+			CreatedAt: comment.CreatedAt,
+		})
+	}
+}
+
+// Saves mentions of the comment's content and notifies users mentioned in it for the first time
+func (s *commentService) saveCommentMentions(ctx context.Context, comment *model.Comment) []model.Mention {
+	return saveMentions(ctx, s.repo, s.rabbitmq, s.logger, mentionSource{
+		target: model.MENTION_TARGET_COMMENT,
+		targetID: comment.ID,
+		postID: comment.PostID,
+		authorID: comment.AuthorID,
+		content: comment.Content,
+		notify: true,
+	})
+}
+
+// Failed notifications are logged, the comment is already saved
+func (s *commentService) publish(queue string, msg any) {
+	msgJSON, err := json.Marshal(msg)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to marshal rabbitmq msg for queue(%s) to json: %s", queue, err.Error())
+		return
+	}
+	if err := s.rabbitmq.PublishToQueue(queue, msgJSON); err != nil {
+		s.logger.Sugar().Errorf("failed to publish rabbitmq msg to queue(%s): %s", queue, err.Error())
+	}
+}
+
+func (s *commentService) FindPostComments(ctx context.Context, postID int64, cursor string, limit int) (*dto.Page[*model.FullComment], error) {
+	limit = pageSize(limit, PAGE_COMMENTS)
+	after, err := dto.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	cachedPage, err := redisrepo.Get[dto.Page[*model.FullComment]](s.rdb, ctx, redisrepo.PostCommentsKey(postID, cursor, limit))
+	if err == nil && cachedPage != nil {
+		return cachedPage, nil
+	}
+	if err != nil && err != redis.Nil {
 		s.logger.Sugar().Errorf("failed to get post(%d) comments from redis: %s", postID, err.Error())
 		return nil, ErrInternal
 	}
 
-	comments, err := s.repo.Postgres.Comment.FindPostComments(ctx, postID, limit, offset)
+	comments, next, err := s.repo.Postgres.Comment.FindPostComments(ctx, postID, after, limit)
 	if err != nil {
 		s.logger.Sugar().Errorf("failed to get post(%d) comments from postgres: %s", postID, err.Error())
 		return nil, ErrInternal
 	}
+	page := dto.NewPage(comments, next)
 
-	if err := redisrepo.SetJSON(s.rdb, ctx, redisrepo.PostCommentsKey(postID, limit, offset), comments, time.Minute); err != nil {
+	if err := redisrepo.SetJSON(s.rdb, ctx, redisrepo.PostCommentsKey(postID, cursor, limit), page, time.Minute); err != nil {
 		s.logger.Sugar().Errorf("failed to set post(%d) comments in redis: %s", postID, err.Error())
 		return nil, ErrInternal
 	}
 
-	return comments, nil
+	return page, nil
 }
 
-func (s *commentService) FindCommentReplies(ctx context.Context, postID int64, commentID int64, limit int, offset int) ([]*model.FullComment, error) {
-	maxLimit(&limit)
+func (s *commentService) FindCommentReplies(ctx context.Context, postID int64, commentID int64, cursor string, limit int) (*dto.Page[*model.FullComment], error) {
+	limit = pageSize(limit, PAGE_COMMENTS)
+	after, err := dto.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
 
-	repliesCache, err := redisrepo.GetMany[model.FullComment](s.rdb, ctx, redisrepo.CommentRepliesKey(postID, commentID, limit, offset))
-	if err == nil {
-		return repliesCache, nil
+	cachedPage, err := redisrepo.Get[dto.Page[*model.FullComment]](s.rdb, ctx, redisrepo.CommentRepliesKey(postID, commentID, cursor, limit))
+	if err == nil && cachedPage != nil {
+		return cachedPage, nil
 	}
-	if err != redis.Nil {
+	if err != nil && err != redis.Nil {
 		s.logger.Sugar().Errorf("failed to get comment(%d) replies from redis: %s", commentID, err.Error())
 		return nil, ErrInternal
 	}
 
-	replies, err := s.repo.Postgres.Comment.FindCommentReplies(ctx, postID, commentID, limit, offset)
+	replies, next, err := s.repo.Postgres.Comment.FindCommentReplies(ctx, postID, commentID, after, limit)
 	if err != nil {
 		s.logger.Sugar().Errorf("failed to get comment(%d) replies from postgres: %s", commentID, err.Error())
 		return nil, ErrInternal
 	}
+	page := dto.NewPage(replies, next)
 
-	if err := redisrepo.SetJSON(s.rdb, ctx, redisrepo.CommentRepliesKey(postID, commentID, limit, offset), replies, time.Minute); err != nil {
+	if err := redisrepo.SetJSON(s.rdb, ctx, redisrepo.CommentRepliesKey(postID, commentID, cursor, limit), page, time.Minute); err != nil {
 		s.logger.Sugar().Errorf("failed to set comment(%d) replies in redis: %s", commentID, err.Error())
 		return nil, ErrInternal
 	}
 
-	return replies, nil
+	return page, nil
 }
 
-func (s *commentService) Delete(ctx context.Context, postID int64, commentID int64, authorID uuid.UUID) error {
-	if err := s.repo.Postgres.Comment.Delete(ctx, postID, commentID, authorID); err != nil {
+// Comment can be deleted by its author, the post author or a moderator (asModerator)
+func (s *commentService) Delete(ctx context.Context, postID int64, commentID int64, userID uuid.UUID, asModerator bool, reason string) error {
+	if err := s.repo.Postgres.Comment.Delete(ctx, postID, commentID, userID, asModerator, reason); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrCommentNotFound
+		}
+		if errors.Is(err, postgres.ErrNotAllowedToDeleteComment) {
+			return ErrNotAllowedToDeleteComment
+		}
 		s.logger.Sugar().Errorf("failed to delete post(%d) comment(%d): %s", postID, commentID, err.Error())
 		return ErrInternal
 	}
 
+	s.updatePostCachedComments(ctx, postID, -1)
+
+	s.deletePostCommentsCache(ctx, postID)
+
 	return nil
 }
 
+// Comments can be edited within "comments.edit-window" after creation, zero means forever
+func (s *commentService) Edit(ctx context.Context, postID, commentID int64, authorID uuid.UUID, req dto.EditCommentRequest) (*model.Comment, error) {
+	var editableSince time.Time
+	if window := viper.GetDuration("comments.edit-window"); window > 0 {
+		editableSince = time.Now().Add(-window)
+	}
+
+	comment, err := s.repo.Postgres.Comment.Edit(ctx, postID, commentID, authorID, req.Content, editableSince)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCommentNotFound
+		}
+		if errors.Is(err, postgres.ErrCommentEditWindowExpired) {
+			return nil, ErrCommentEditWindowExpired
+		}
+		s.logger.Sugar().Errorf("failed to edit post(%d) comment(%d): %s", postID, commentID, err.Error())
+		return nil, ErrInternal
+	}
+
+	if mentions := s.saveCommentMentions(ctx, comment); mentions != nil {
+		comment.Mentions = mentions
+	}
+
+	s.deletePostCommentsCache(ctx, postID)
+
+	return comment, nil
+}
+
+// Edit history is visible to the comment author and moderators (asModerator)
+func (s *commentService) FindRevisions(ctx context.Context, postID, commentID int64, userID uuid.UUID, asModerator bool) ([]*model.CommentRevision, error) {
+	revisions, err := s.repo.Postgres.Comment.FindRevisions(ctx, postID, commentID, userID, asModerator)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrCommentNotFound
+		}
+		if errors.Is(err, postgres.ErrNotAllowedToViewCommentRevisions) {
+			return nil, ErrNotAllowedToViewCommentRevisions
+		}
+		s.logger.Sugar().Errorf("failed to find post(%d) comment(%d) revisions: %s", postID, commentID, err.Error())
+		return nil, ErrInternal
+	}
+
+	return revisions, nil
+}
+
+// Deletes cached comments and replies pages of the post
+func (s *commentService) deletePostCommentsCache(ctx context.Context, postID int64) {
+	var keys []string
+	for _, pattern := range []string{
+		redisrepo.PostCommentsKeyPattern(postID),
+		redisrepo.PostCommentRepliesKeyPattern(postID),
+		redisrepo.PostCommentsTreeKeyPattern(postID),
+	} {
+		patternKeys, err := s.rdb.Keys(ctx, pattern).Result()
+		if err != nil && err != redis.Nil {
+			s.logger.Sugar().Errorf("failed to get keys with pattern(%s) from redis: %s", pattern, err.Error())
+			continue
+		}
+		keys = append(keys, patternKeys...)
+	}
+
+	if len(keys) == 0 {
+		return
+	}
+
+	if err := s.rdb.Del(ctx, keys...).Err(); err != nil {
+		s.logger.Sugar().Errorf("failed to delete post(%d) comments from redis: %s", postID, err.Error())
+	}
+}
+
 // Set 'unlike' value to true if you want to UNLIKE a comment
 func (s *commentService) Like(ctx context.Context, commentID int64, userID uuid.UUID, unlike bool) error {
 	var affected bool
@@ -174,6 +347,81 @@ func (s *commentService) updateCommentCachedLikes(ctx context.Context, commentID
 	return nil
 }
 
+// Comment is already saved, so a lost delta is only logged. Counts are recounted from comments on startup
+func (s *commentService) updatePostCachedComments(ctx context.Context, postID int64, delta int64) {
+	key := redisrepo.PostCommentsCountKey(postID)
+
+	if err := s.rdb.IncrBy(ctx, key, delta).Err(); err != nil {
+		s.logger.Sugar().Errorf("failed to increment key(%s) in redis: %s", key, err.Error())
+	}
+}
+
+// Trending reads comments counts of posts, which were zero for posts commented before they were counted
+// and drift when a count change is lost in redis. Must run before the flush job is scheduled, pending
+// changes flushed during the recount would be subtracted twice
+func (s *commentService) recountPostsComments(ctx context.Context) error {
+	keys, err := s.rdb.Keys(ctx, redisrepo.POST_COMMENTS_COUNT_KEY_PATTERN).Result()
+	if err != nil && err != redis.Nil {
+		return fmt.Errorf("failed to get keys with pattern(%s) from redis: %s", redisrepo.POST_COMMENTS_COUNT_KEY_PATTERN, err.Error())
+	}
+
+	// Changes that aren't flushed to postgres yet are already counted in comments
+	pending := make(map[int64]int64, len(keys))
+	for _, key := range keys {
+		postID, err := redisrepo.GetPostIDFromPostCommentsCountKey(key)
+		if err != nil {
+			continue
+		}
+
+		delta, err := s.rdb.Get(ctx, key).Int64()
+		if err != nil && err != redis.Nil {
+			return fmt.Errorf("failed to get post(%d) cached comments count from redis: %s", postID, err.Error())
+		}
+		pending[postID] = delta
+	}
+
+	if _, err := s.repo.Postgres.Post.RecountComments(ctx, pending); err != nil {
+		return fmt.Errorf("failed to recount posts comments: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (s *commentService) postsBatchCommentsCountUpdate(ctx context.Context) error {
+	postKeys, err := s.rdb.Keys(ctx, redisrepo.POST_COMMENTS_COUNT_KEY_PATTERN).Result()
+	if err != nil && err != redis.Nil {
+		return fmt.Errorf("failed to get keys with pattern(%s) from redis: %s", redisrepo.POST_COMMENTS_COUNT_KEY_PATTERN, err.Error())
+	}
+	if err == redis.Nil || len(postKeys) == 0 {
+		return nil
+	}
+
+	for _, postKey := range postKeys {
+		postID, err := redisrepo.GetPostIDFromPostCommentsCountKey(postKey)
+		if err != nil {
+			continue
+		}
+
+		// Taken atomically, so comments created during the update are counted next time
+		n, err := s.rdb.GetDel(ctx, postKey).Int64()
+		if err != nil && err != redis.Nil {
+			return fmt.Errorf("failed to get post(%d) cached comments count from redis: %s", postID, err.Error())
+		}
+		if err == redis.Nil || n == 0 {
+			continue
+		}
+
+		if err := s.repo.Postgres.Post.IncrPostCommentsBy(ctx, postID, n); err != nil {
+			if err := s.rdb.IncrBy(ctx, postKey, n).Err(); err != nil {
+				s.logger.Sugar().Errorf("failed to restore post(%d) cached comments count in redis: %s", postID, err.Error())
+			}
+			return fmt.Errorf("failed to incr post(%d) comments by(%d): %s", postID, n, err.Error())
+		}
+	}
+
+	return nil
+}
+
 func (s *commentService) commentsBatchLikesUpdate(ctx context.Context) error {
 	commentKeys, err := s.rdb.Keys(ctx, redisrepo.COMMENT_LIKES_KEY_PATTERN).Result()
 	if err != nil && err != redis.Nil {
@@ -219,8 +467,24 @@ func (s *commentService) ScheduleCommentLikesUpdates() {
 	}))
 }
 
+// Comments counts are recounted once on startup, then pending changes are flushed periodically
+func (s *commentService) SchedulePostCommentsCountUpdates() {
+	s.scheduler.NewJob(gocron.OneTimeJob(gocron.OneTimeJobStartImmediately()), gocron.NewTask(func(ctx context.Context) {
+		if err := s.recountPostsComments(ctx); err != nil {
+			s.logger.Sugar().Error(err.Error())
+		}
+
+		s.scheduler.NewJob(gocron.DurationJob(POST_COMMENTS_COUNT_UPDATE_TIMEOUT), gocron.NewTask(func(ctx context.Context) {
+			if err := s.postsBatchCommentsCountUpdate(ctx); err != nil {
+				s.logger.Sugar().Error(err.Error())
+			}
+		}))
+	}))
+}
+
 func (s *commentService) StartScheduledJobs() {
 	s.ScheduleCommentLikesUpdates()
+	s.SchedulePostCommentsCountUpdates()
 
 	s.scheduler.Start()
 }
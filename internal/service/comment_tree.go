@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/BloggingApp/post-service/internal/dto"
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/BloggingApp/post-service/internal/repository/redisrepo"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+)
+
+// Max tree size is set in "comments.tree" config
+const (
+	COMMENTS_TREE_DEFAULT_MAX_DEPTH = 5
+	COMMENTS_TREE_DEFAULT_MAX_BREADTH = 10
+)
+
+// Returns value capped by the config, zero value means the max
+func treeParam(value int, key string, def int) int {
+	max := viper.GetInt(key)
+	if max <= 0 {
+		max = def
+	}
+	if value <= 0 || value > max {
+		return max
+	}
+	return value
+}
+
+// Returns the post's comments tree, or the subtree of parentID's replies if it isn't nil.
+// Every comment has up to breadth replies and the tree has up to depth levels
+func (s *commentService) FindTree(ctx context.Context, postID int64, parentID *int64, sort string, cursor string, depth, breadth int) (*dto.CommentTree, error) {
+	if sort == "" {
+		sort = model.COMMENTS_SORT_TOP
+	}
+	switch sort {
+	case model.COMMENTS_SORT_TOP, model.COMMENTS_SORT_NEWEST, model.COMMENTS_SORT_OLDEST, model.COMMENTS_SORT_CONTROVERSIAL:
+	default:
+		return nil, ErrInvalidCommentsSort
+	}
+
+	after, err := dto.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	depth = treeParam(depth, "comments.tree.max-depth", COMMENTS_TREE_DEFAULT_MAX_DEPTH)
+	breadth = treeParam(breadth, "comments.tree.max-breadth", COMMENTS_TREE_DEFAULT_MAX_BREADTH)
+
+	var parent int64
+	if parentID != nil {
+		parent = *parentID
+	}
+	key := redisrepo.PostCommentsTreeKey(postID, parent, sort, cursor, depth, breadth)
+
+	cachedTree, err := redisrepo.Get[dto.CommentTree](s.rdb, ctx, key)
+	if err == nil && cachedTree != nil {
+		return cachedTree, nil
+	}
+	if err != nil && err != redis.Nil {
+		s.logger.Sugar().Errorf("failed to get post(%d) comments tree from redis: %s", postID, err.Error())
+		return nil, ErrInternal
+	}
+
+	entries, err := s.repo.Postgres.Comment.FindTree(ctx, postID, parentID, sort, after, depth, breadth)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find post(%d) comments tree from postgres: %s", postID, err.Error())
+		return nil, ErrInternal
+	}
+	tree := buildCommentTree(entries, breadth)
+
+	if err := redisrepo.SetJSON(s.rdb, ctx, key, tree, time.Minute); err != nil {
+		s.logger.Sugar().Errorf("failed to set post(%d) comments tree in redis: %s", postID, err.Error())
+		return nil, ErrInternal
+	}
+
+	return tree, nil
+}
+
+// Entries must be ordered by depth and position. Entries after breadth siblings aren't added,
+// they mean there are more siblings, which are loaded with the cursor of the last added one
+func buildCommentTree(entries []*model.CommentTreeEntry, breadth int) *dto.CommentTree {
+	tree := &dto.CommentTree{Comments: []*dto.CommentNode{}}
+	nodes := make(map[int64]*dto.CommentNode, len(entries))
+	cursors := make(map[int64]model.Cursor, len(entries))
+
+	var lastTopCursor model.Cursor
+	for _, entry := range entries {
+		var parent *dto.CommentNode
+		if entry.Depth > 1 {
+			parent = nodes[*entry.Comment.Comment.ParentID]
+			if parent == nil {
+				continue
+			}
+		}
+
+		if entry.Position > int64(breadth) {
+			if parent == nil {
+				tree.NextCursor = dto.EncodeCursor(&lastTopCursor)
+			} else {
+				cursor := cursors[parent.Comment.ID]
+				parent.RepliesCursor = dto.EncodeCursor(&cursor)
+			}
+			continue
+		}
+
+		node := &dto.CommentNode{
+			FullComment: entry.Comment,
+			RepliesCount: entry.RepliesCount,
+			Replies: []*dto.CommentNode{},
+		}
+		nodes[node.Comment.ID] = node
+
+		if parent == nil {
+			tree.Comments = append(tree.Comments, node)
+			lastTopCursor = entry.Cursor
+		} else {
+			parent.Replies = append(parent.Replies, node)
+			cursors[parent.Comment.ID] = entry.Cursor
+		}
+	}
+
+	return tree
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"strings"
+
+	"github.com/BloggingApp/post-service/internal/dto"
+)
+
+const (
+	DIFF_OP_EQUAL = "="
+	DIFF_OP_INSERT = "+"
+	DIFF_OP_DELETE = "-"
+)
+
+// Max size of the LCS table, bigger changes are diffed as the whole changed block replaced
+const MAX_DIFF_CELLS = 1_000_000
+
+// Line-level diff based on the longest common subsequence of lines
+func diffLines(from, to string) []dto.DiffLine {
+	a := strings.Split(from, "\n")
+	b := strings.Split(to, "\n")
+
+	diff := []dto.DiffLine{}
+
+	// Common prefix and suffix don't need the table
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		diff = append(diff, dto.DiffLine{Op: DIFF_OP_EQUAL, Text: a[prefix]})
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	diff = append(diff, diffChangedLines(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+
+	for _, line := range a[len(a)-suffix:] {
+		diff = append(diff, dto.DiffLine{Op: DIFF_OP_EQUAL, Text: line})
+	}
+
+	return diff
+}
+
+func diffChangedLines(a, b []string) []dto.DiffLine {
+	diff := []dto.DiffLine{}
+
+	if (len(a)+1)*(len(b)+1) > MAX_DIFF_CELLS {
+		for _, line := range a {
+			diff = append(diff, dto.DiffLine{Op: DIFF_OP_DELETE, Text: line})
+		}
+		for _, line := range b {
+			diff = append(diff, dto.DiffLine{Op: DIFF_OP_INSERT, Text: line})
+		}
+		return diff
+	}
+
+	// lcs[i][j] is the LCS length of a[i:] and b[j:]
+	lcs := make([][]int32, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = append(diff, dto.DiffLine{Op: DIFF_OP_EQUAL, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, dto.DiffLine{Op: DIFF_OP_DELETE, Text: a[i]})
+			i++
+		default:
+			diff = append(diff, dto.DiffLine{Op: DIFF_OP_INSERT, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, dto.DiffLine{Op: DIFF_OP_DELETE, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, dto.DiffLine{Op: DIFF_OP_INSERT, Text: b[j]})
+	}
+
+	return diff
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/BloggingApp/post-service/internal/dto"
+)
+
+func TestDiffLines(t *testing.T) {
+	eq := func(text string) dto.DiffLine { return dto.DiffLine{Op: DIFF_OP_EQUAL, Text: text} }
+	ins := func(text string) dto.DiffLine { return dto.DiffLine{Op: DIFF_OP_INSERT, Text: text} }
+	del := func(text string) dto.DiffLine { return dto.DiffLine{Op: DIFF_OP_DELETE, Text: text} }
+
+	tests := []struct {
+		name     string
+		from     string
+		to       string
+		expected []dto.DiffLine
+	}{
+		{name: "equal", from: "a\nb", to: "a\nb", expected: []dto.DiffLine{eq("a"), eq("b")}},
+		{name: "line appended", from: "a\nb", to: "a\nb\nc", expected: []dto.DiffLine{eq("a"), eq("b"), ins("c")}},
+		{name: "line removed", from: "a\nb\nc", to: "a\nc", expected: []dto.DiffLine{eq("a"), del("b"), eq("c")}},
+		{name: "line replaced", from: "a\nb\nc", to: "a\nx\nc", expected: []dto.DiffLine{eq("a"), del("b"), ins("x"), eq("c")}},
+		{
+			name: "lines moved",
+			from: "x\na\nb",
+			to: "a\nb\ny",
+			expected: []dto.DiffLine{del("x"), eq("a"), eq("b"), ins("y")},
+		},
+		{name: "from empty", from: "", to: "a", expected: []dto.DiffLine{del(""), ins("a")}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if diff := diffLines(tt.from, tt.to); !slices.Equal(diff, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, diff)
+			}
+		})
+	}
+}
+
+func TestDiffChangedLinesOverLimit(t *testing.T) {
+	a := make([]string, 1000)
+	b := make([]string, 1000)
+	for i := range a {
+		a[i] = "a"
+		b[i] = "b"
+	}
+
+	diff := diffChangedLines(a, b)
+	if len(diff) != 2000 || diff[0].Op != DIFF_OP_DELETE || diff[1999].Op != DIFF_OP_INSERT {
+		t.Errorf("expected the whole block replaced, got %d lines", len(diff))
+	}
+}
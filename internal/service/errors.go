@@ -9,4 +9,40 @@ var (
 	ErrFailedToUploadPostImageToCDN = errors.New("failed to upload post image to CDN")
 	ErrFailedToLikeThePost = errors.New("failed to like the post")
 	ErrFailedToLikeTheComment = errors.New("failed to like the comment")
+	ErrFailedToBookmarkThePost = errors.New("failed to bookmark the post")
+	ErrBookmarkCollectionNotFound = errors.New("bookmark collection not found")
+	ErrFailedToReact = errors.New("failed to react")
+	ErrInvalidReactionType = errors.New("unknown reaction type")
+	ErrFailedToReport = errors.New("failed to report")
+	ErrInvalidReportReason = errors.New("unknown report reason")
+	ErrInvalidReportTarget = errors.New("report target must be post or comment")
+	ErrInvalidReportAction = errors.New("report action must be dismiss, hide, delete or warn")
+	ErrReportsNotFound = errors.New("no open reports")
+	ErrCommentNotFound = errors.New("comment not found")
+	ErrInvalidCommentsSort = errors.New("comments sort must be top, newest, oldest or controversial")
+	ErrCommentEditWindowExpired = errors.New("comment can no longer be edited")
+	ErrNotAllowedToDeleteComment = errors.New("only the comment author, the post author and moderators can delete the comment")
+	ErrNotAllowedToViewCommentRevisions = errors.New("only the comment author and moderators can see the comment edit history")
+	ErrPostNotFound = errors.New("post not found")
+	ErrDraftNotFound = errors.New("draft not found")
+	ErrPublishAtMustBeInFuture = errors.New("publish time must be in the future")
+	ErrPostIsNotScheduled = errors.New("post is not scheduled")
+	ErrRevisionNotFound = errors.New("revision not found")
+	ErrPostClaimedByAnotherModerator = errors.New("post is claimed by another moderator")
+	ErrPostAlreadyApprovedByModerator = errors.New("post is already approved by you")
+	ErrNoPostsToModerate = errors.New("no unclaimed posts to moderate")
+	ErrInvalidDateRange = errors.New("from must be before to")
+	ErrTooManyDecisionsToExport = errors.New("too many decisions to export, narrow the filter")
+	ErrPostIsNotAppealable = errors.New("only the latest rejection of a post can be appealed, once")
+	ErrAppealNotFound = errors.New("appeal not found")
+	ErrAppealRejectedByModerator = errors.New("appeal must be decided by a moderator other than the one who rejected the post")
+	ErrInvalidTag = errors.New("tag must contain letters or digits and be at most 32 characters long")
+	ErrTagIsBanned = errors.New("tag is banned")
+	ErrTagNotFound = errors.New("tag not found")
+	ErrNoTags = errors.New("at least one tag is required")
+	ErrTooManyTags = errors.New("too many tags")
+	ErrInvalidTagsMode = errors.New("tags mode must be any or all")
+	ErrSearchQueryIsEmpty = errors.New("search query must contain at least one word")
+	ErrInvalidTrendingWindow = errors.New("trending window must be 1h, 24h or 7d")
+	ErrDraftIsIncomplete = errors.New("draft must have a title (min 2), content (100-15000) and feed view (100-2000) to be published")
 )
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/BloggingApp/post-service/internal/dto"
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/BloggingApp/post-service/internal/rabbitmq"
+	"github.com/BloggingApp/post-service/internal/repository"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+// Max different usernames resolved in one post or comment, the rest aren't mentions
+const MAX_MENTIONED_USERS = 20
+
+// "@username" not preceded by a word character, "@" or "/" so emails and URLs aren't mentions
+var REGEXP_TO_GET_MENTIONS = regexp.MustCompile(`(?:^|[^\w@/])@(\w+)`)
+
+// @username found in content before it's resolved, offset and length are in bytes
+type mentionMatch struct {
+	username string
+	offset   int
+	length   int
+}
+
+func parseMentions(content string) []mentionMatch {
+	matches := []mentionMatch{}
+	for _, match := range REGEXP_TO_GET_MENTIONS.FindAllStringSubmatchIndex(content, -1) {
+		// "@" is right before the username group
+		matches = append(matches, mentionMatch{
+			username: content[match[2]:match[3]],
+			offset: match[2] - 1,
+			length: match[3] - match[2] + 1,
+		})
+	}
+	return matches
+}
+
+// Post or comment with mentions
+type mentionSource struct {
+	target   string // model.MENTION_TARGET_*
+	targetID int64
+	postID   int64
+	authorID uuid.UUID
+	content  string
+	notify   bool // mentioned users are recorded and notified only if the source is visible
+}
+
+// Resolves mentions in the source content against cached users, saves them and notifies users mentioned
+// in the source for the first time, users aren't notified about their own mentions.
+// Errors are logged and nil is returned, the source is already saved
+func saveMentions(ctx context.Context, repo *repository.Repository, mq *rabbitmq.MQConn, logger *zap.Logger, source mentionSource) []model.Mention {
+	matches := parseMentions(source.content)
+
+	usernames := []string{}
+	seen := make(map[string]struct{})
+	for _, match := range matches {
+		username := strings.ToLower(match.username)
+		if _, ok := seen[username]; ok {
+			continue
+		}
+		if len(usernames) == MAX_MENTIONED_USERS {
+			break
+		}
+		seen[username] = struct{}{}
+		usernames = append(usernames, username)
+	}
+
+	users := make(map[string]*model.CachedUser, len(usernames))
+	if len(usernames) > 0 {
+		found, err := repo.Postgres.UserCache.FindByUsernames(ctx, usernames)
+		if err != nil {
+			logger.Sugar().Errorf("failed to find mentioned users of %s(%d): %s", source.target, source.targetID, err.Error())
+			return nil
+		}
+		for _, user := range found {
+			users[strings.ToLower(user.Username)] = user
+		}
+	}
+
+	mentions := []model.Mention{}
+	for _, match := range matches {
+		user, ok := users[strings.ToLower(match.username)]
+		if !ok {
+			continue
+		}
+		mentions = append(mentions, model.Mention{
+			UserID: user.ID,
+			Username: user.Username,
+			Offset: utf8.RuneCountInString(source.content[:match.offset]),
+			Length: utf8.RuneCountInString(source.content[match.offset:match.offset+match.length]),
+		})
+	}
+
+	newUserIDs, err := repo.Postgres.Mention.Save(ctx, source.target, source.targetID, mentions, source.notify)
+	if err != nil {
+		logger.Sugar().Errorf("failed to save mentions of %s(%d): %s", source.target, source.targetID, err.Error())
+		return nil
+	}
+
+	msg := dto.MQUserMentionedMsg{
+		PostID: source.postID,
+		MentionedByID: source.authorID,
+		CreatedAt: time.Now(),
+	}
+	if source.target == model.MENTION_TARGET_COMMENT {
+		msg.CommentID = &source.targetID
+	}
+	for _, userID := range newUserIDs {
+		if userID == source.authorID {
+			continue
+		}
+		msg.UserID = userID
+
+		msgJSON, err := json.Marshal(msg)
+		if err != nil {
+			logger.Sugar().Errorf("failed to marshal rabbitmq msg for queue(%s) to json: %s", rabbitmq.USER_MENTIONED_QUEUE, err.Error())
+			continue
+		}
+		if err := mq.PublishToQueue(rabbitmq.USER_MENTIONED_QUEUE, msgJSON); err != nil {
+			logger.Sugar().Errorf("failed to publish rabbitmq msg to queue(%s): %s", rabbitmq.USER_MENTIONED_QUEUE, err.Error())
+		}
+	}
+
+	return mentions
+}
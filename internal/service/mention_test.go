@@ -0,0 +1,35 @@
+package service
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected []mentionMatch
+	}{
+		{name: "mention", content: "hi @bob!", expected: []mentionMatch{{username: "bob", offset: 3, length: 4}}},
+		{name: "at the start", content: "@alice look", expected: []mentionMatch{{username: "alice", offset: 0, length: 6}}},
+		{
+			name: "several mentions",
+			content: "@a and @b_2",
+			expected: []mentionMatch{{username: "a", offset: 0, length: 2}, {username: "b_2", offset: 7, length: 4}},
+		},
+		{name: "offset in bytes", content: "привет @bob", expected: []mentionMatch{{username: "bob", offset: 13, length: 4}}},
+		{name: "email", content: "mail me at bob@example.com", expected: []mentionMatch{}},
+		{name: "url", content: "https://example.com/@bob", expected: []mentionMatch{}},
+		{name: "double at", content: "@@bob", expected: []mentionMatch{}},
+		{name: "no username", content: "@ nobody", expected: []mentionMatch{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if matches := parseMentions(tt.content); !slices.Equal(matches, tt.expected) {
+				t.Errorf("expected %+v, got %+v", tt.expected, matches)
+			}
+		})
+	}
+}
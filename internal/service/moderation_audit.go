@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+
+	"github.com/BloggingApp/post-service/internal/dto"
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/BloggingApp/post-service/internal/repository"
+	"go.uber.org/zap"
+)
+
+const (
+	// Decisions exported at most at once, narrower filters export the rest
+	MODERATION_EXPORT_MAX_DECISIONS = 10000
+	MODERATION_EXPORT_PAGE_SIZE = 500
+)
+
+type moderationAuditService struct {
+	logger *zap.Logger
+	repo *repository.Repository
+}
+
+func newModerationAuditService(logger *zap.Logger, repo *repository.Repository) ModerationAudit {
+	return &moderationAuditService{
+		logger: logger,
+		repo: repo,
+	}
+}
+
+func checkDecisionsFilter(filter model.ModerationDecisionsFilter) error {
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return ErrInvalidDateRange
+	}
+	return nil
+}
+
+// Decisions matching the filter, newest first
+func (s *moderationAuditService) FindDecisions(ctx context.Context, filter model.ModerationDecisionsFilter, cursor string, limit int) (*dto.Page[*model.ModerationDecision], error) {
+	if err := checkDecisionsFilter(filter); err != nil {
+		return nil, err
+	}
+
+	limit = pageSize(limit, PAGE_MODERATION)
+	after, err := dto.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	decisions, next, err := s.repo.Postgres.ModerationAudit.FindDecisions(ctx, filter, after, limit)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find moderation decisions from postgres: %s", err.Error())
+		return nil, ErrInternal
+	}
+
+	return dto.NewPage(decisions, next), nil
+}
+
+// All decisions matching the filter, newest first.
+// Returns ErrTooManyDecisionsToExport if more than MODERATION_EXPORT_MAX_DECISIONS match
+func (s *moderationAuditService) ExportDecisions(ctx context.Context, filter model.ModerationDecisionsFilter) ([]*model.ModerationDecision, error) {
+	if err := checkDecisionsFilter(filter); err != nil {
+		return nil, err
+	}
+
+	decisions := []*model.ModerationDecision{}
+	var cursor *model.Cursor
+	for {
+		page, next, err := s.repo.Postgres.ModerationAudit.FindDecisions(ctx, filter, cursor, MODERATION_EXPORT_PAGE_SIZE)
+		if err != nil {
+			s.logger.Sugar().Errorf("failed to find moderation decisions from postgres: %s", err.Error())
+			return nil, ErrInternal
+		}
+		decisions = append(decisions, page...)
+
+		// A partial export would look complete
+		if len(decisions) > MODERATION_EXPORT_MAX_DECISIONS || (len(decisions) == MODERATION_EXPORT_MAX_DECISIONS && next != nil) {
+			return nil, ErrTooManyDecisionsToExport
+		}
+
+		if next == nil {
+			return decisions, nil
+		}
+		cursor = next
+	}
+}
+
+// Stats of moderators over decisions matching the filter, most active first
+func (s *moderationAuditService) FindModeratorStats(ctx context.Context, filter model.ModerationDecisionsFilter) ([]*model.ModeratorStats, error) {
+	if err := checkDecisionsFilter(filter); err != nil {
+		return nil, err
+	}
+
+	stats, err := s.repo.Postgres.ModerationAudit.FindModeratorStats(ctx, filter)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find moderator stats from postgres: %s", err.Error())
+		return nil, ErrInternal
+	}
+
+	for _, moderator := range stats {
+		moderator.ApprovalRate = float64(moderator.Approvals) / float64(moderator.Decisions)
+
+		// Open ends of the range are bounded by the moderator's own decisions
+		from, to := moderator.FirstDecisionAt, moderator.LastDecisionAt
+		if filter.From != nil {
+			from = *filter.From
+		}
+		if filter.To != nil {
+			to = *filter.To
+		}
+		days := max(to.Sub(from).Hours()/24, 1)
+		moderator.DecisionsPerDay = float64(moderator.Decisions) / days
+	}
+
+	return stats, nil
+}
+
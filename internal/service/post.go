@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
@@ -19,6 +20,7 @@ import (
 	"github.com/BloggingApp/post-service/internal/model"
 	"github.com/BloggingApp/post-service/internal/rabbitmq"
 	"github.com/BloggingApp/post-service/internal/repository"
+	"github.com/BloggingApp/post-service/internal/repository/postgres"
 	"github.com/BloggingApp/post-service/internal/repository/redisrepo"
 	"github.com/go-co-op/gocron/v2"
 	"github.com/google/uuid"
@@ -56,6 +58,21 @@ func newPostService(logger *zap.Logger, repo *repository.Repository, rdb *redis.
 const (
 	POST_LIKES_UPDATE_TIMEOUT = time.Minute * 2
 	COMMENT_LIKES_UPDATE_TIMEOUT = time.Minute * 2
+	POST_COMMENTS_COUNT_UPDATE_TIMEOUT = time.Minute * 2
+	DELETED_POSTS_PURGE_TIMEOUT = time.Hour
+	DELETED_POSTS_RETENTION = time.Hour * 24 * 30
+	DRAFT_AUTOSAVES_FLUSH_TIMEOUT = time.Minute
+	DRAFT_AUTOSAVE_TTL = time.Hour * 24
+	SCHEDULED_POSTS_PUBLISH_TIMEOUT = time.Minute
+	SCHEDULED_POST_NOTIFICATION_ATTEMPTS = 3
+	SCHEDULED_POST_NOTIFICATION_RETRY_DELAY = time.Second
+)
+
+const (
+	// Edit is saved as a pending revision and the old version stays live until a moderator approves it
+	EDITS_POLICY_PENDING_REVISION = "pending-revision"
+	// Edit is applied and the post goes back to the moderation queue
+	EDITS_POLICY_UNVALIDATE = "unvalidate"
 )
 
 var REGEXP_TO_GET_IMAGES = regexp.MustCompile(`!\[.*?\]\((.*?)\)`)
@@ -66,19 +83,80 @@ func (s *postService) UploadTempPostImage(ctx context.Context, file multipart.Fi
 }
 
 func (s *postService) Create(ctx context.Context, authorID uuid.UUID, req dto.CreatePostRequest) (*model.Post, error) {
+	if req.PublishAt != nil && !req.PublishAt.After(time.Now()) {
+		return nil, ErrPublishAtMustBeInFuture
+	}
+
+	tags, err := s.canonicalPostTags(ctx, req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := s.moveContentImagesToPerm(req.Content)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to move user(%s)'s post images from temp to perm: %s", authorID.String(), err.Error())
+		return nil, ErrInternal
+	}
+
 	post := model.Post{
 		AuthorID: authorID,
 		Title: req.Title,
-		Content: req.Content,
+		Content: content,
+		FeedView: req.FeedView,
+		PublishAt: req.PublishAt,
 	}
 
-	createdPost, err := s.repo.Postgres.Post.Create(ctx, post, req.Tags)
+	createdPost, err := s.repo.Postgres.Post.Create(ctx, post, tags)
 	if err != nil {
 		s.logger.Sugar().Errorf("failed to create user(%s) post: %s", post.AuthorID.String(), err.Error())
 		return nil, ErrInternal
 	}
 
-	matches := REGEXP_TO_GET_IMAGES.FindAllStringSubmatch(post.Content, -1)
+	result := s.autoModerate(ctx, createdPost.ID, authorID, createdPost.Title, createdPost.FeedView, createdPost.Content)
+	if s.applyAutoModeration(ctx, createdPost.ID, result) {
+		statusMsg := autoModerationStatusMsg(result)
+		createdPost.Validated = result.Decision == model.AUTO_MODERATION_APPROVE
+		createdPost.ValidationStatusMsg = &statusMsg
+	}
+
+	if mentions := s.savePostMentions(ctx, createdPost.ID); mentions != nil {
+		createdPost.Mentions = mentions
+	}
+
+	// Scheduled posts notify followers when they are published by the scheduled job
+	if createdPost.PublishAt != nil {
+		return createdPost, nil
+	}
+
+	if err := s.publishPostCreated(createdPost); err != nil {
+		return nil, err
+	}
+
+	return createdPost, nil
+}
+
+// Saves mentions of the post's current content, mentioned users are notified only if the post is visible.
+// Returns nil if they weren't saved
+func (s *postService) savePostMentions(ctx context.Context, postID int64) []model.Mention {
+	post, err := s.repo.Postgres.Post.FindAnyByID(ctx, postID)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find post(%d) to save its mentions: %s", postID, err.Error())
+		return nil
+	}
+
+	return saveMentions(ctx, s.repo, s.rabbitmq, s.logger, mentionSource{
+		target: model.MENTION_TARGET_POST,
+		targetID: post.ID,
+		postID: post.ID,
+		authorID: post.AuthorID,
+		content: post.Content,
+		notify: post.Validated && !post.Draft && post.PublishAt == nil && post.HiddenAt == nil,
+	})
+}
+
+// Moves images from temp to perm storage and returns the content with rewritten image URLs
+func (s *postService) moveContentImagesToPerm(content string) (string, error) {
+	matches := REGEXP_TO_GET_IMAGES.FindAllStringSubmatch(content, -1)
 
 	moves := make(map[string]string)
 
@@ -94,33 +172,40 @@ func (s *postService) Create(ctx context.Context, authorID uuid.UUID, req dto.Cr
 
 			moves[oldPath] = newPath
 
-			newURL := strings.Replace(url, "/temp", "/perm/", 1)
-			post.Content = strings.ReplaceAll(post.Content, url, newURL)
+			newURL := strings.Replace(url, "/temp/", "/perm/", 1)
+			content = strings.ReplaceAll(content, url, newURL)
 		}
 	}
 
+	if len(moves) == 0 {
+		return content, nil
+	}
+
 	if err := s.moveImagesFromTempToPerm(moves); err != nil {
-		s.logger.Sugar().Errorf("failed to move user(%s)'s post images from temp to perm: %s", authorID.String(), err.Error())
-		return nil, ErrInternal
+		return "", err
 	}
-	
+
+	return content, nil
+}
+
+func (s *postService) publishPostCreated(post *model.Post) error {
 	postCreatedMsg := dto.MQPostCreatedMsg{
-		PostID: createdPost.ID,
-		UserID: authorID,
-		PostTitle: createdPost.Title,
-		CreatedAt: createdPost.CreatedAt,
+		PostID: post.ID,
+		UserID: post.AuthorID,
+		PostTitle: post.Title,
+		CreatedAt: post.CreatedAt,
 	}
 	postCreatedMsgJSON, err := json.Marshal(postCreatedMsg)
 	if err != nil {
-		s.logger.Sugar().Errorf("failed to marshal user(%s)'s post created msg to json: %s", authorID.String(), err.Error())
-		return nil, ErrInternal
+		s.logger.Sugar().Errorf("failed to marshal user(%s)'s post created msg to json: %s", post.AuthorID.String(), err.Error())
+		return ErrInternal
 	}
 	if err := s.rabbitmq.PublishToQueue(rabbitmq.NEW_POST_NOTIFICATION_QUEUE, postCreatedMsgJSON); err != nil {
-		s.logger.Sugar().Errorf("failed to publish user(%s)'s new post notification to rabbitmq: %s", authorID.String(), err.Error())
-		return nil, ErrInternal
+		s.logger.Sugar().Errorf("failed to publish user(%s)'s new post notification to rabbitmq: %s", post.AuthorID.String(), err.Error())
+		return ErrInternal
 	}
 
-	return createdPost, nil
+	return nil
 }
 
 func (s *postService) extractPathFromURL(url string) string {
@@ -266,108 +351,185 @@ func (s *postService) incrViewsIfPostIsNotNil(postID int64) {
 	}(postID)
 }
 
-func (s *postService) FindAuthorPosts(ctx context.Context, authorID uuid.UUID, limit int, offset int) ([]*model.AuthorPost, error) {
-	maxLimit(&limit)
+func (s *postService) FindAuthorPosts(ctx context.Context, authorID uuid.UUID, cursor string, limit int) (*dto.Page[*model.AuthorPost], error) {
+	limit = pageSize(limit, PAGE_POSTS)
+	after, err := dto.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
 
-	cachedPosts, err := redisrepo.GetMany[model.AuthorPost](s.rdb, ctx, redisrepo.AuthorPostsKey(authorID.String(), limit, offset))
-	if err == nil {
-		return cachedPosts, nil
+	cachedPage, err := redisrepo.Get[dto.Page[*model.AuthorPost]](s.rdb, ctx, redisrepo.AuthorPostsKey(authorID.String(), cursor, limit))
+	if err == nil && cachedPage != nil {
+		return cachedPage, nil
 	}
-	if err != redis.Nil {
+	if err != nil && err != redis.Nil {
 		s.logger.Sugar().Errorf("failed to get author(%s)'s posts from redis: %s", authorID.String(), err.Error())
 		return nil, ErrInternal
 	}
 
-	posts, err := s.repo.Postgres.Post.FindAuthorPosts(ctx, authorID, limit, offset)
-	if err != nil && err != pgx.ErrNoRows {
+	posts, next, err := s.repo.Postgres.Post.FindAuthorPosts(ctx, authorID, after, limit)
+	if err != nil {
 		s.logger.Sugar().Errorf("failed to find author(%s)'s posts from postgres: %s", authorID.String(), err.Error())
 		return nil, ErrInternal
 	}
+	page := dto.NewPage(posts, next)
 
-	if err := redisrepo.SetJSON(s.rdb, ctx, redisrepo.AuthorPostsKey(authorID.String(), limit, offset), posts, time.Minute); err != nil {
+	if err := redisrepo.SetJSON(s.rdb, ctx, redisrepo.AuthorPostsKey(authorID.String(), cursor, limit), page, time.Minute); err != nil {
 		s.logger.Sugar().Errorf("failed to set author(%s)'s posts in redis: %s", authorID.String(), err.Error())
 		return nil, ErrInternal
 	}
 
-	return posts, nil
+	return page, nil
 }
 
-func (s *postService) FindUserNotValidatedPosts(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*model.AuthorPost, error) {
-	maxLimit(&limit)
+func (s *postService) FindUserNotValidatedPosts(ctx context.Context, userID uuid.UUID, cursor string, limit int) (*dto.Page[*model.AuthorPost], error) {
+	limit = pageSize(limit, PAGE_POSTS)
+	after, err := dto.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
 
-	cachedPosts, err := redisrepo.GetMany[model.AuthorPost](s.rdb, ctx, redisrepo.UserNotValidatedPostsKey(userID.String(), limit, offset))
-	if err == nil {
-		return cachedPosts, nil
+	cachedPage, err := redisrepo.Get[dto.Page[*model.AuthorPost]](s.rdb, ctx, redisrepo.UserNotValidatedPostsKey(userID.String(), cursor, limit))
+	if err == nil && cachedPage != nil {
+		return cachedPage, nil
 	}
-	if err != redis.Nil {
+	if err != nil && err != redis.Nil {
 		s.logger.Sugar().Errorf("failed to get user(%s)'s not validated posts from redis: %s", userID.String(), err.Error())
 		return nil, ErrInternal
 	}
 
-	posts, err := s.repo.Postgres.Post.FindUserNotValidatedPosts(ctx, userID, limit, offset)
-	if err != nil && err != pgx.ErrNoRows {
+	posts, next, err := s.repo.Postgres.Post.FindUserNotValidatedPosts(ctx, userID, after, limit)
+	if err != nil {
 		s.logger.Sugar().Errorf("failed to find user(%s)'s not validated posts from postgres: %s", userID.String(), err.Error())
 		return nil, ErrInternal
 	}
+	page := dto.NewPage(posts, next)
 
-	if err := redisrepo.SetJSON(s.rdb, ctx, redisrepo.UserNotValidatedPostsKey(userID.String(), limit, offset), posts, time.Minute); err != nil {
+	if err := redisrepo.SetJSON(s.rdb, ctx, redisrepo.UserNotValidatedPostsKey(userID.String(), cursor, limit), page, time.Minute); err != nil {
 		s.logger.Sugar().Errorf("failed to set user(%s)'s not validated posts in redis: %s", userID.String(), err.Error())
 		return nil, ErrInternal
 	}
 
-	return posts, nil
+	return page, nil
 }
 
-func (s *postService) FindNotValidatedPosts(ctx context.Context, limit, offset int) ([]*model.FullPost, error) {
-	maxLimit(&limit)
-
-	cachedPosts, err := redisrepo.GetMany[model.FullPost](s.rdb, ctx, redisrepo.NotValidatedPostsKey(limit, offset))
-	if err == nil {
-		return cachedPosts, nil
+// Posts and pending edits are paginated separately, cursor is for posts and editsCursor is for pending edits
+func (s *postService) FindNotValidatedPosts(ctx context.Context, cursor, editsCursor string, limit int) (*dto.ModerationQueue, error) {
+	limit = pageSize(limit, PAGE_MODERATION)
+	after, err := dto.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
 	}
-	if err != redis.Nil {
+
+	posts, err := redisrepo.Get[dto.Page[*model.QueuedPost]](s.rdb, ctx, redisrepo.NotValidatedPostsKey(cursor, limit))
+	if err != nil && err != redis.Nil {
 		s.logger.Sugar().Errorf("failed to get not validated posts from redis: %s", err.Error())
 		return nil, ErrInternal
 	}
+	if posts == nil {
+		items, next, err := s.repo.Postgres.Post.FindNotValidatedPosts(ctx, nil, after, limit)
+		if err != nil {
+			s.logger.Sugar().Errorf("failed to find not validated posts from postgres: %s", err.Error())
+			return nil, ErrInternal
+		}
+		posts = dto.NewPage(items, next)
 
-	posts, err := s.repo.Postgres.Post.FindNotValidatedPosts(ctx, limit, offset)
-	if err != nil && err != pgx.ErrNoRows {
-		s.logger.Sugar().Errorf("failed to find not validated posts from postgres: %s", err.Error())
+		if err := redisrepo.SetJSON(s.rdb, ctx, redisrepo.NotValidatedPostsKey(cursor, limit), posts, time.Minute); err != nil {
+			s.logger.Sugar().Errorf("failed to set not validated posts in redis: %s", err.Error())
+			return nil, ErrInternal
+		}
+	}
+
+	pendingEdits, err := s.findPendingEdits(ctx, editsCursor, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ModerationQueue{
+		Posts: *posts,
+		PendingEdits: *pendingEdits,
+	}, nil
+}
+
+// Returns pending revisions of validated posts with a diff against the live version
+func (s *postService) findPendingEdits(ctx context.Context, cursor string, limit int) (*dto.Page[*dto.PendingEdit], error) {
+	after, err := dto.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, next, err := s.repo.Postgres.PostRevision.FindPendingRevisions(ctx, after, limit)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find pending revisions from postgres: %s", err.Error())
 		return nil, ErrInternal
 	}
 
-	if err := redisrepo.SetJSON(s.rdb, ctx, redisrepo.NotValidatedPostsKey(limit, offset), posts, time.Minute); err != nil {
-		s.logger.Sugar().Errorf("failed to set not validated posts in redis: %s", err.Error())
+	pendingEdits := []*dto.PendingEdit{}
+	for _, revision := range revisions {
+		pendingEdit, err := s.pendingEdit(ctx, revision)
+		if err != nil {
+			return nil, err
+		}
+		if pendingEdit != nil {
+			pendingEdits = append(pendingEdits, pendingEdit)
+		}
+	}
+
+	return dto.NewPage(pendingEdits, next), nil
+}
+
+// Pending revision with a diff against the live version, nil if the post isn't found
+func (s *postService) pendingEdit(ctx context.Context, revision *model.PostRevision) (*dto.PendingEdit, error) {
+	post, err := s.repo.Postgres.Post.FindByID(ctx, revision.PostID)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find post(%d) from postgres: %s", revision.PostID, err.Error())
 		return nil, ErrInternal
 	}
+	if post == nil {
+		return nil, nil
+	}
 
-	return posts, nil
+	return &dto.PendingEdit{
+		Revision: *revision,
+		Post: *post,
+		Diff: dto.PostDiff{
+			Title: diffLines(post.Post.Title, revision.Title),
+			FeedView: diffLines(post.Post.FeedView, revision.FeedView),
+			Content: diffLines(post.Post.Content, revision.Content),
+			Tags: diffLines(strings.Join(post.Tags, "\n"), strings.Join(revision.Tags, "\n")),
+		},
+	}, nil
 }
 
-func (s *postService) FindUserLikes(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]*model.FullPost, error) {
-	maxLimit(&limit)
+func (s *postService) FindUserLikes(ctx context.Context, userID uuid.UUID, cursor string, limit int) (*dto.Page[*model.FullPost], error) {
+	limit = pageSize(limit, PAGE_POSTS)
+	after, err := dto.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
 
-	postsCache, err := redisrepo.GetMany[model.FullPost](s.rdb, ctx, redisrepo.UserLikesKey(userID.String(), limit, offset))
-	if err == nil {
-		return postsCache, nil
+	cachedPage, err := redisrepo.Get[dto.Page[*model.FullPost]](s.rdb, ctx, redisrepo.UserLikesKey(userID.String(), cursor, limit))
+	if err == nil && cachedPage != nil {
+		return cachedPage, nil
 	}
-	if err != redis.Nil {
+	if err != nil && err != redis.Nil {
 		s.logger.Sugar().Errorf("failed to get user(%s) likes from redis: %s", userID.String(), err.Error())
 		return nil, ErrInternal
 	}
 
-	posts, err := s.repo.Postgres.Post.FindUserLikes(ctx, userID, limit, offset)
-	if err != nil && err != pgx.ErrNoRows {
+	posts, next, err := s.repo.Postgres.Post.FindUserLikes(ctx, userID, after, limit)
+	if err != nil {
 		s.logger.Sugar().Errorf("failed to get user(%s) likes from postgres: %s", userID.String(), err.Error())
 		return nil, ErrInternal
 	}
+	page := dto.NewPage(posts, next)
 
-	if err := redisrepo.SetJSON(s.rdb, ctx, redisrepo.UserLikesKey(userID.String(), limit, offset), posts, time.Hour); err != nil {
+	if err := redisrepo.SetJSON(s.rdb, ctx, redisrepo.UserLikesKey(userID.String(), cursor, limit), page, time.Hour); err != nil {
 		s.logger.Sugar().Errorf("failed to set user(%s) likes in redis: %s", userID.String(), err.Error())
 		return nil, ErrInternal
 	}
 
-	return posts, nil
+	return page, nil
 }
 
 func (s *postService) IsLiked(ctx context.Context, postID int64, userID uuid.UUID) bool {
@@ -467,137 +629,215 @@ func (s *postService) postsBatchLikesUpdate(ctx context.Context) error {
 	return nil
 }
 
-func (s *postService) GetTrending(ctx context.Context, hours, limit int) ([]*model.FullPost, error) {
-	if hours > 24 * 7 {
-		hours = 24 * 7
+// Results are cached by the normalized query, so queries differing only in case or punctuation share cache
+func (s *postService) Search(ctx context.Context, query string, cursor string, limit int) (*dto.Page[*model.PostSearchResult], error) {
+	limit = pageSize(limit, PAGE_SEARCH)
+	after, err := dto.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	tsQuery := parseSearchQuery(query)
+	if tsQuery == "" {
+		return nil, ErrSearchQueryIsEmpty
 	}
 
-	postsCache, err := redisrepo.GetMany[model.FullPost](s.rdb, ctx, redisrepo.TrendingPostsKey(limit))
-	if err == nil {
-		return postsCache, nil
+	cachedPage, err := redisrepo.Get[dto.Page[*model.PostSearchResult]](s.rdb, ctx, redisrepo.SearchPostsResultKey(tsQuery, cursor, limit))
+	if err == nil && cachedPage != nil {
+		return cachedPage, nil
 	}
-	if err != redis.Nil {
-		s.logger.Sugar().Errorf("failed to get trending posts with limit(%d) from redis: %s", limit, err.Error())
+	if err != nil && err != redis.Nil {
+		s.logger.Sugar().Errorf("failed to get posts search result by query(%s) from redis: %s", tsQuery, err.Error())
 		return nil, ErrInternal
 	}
 
-	posts, err := s.repo.Postgres.Post.GetTrending(ctx, hours, limit)
+	result, next, err := s.repo.Postgres.Post.Search(ctx, tsQuery, after, limit)
 	if err != nil {
-		s.logger.Sugar().Errorf("failed to get trending posts with limit(%d) from postgres: %s", limit, err.Error())
+		s.logger.Sugar().Errorf("failed to get posts search result by query(%s) from postgres: %s", tsQuery, err.Error())
 		return nil, ErrInternal
 	}
+	page := dto.NewPage(result, next)
 
-	if err := redisrepo.SetJSON(s.rdb, ctx, redisrepo.TrendingPostsKey(limit), posts, time.Duration(hours * int(time.Hour))); err != nil {
-		s.logger.Sugar().Errorf("failed to set trending posts for limit(%d) in redis cache: %s", limit, err.Error())
+	if err := redisrepo.SetJSON(s.rdb, ctx, redisrepo.SearchPostsResultKey(tsQuery, cursor, limit), page, time.Minute); err != nil {
+		s.logger.Sugar().Errorf("failed to set posts search result by query(%s) in redis: %s", tsQuery, err.Error())
 		return nil, ErrInternal
 	}
 
-	return posts, nil
+	return page, nil
 }
 
-func (s *postService) SearchByTitle(ctx context.Context, title string, limit, offset int) ([]*model.FullPost, error) {
-	resultCache, err := redisrepo.GetMany[model.FullPost](s.rdb, ctx, redisrepo.SearchPostsResultByTitleKey(title, limit, offset))
-	if err == nil {
-		return resultCache, nil
+// Tags are matched by their canonical names, so aliases work too
+func (s *postService) FindByTags(ctx context.Context, tags []string, mode string, cursor string, limit int) (*dto.Page[*model.FullPost], error) {
+	limit = pageSize(limit, PAGE_POSTS)
+	after, err := dto.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
 	}
-	if err != redis.Nil {
-		s.logger.Sugar().Errorf("failed to get posts search result by title(%s) from redis: %s", title, err.Error())
+
+	if len(tags) > MAX_TAGS_PER_QUERY {
+		return nil, ErrTooManyTags
+	}
+
+	tags, err = s.canonicalQueryTags(ctx, tags)
+	if err != nil {
+		return nil, err
+	}
+	if len(tags) == 0 {
+		return nil, ErrNoTags
+	}
+
+	if mode == "" {
+		mode = TAGS_MODE_ANY
+	}
+	if mode != TAGS_MODE_ANY && mode != TAGS_MODE_ALL {
+		return nil, ErrInvalidTagsMode
+	}
+
+	cachedPage, err := redisrepo.Get[dto.Page[*model.FullPost]](s.rdb, ctx, redisrepo.TagsPostsKey(mode, tags, cursor, limit))
+	if err == nil && cachedPage != nil {
+		return cachedPage, nil
+	}
+	if err != nil && err != redis.Nil {
+		s.logger.Sugar().Errorf("failed to get posts by tags(%v) from redis: %s", tags, err.Error())
 		return nil, ErrInternal
 	}
 
-	result, err := s.repo.Postgres.Post.SearchByTitle(ctx, title, limit, offset)
+	posts, next, err := s.repo.Postgres.Post.SearchByTags(ctx, tags, mode == TAGS_MODE_ALL, after, limit)
 	if err != nil {
-		s.logger.Sugar().Errorf("failed to get posts search result by title(%s) from postgres: %s", title, err.Error())
+		s.logger.Sugar().Errorf("failed to find posts by tags(%v) from postgres: %s", tags, err.Error())
 		return nil, ErrInternal
 	}
+	page := dto.NewPage(posts, next)
 
-	if err := redisrepo.SetJSON(s.rdb, ctx, redisrepo.SearchPostsResultByTitleKey(title, limit, offset), result, time.Minute); err != nil {
-		s.logger.Sugar().Errorf("failed to set posts search result by title(%s) in redis: %s", title, err.Error())
+	if err := redisrepo.SetJSON(s.rdb, ctx, redisrepo.TagsPostsKey(mode, tags, cursor, limit), page, time.Minute); err != nil {
+		s.logger.Sugar().Errorf("failed to set posts by tags(%v) in redis: %s", tags, err.Error())
 		return nil, ErrInternal
 	}
 
-	return result, nil
+	return page, nil
 }
 
-func (s *postService) Edit(ctx context.Context, input dto.EditPostRequest) error {
-	post, err := s.repo.Postgres.Post.FindByID(ctx, input.PostID)
+// Images removed from the content are kept in storage, because earlier revisions still reference them and can be rolled back to.
+// They are deleted when the post is purged.
+// Returns true if the edit is waiting for moderation
+func (s *postService) Edit(ctx context.Context, input dto.EditPostRequest) (bool, error) {
+	post, err := s.repo.Postgres.Post.FindAuthorPost(ctx, input.PostID, input.AuthorID)
 	if err != nil {
+		if err == pgx.ErrNoRows {
+			return false, ErrPostNotFound
+		}
+
 		s.logger.Sugar().Errorf("failed to get post(%d) from postres: %s", input.PostID, err.Error())
-		return ErrInternal
+		return false, ErrInternal
 	}
 
 	updates := make(map[string]any)
 
+	tags, err := s.editedPostTags(ctx, post.Tags, input)
+	if err != nil {
+		return false, err
+	}
+	if tags != nil {
+		updates["tags"] = tags
+	}
+
 	if input.Content != nil {
-		editedContent := *input.Content
+		// Moving new added images to post from temp to perm storage
+		editedContent, err := s.moveContentImagesToPerm(*input.Content)
+		if err != nil {
+			s.logger.Sugar().Errorf("failed to move user(%s)'s post images from temp to perm: %s", post.Post.AuthorID.String(), err.Error())
+			return false, ErrInternal
+		}
 
-		newUrls := []string{}
-		oldUrls := []string{}
+		updates["content"] = editedContent
+	}
 
-		matches := REGEXP_TO_GET_IMAGES.FindAllStringSubmatch(editedContent, -1)
-		moves := make(map[string]string)
-		for _, match := range matches {
-			if len(match) < 2 {
-				continue
-			}
-			url := match[1]
-			newUrls = append(newUrls, url)
+	if input.Title != nil {
+		updates["title"] = *input.Title
+	}
 
-			if strings.Contains(url, "/temp/") {
-				oldPath := s.extractPathFromURL(url)
-				newPath := strings.Replace(oldPath, "/temp/", "/perm/", 1)
+	if input.FeedView != nil {
+		updates["feed_view"] = *input.FeedView
+	}
 
-				moves[oldPath] = newPath
+	// Post as it's going to be moderated
+	title, feedView, content := post.Post.Title, post.Post.FeedView, post.Post.Content
+	if input.Title != nil {
+		title = *input.Title
+	}
+	if input.FeedView != nil {
+		feedView = *input.FeedView
+	}
+	if input.Content != nil {
+		content = updates["content"].(string)
+	}
+	moderatedChanged := input.Title != nil || input.FeedView != nil || input.Content != nil
 
-				newURL := strings.Replace(url, "/temp", "/perm/", 1)
-				editedContent = strings.ReplaceAll(editedContent, url, newURL)
-			}
-		}
+	policy := editsPolicy()
+	needsModeration := post.Post.Validated && (
+		(input.Title != nil && *input.Title != post.Post.Title) ||
+		(input.Content != nil && updates["content"] != post.Post.Content))
 
-		// Moving new added images to post from temp to perm storage
-		if err := s.moveImagesFromTempToPerm(moves); err != nil {
-			s.logger.Sugar().Errorf("failed to move user(%s)'s post images from temp to perm: %s", post.Post.AuthorID.String(), err.Error())
-			return ErrInternal
+	if needsModeration && policy == EDITS_POLICY_PENDING_REVISION {
+		// Tags are not moderated, so they are updated right away
+		revision, err := s.repo.Postgres.Post.CreatePendingRevision(ctx, post.Post.ID, input.AuthorID, updates)
+		if err != nil {
+			s.logger.Sugar().Errorf("failed to create post(%d) pending revision: %s", post.Post.ID, err.Error())
+			return false, ErrInternal
 		}
 
-		// Getting old post images
-		matches = REGEXP_TO_GET_IMAGES.FindAllStringSubmatch(post.Post.Content, -1)
-		for _, match := range matches {
-			if len(match) < 2 {
-				continue
-			}
-			url := match[1]
-			oldUrls = append(oldUrls, url)
+		if tags != nil {
+			s.invalidatePostTagCaches(ctx, post.Post.ID)
 		}
 
-		// Retrieving removed images from post
-		removedPaths := []string{}
-		for _, oldUrl := range oldUrls {
-			if slices.Contains(newUrls, oldUrl) {
-				continue
-			}
-
-			removedPaths = append(removedPaths, s.extractPathFromURL(oldUrl))
+		if revision == nil {
+			return false, nil
 		}
 
-		if err := s.deletePostImages(removedPaths); err != nil {
-			s.logger.Sugar().Errorf("failed to delete post(%d) removed urls: %s", post.Post.ID, err.Error())
-			return ErrInternal
+		result := s.autoModerateRevision(ctx, revision.ID, input.AuthorID, title, feedView, content)
+		if s.applyRevisionAutoModeration(ctx, revision.ID, result) && result.Decision == model.AUTO_MODERATION_APPROVE {
+			return false, nil
 		}
 
-		updates["content"] = editedContent
+		return true, nil
 	}
 
-	if input.Title != nil {
-		updates["title"] = *input.Title
+	if err := s.repo.Postgres.Post.Update(ctx, post.Post.ID, input.AuthorID, updates, policy == EDITS_POLICY_UNVALIDATE); err != nil {
+		s.logger.Sugar().Errorf("failed to update post(%d): %s", post.Post.ID, err.Error())
+		return false, ErrInternal
 	}
 
-	if err := s.repo.Postgres.Post.Update(ctx, post.Post.ID, input.AuthorID, updates); err != nil {
-		s.logger.Sugar().Errorf("failed to update post(%d): %s", post.Post.ID, err.Error())
-		return ErrInternal
+	if input.Content != nil {
+		s.savePostMentions(ctx, post.Post.ID)
 	}
 
-	return nil
+	if tags != nil {
+		s.invalidatePostTagCaches(ctx, post.Post.ID)
+	} else if err := s.rdb.Del(ctx, redisrepo.PostKey(post.Post.ID)).Err(); err != nil {
+		s.logger.Sugar().Errorf("failed to delete post(%d) from redis: %s", post.Post.ID, err.Error())
+	}
+
+	unvalidated := needsModeration && policy == EDITS_POLICY_UNVALIDATE
+	if moderatedChanged && (!post.Post.Validated || unvalidated) {
+		result := s.autoModerate(ctx, post.Post.ID, input.AuthorID, title, feedView, content)
+		if s.applyAutoModeration(ctx, post.Post.ID, result) && result.Decision == model.AUTO_MODERATION_APPROVE {
+			return false, nil
+		}
+	}
+
+	return unvalidated, nil
+}
+
+func (s *postService) invalidatePostTagCaches(ctx context.Context, postID int64) {
+	invalidateTagCaches(ctx, s.rdb, s.logger, []int64{postID})
+}
+
+// Policy for edits of title or content of validated posts, "moderation.edits-policy" in config
+func editsPolicy() string {
+	if viper.GetString("moderation.edits-policy") == EDITS_POLICY_UNVALIDATE {
+		return EDITS_POLICY_UNVALIDATE
+	}
+	return EDITS_POLICY_PENDING_REVISION
 }
 
 func (s *postService) deletePostImages(paths []string) error {
@@ -625,28 +865,156 @@ func (s *postService) SchedulePostLikesUpdates() {
 	}))
 }
 
+func (s *postService) SchedulePostsPurge() {
+	s.scheduler.NewJob(gocron.DurationJob(DELETED_POSTS_PURGE_TIMEOUT), gocron.NewTask(func(ctx context.Context) {
+		if err := s.purgeDeletedPosts(ctx); err != nil {
+			s.logger.Sugar().Error(err.Error())
+		}
+	}))
+}
+
 func (s *postService) StartScheduledJobs() {
 	s.SchedulePostLikesUpdates()
+	s.SchedulePostsPurge()
+	s.ScheduleDraftAutosavesFlush()
+	s.SchedulePostsPublishing()
+	s.ScheduleTrendingRecompute()
+	s.ScheduleRelatedPostsRecompute()
+	s.scheduleBackfills()
 
 	s.scheduler.Start()
 }
 
-func (s *postService) UpdateValidationStatus(ctx context.Context, id int64, moderatorID uuid.UUID, validated bool, validationStatusMsg string) error {
-	post, err := s.FindByID(ctx, id)
+// Rejections are applied right away, approvals once the post has as many of them as its tags require.
+// Returns false if the approval was counted and the post is waiting for more approvals.
+// Decisions on posts claimed by other moderators are rejected, the moderator's claim is released after the decision
+func (s *postService) UpdateValidationStatus(ctx context.Context, id int64, moderatorID uuid.UUID, validated bool, validationStatusMsg string) (bool, error) {
+	post, err := s.repo.Postgres.Post.FindAnyByID(ctx, id)
 	if err != nil {
-		return err
+		if err == pgx.ErrNoRows {
+			return false, ErrPostNotFound
+		}
+
+		s.logger.Sugar().Errorf("failed to find post(%d) from postgres: %s", id, err.Error())
+		return false, ErrInternal
+	}
+
+	if err := s.checkPostClaim(ctx, id, moderatorID); err != nil {
+		return false, err
+	}
+
+	// Validated posts are re-approved by one moderator
+	approvals := 1
+	if validated && !post.Validated {
+		approvals, err = s.postRequiredApprovals(ctx, post)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	// Claim may have expired and been taken by another moderator since it was checked
+	holdClaim := func(ctx context.Context) error {
+		return s.holdPostClaim(ctx, id, moderatorID)
 	}
 
-	if err := s.repo.Postgres.Post.UpdateValidationStatus(ctx, id, moderatorID, validated, validationStatusMsg); err != nil {
+	applied, err := s.repo.Postgres.Post.UpdateValidationStatus(ctx, id, moderatorID, validated, validationStatusMsg, approvals, holdClaim)
+	if err != nil {
+		if errors.Is(err, postgres.ErrPostAlreadyApprovedByModerator) {
+			return false, ErrPostAlreadyApprovedByModerator
+		}
+		if errors.Is(err, ErrPostClaimedByAnotherModerator) || errors.Is(err, ErrInternal) {
+			return false, err
+		}
+
 		s.logger.Sugar().Errorf("failed to update post(%d) validation status: %s", id, err.Error())
-		return ErrInternal
+		return false, ErrInternal
 	}
 
-	msgJSON, err := json.Marshal(dto.MQPostValidationStatusUpdateMsg{
+	// Released so the post is handed to the next moderator if it needs more approvals
+	if err := s.ReleasePostClaim(ctx, id, moderatorID); err != nil {
+		s.logger.Sugar().Errorf("failed to release post(%d) claim after the decision: %s", id, err.Error())
+	}
+
+	if !applied {
+		return false, nil
+	}
+
+	// Users mentioned while the post was waiting for moderation are notified now
+	if validated {
+		s.savePostMentions(ctx, id)
+	}
+
+	if err := s.rdb.Del(ctx, redisrepo.PostKey(id)).Err(); err != nil {
+		s.logger.Sugar().Errorf("failed to delete post(%d) from redis: %s", id, err.Error())
+	}
+
+	if validated && !post.Draft && post.PublishAt == nil && post.DeletedAt == nil && post.HiddenAt == nil {
+		s.postBecameVisible(ctx, post)
+	}
+
+	return true, s.publishValidationStatusUpdate(dto.MQPostValidationStatusUpdateMsg{
 		PostID: id,
-		UserID: post.Post.AuthorID,
+		UserID: post.AuthorID,
 		StatusMsg: validationStatusMsg,
 	})
+}
+
+// Approves or rejects a pending edit of a validated post.
+// Edits of posts claimed by other moderators are rejected like post decisions, the moderator's claim is released after the decision
+func (s *postService) ReviewRevision(ctx context.Context, revisionID int64, moderatorID uuid.UUID, approved bool, statusMsg string) error {
+	postID, err := s.repo.Postgres.PostRevision.FindPendingPostID(ctx, revisionID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrRevisionNotFound
+		}
+
+		s.logger.Sugar().Errorf("failed to find revision(%d) post from postgres: %s", revisionID, err.Error())
+		return ErrInternal
+	}
+
+	if err := s.checkPostClaim(ctx, postID, moderatorID); err != nil {
+		return err
+	}
+
+	holdClaim := func(ctx context.Context) error {
+		return s.holdPostClaim(ctx, postID, moderatorID)
+	}
+
+	revision, err := s.repo.Postgres.Post.ReviewRevision(ctx, revisionID, moderatorID, approved, statusMsg, holdClaim)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrRevisionNotFound
+		}
+		if errors.Is(err, ErrPostClaimedByAnotherModerator) || errors.Is(err, ErrInternal) {
+			return err
+		}
+
+		s.logger.Sugar().Errorf("failed to review revision(%d): %s", revisionID, err.Error())
+		return ErrInternal
+	}
+
+	if err := s.ReleasePostClaim(ctx, postID, moderatorID); err != nil {
+		s.logger.Sugar().Errorf("failed to release post(%d) claim after the decision: %s", postID, err.Error())
+	}
+
+	if approved {
+		s.savePostMentions(ctx, revision.PostID)
+	}
+
+	if err := s.rdb.Del(ctx, redisrepo.PostKey(revision.PostID)).Err(); err != nil {
+		s.logger.Sugar().Errorf("failed to delete post(%d) from redis: %s", revision.PostID, err.Error())
+	}
+
+	return s.publishValidationStatusUpdate(dto.MQPostValidationStatusUpdateMsg{
+		PostID: revision.PostID,
+		UserID: revision.AuthorID,
+		StatusMsg: statusMsg,
+		RevisionID: &revision.ID,
+	})
+}
+
+func (s *postService) publishValidationStatusUpdate(msg dto.MQPostValidationStatusUpdateMsg) error {
+	msgJSON, err := json.Marshal(msg)
 	if err != nil {
 		s.logger.Sugar().Errorf("failed to marshal rabbitmq msg for queue(%s) to json: %s", rabbitmq.POST_VALIDATION_STATUS_UPDATES_QUEUE, err.Error())
 		return ErrInternal
@@ -658,3 +1026,139 @@ func (s *postService) UpdateValidationStatus(ctx context.Context, id int64, mode
 
 	return nil
 }
+
+func (s *postService) Delete(ctx context.Context, id int64, authorID uuid.UUID) error {
+	if err := s.repo.Postgres.Post.Delete(ctx, id, authorID); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrPostNotFound
+		}
+
+		s.logger.Sugar().Errorf("failed to delete user(%s)'s post(%d): %s", authorID.String(), id, err.Error())
+		return ErrInternal
+	}
+
+	if err := s.rdb.Del(ctx, redisrepo.PostKey(id)).Err(); err != nil {
+		s.logger.Sugar().Errorf("failed to delete post(%d) from redis: %s", id, err.Error())
+	}
+
+	return nil
+}
+
+func (s *postService) Restore(ctx context.Context, id int64, authorID uuid.UUID) error {
+	if err := s.repo.Postgres.Post.Restore(ctx, id, authorID); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrPostNotFound
+		}
+
+		s.logger.Sugar().Errorf("failed to restore user(%s)'s post(%d): %s", authorID.String(), id, err.Error())
+		return ErrInternal
+	}
+
+	if err := s.rdb.Del(ctx, redisrepo.PostKey(id)).Err(); err != nil {
+		s.logger.Sugar().Errorf("failed to delete post(%d) from redis: %s", id, err.Error())
+	}
+
+	post, err := s.repo.Postgres.Post.FindAnyByID(ctx, id)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find post(%d) from postgres: %s", id, err.Error())
+		return nil
+	}
+	if post.Validated && !post.Draft && post.PublishAt == nil && post.HiddenAt == nil {
+		s.postBecameVisible(ctx, post)
+	}
+
+	return nil
+}
+
+// Permanently deletes a post from the author's trash without waiting for the retention window
+func (s *postService) Purge(ctx context.Context, id int64, authorID uuid.UUID) error {
+	post, err := s.repo.Postgres.Post.FindAuthorDeletedPost(ctx, id, authorID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrPostNotFound
+		}
+
+		s.logger.Sugar().Errorf("failed to find user(%s)'s deleted post(%d): %s", authorID.String(), id, err.Error())
+		return ErrInternal
+	}
+
+	if err := s.purgePost(ctx, post); err != nil {
+		s.logger.Sugar().Error(err.Error())
+		return ErrInternal
+	}
+
+	return nil
+}
+
+func (s *postService) FindAuthorDeletedPosts(ctx context.Context, authorID uuid.UUID, cursor string, limit int) (*dto.Page[*model.AuthorPost], error) {
+	limit = pageSize(limit, PAGE_POSTS)
+	after, err := dto.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, next, err := s.repo.Postgres.Post.FindAuthorDeletedPosts(ctx, authorID, after, limit)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find user(%s)'s deleted posts from postgres: %s", authorID.String(), err.Error())
+		return nil, ErrInternal
+	}
+
+	return dto.NewPage(posts, next), nil
+}
+
+func (s *postService) purgeDeletedPosts(ctx context.Context) error {
+	posts, err := s.repo.Postgres.Post.FindDeletedBefore(ctx, time.Now().Add(-DELETED_POSTS_RETENTION))
+	if err != nil {
+		return fmt.Errorf("failed to find deleted posts to purge from postgres: %s", err.Error())
+	}
+
+	// Failed posts are retried on the next run and don't block the rest
+	for _, post := range posts {
+		if err := s.purgePost(ctx, post); err != nil {
+			s.logger.Sugar().Error(err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (s *postService) purgePost(ctx context.Context, post *model.Post) error {
+	contents, err := s.repo.Postgres.PostRevision.FindPostContents(ctx, post.ID)
+	if err != nil {
+		return fmt.Errorf("failed to find post(%d) revisions contents: %s", post.ID, err.Error())
+	}
+	contents = append(contents, post.Content)
+
+	paths := []string{}
+	for _, content := range contents {
+		for _, path := range s.extractImagePaths(content) {
+			if !slices.Contains(paths, path) {
+				paths = append(paths, path)
+			}
+		}
+	}
+	if len(paths) > 0 {
+		if err := s.deletePostImages(paths); err != nil {
+			return fmt.Errorf("failed to delete post(%d) images: %s", post.ID, err.Error())
+		}
+	}
+
+	if err := s.repo.Postgres.Post.Purge(ctx, post.ID); err != nil {
+		return fmt.Errorf("failed to purge post(%d) from postgres: %s", post.ID, err.Error())
+	}
+
+	return nil
+}
+
+func (s *postService) extractImagePaths(content string) []string {
+	paths := []string{}
+	matches := REGEXP_TO_GET_IMAGES.FindAllStringSubmatch(content, -1)
+	for _, match := range matches {
+		if len(match) < 2 {
+			continue
+		}
+		paths = append(paths, s.extractPathFromURL(match[1]))
+	}
+
+	return paths
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/BloggingApp/post-service/internal/dto"
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/BloggingApp/post-service/internal/rabbitmq"
+	"github.com/BloggingApp/post-service/internal/repository/postgres"
+	"github.com/BloggingApp/post-service/internal/repository/redisrepo"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// Appeals the latest rejection of the author's post, optionally editing the post.
+// Each rejection can be appealed once. The appeal is created before the edit is applied and is withdrawn
+// if the edit fails. Returns nil appeal if auto-moderation approved the edited post
+func (s *postService) Appeal(ctx context.Context, postID int64, authorID uuid.UUID, req dto.AppealPostRequest) (*model.PostAppeal, error) {
+	rejection, err := s.repo.Postgres.Appeal.FindAppealableRejection(ctx, postID, authorID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPostIsNotAppealable
+		}
+
+		s.logger.Sugar().Errorf("failed to find post(%d) rejection from postgres: %s", postID, err.Error())
+		return nil, ErrInternal
+	}
+
+	appeal, err := s.repo.Postgres.Appeal.Create(ctx, model.PostAppeal{
+		AuthorID: authorID,
+		RejectionID: rejection.ID,
+		Justification: req.Justification,
+		Edited: req.Edit != nil,
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrPostIsNotAppealable
+		}
+
+		s.logger.Sugar().Errorf("failed to create post(%d) appeal: %s", postID, err.Error())
+		return nil, ErrInternal
+	}
+
+	if req.Edit == nil {
+		return appeal, nil
+	}
+
+	// Rejected posts aren't validated, so the edit is applied right away
+	req.Edit.PostID = postID
+	req.Edit.AuthorID = authorID
+	if _, err := s.Edit(ctx, *req.Edit); err != nil {
+		s.withdrawAppeal(ctx, appeal)
+		return nil, err
+	}
+
+	// Auto-moderation may have approved the edited post, then there's nothing to appeal
+	post, err := s.repo.Postgres.Post.FindAnyByID(ctx, postID)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find post(%d) from postgres: %s", postID, err.Error())
+		return appeal, nil
+	}
+	if post.Validated {
+		s.withdrawAppeal(ctx, appeal)
+		return nil, nil
+	}
+
+	return appeal, nil
+}
+
+func (s *postService) withdrawAppeal(ctx context.Context, appeal *model.PostAppeal) {
+	if err := s.repo.Postgres.Appeal.Withdraw(ctx, appeal.ID); err != nil {
+		s.logger.Sugar().Errorf("failed to withdraw post(%d) appeal(%d): %s", appeal.PostID, appeal.ID, err.Error())
+	}
+}
+
+func (s *postService) FindPostAppeals(ctx context.Context, postID int64, authorID uuid.UUID) ([]*model.PostAppeal, error) {
+	appeals, err := s.repo.Postgres.Appeal.FindPostAppeals(ctx, postID, authorID)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find post(%d) appeals from postgres: %s", postID, err.Error())
+		return nil, ErrInternal
+	}
+
+	return appeals, nil
+}
+
+// Pending appeals of posts rejected by other moderators, oldest first
+func (s *postService) FindAppealsQueue(ctx context.Context, moderatorID uuid.UUID, cursor string, limit int) (*dto.Page[*model.AppealedPost], error) {
+	limit = pageSize(limit, PAGE_MODERATION)
+	after, err := dto.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	appeals, next, err := s.repo.Postgres.Appeal.FindQueue(ctx, moderatorID, after, limit)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find appeals queue from postgres: %s", err.Error())
+		return nil, ErrInternal
+	}
+
+	return dto.NewPage(appeals, next), nil
+}
+
+// Upholds or overturns the appeal and notifies the author. Overturned appeal is the moderator's approval of the post,
+// which is validated once it has as many approvals as its tags require. Decisions on posts claimed by other moderators
+// are rejected, the moderator's claim is released after the decision
+func (s *postService) DecideAppeal(ctx context.Context, appealID int64, moderatorID uuid.UUID, req dto.DecideAppealRequest) (*model.PostAppeal, error) {
+	postID, err := s.repo.Postgres.Appeal.FindPendingPostID(ctx, appealID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAppealNotFound
+		}
+
+		s.logger.Sugar().Errorf("failed to find appeal(%d) post from postgres: %s", appealID, err.Error())
+		return nil, ErrInternal
+	}
+
+	if err := s.checkPostClaim(ctx, postID, moderatorID); err != nil {
+		return nil, err
+	}
+
+	approvals := 1
+	if req.Overturned {
+		post, err := s.repo.Postgres.Post.FindAnyByID(ctx, postID)
+		if err != nil {
+			s.logger.Sugar().Errorf("failed to find post(%d) from postgres: %s", postID, err.Error())
+			return nil, ErrInternal
+		}
+
+		approvals, err = s.postRequiredApprovals(ctx, post)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	holdClaim := func(ctx context.Context) error {
+		return s.holdPostClaim(ctx, postID, moderatorID)
+	}
+
+	appeal, validated, err := s.repo.Postgres.Appeal.Decide(ctx, appealID, moderatorID, req.Overturned, req.DecisionMsg, approvals, holdClaim)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrAppealNotFound
+		}
+		if errors.Is(err, postgres.ErrAppealRejectedByModerator) {
+			return nil, ErrAppealRejectedByModerator
+		}
+		if errors.Is(err, postgres.ErrPostAlreadyApprovedByModerator) {
+			return nil, ErrPostAlreadyApprovedByModerator
+		}
+		if errors.Is(err, ErrPostClaimedByAnotherModerator) || errors.Is(err, ErrInternal) {
+			return nil, err
+		}
+
+		s.logger.Sugar().Errorf("failed to decide appeal(%d): %s", appealID, err.Error())
+		return nil, ErrInternal
+	}
+
+	if err := s.ReleasePostClaim(ctx, postID, moderatorID); err != nil {
+		s.logger.Sugar().Errorf("failed to release post(%d) claim after the decision: %s", postID, err.Error())
+	}
+
+	if validated {
+		s.applyOverturnedAppeal(ctx, appeal.PostID)
+	}
+
+	s.publishAppealDecision(appeal, moderatorID, req.DecisionMsg)
+
+	return appeal, nil
+}
+
+// Decision is already saved, so a failed notification is only logged
+func (s *postService) publishAppealDecision(appeal *model.PostAppeal, moderatorID uuid.UUID, decisionMsg string) {
+	msgJSON, err := json.Marshal(dto.MQPostAppealDecidedMsg{
+		AppealID: appeal.ID,
+		PostID: appeal.PostID,
+		UserID: appeal.AuthorID,
+		ModeratorID: moderatorID,
+		Status: appeal.Status,
+		DecisionMsg: decisionMsg,
+		DecidedAt: *appeal.DecidedAt,
+	})
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to marshal rabbitmq msg for queue(%s) to json: %s", rabbitmq.POST_APPEAL_DECIDED_QUEUE, err.Error())
+		return
+	}
+	if err := s.rabbitmq.PublishToQueue(rabbitmq.POST_APPEAL_DECIDED_QUEUE, msgJSON); err != nil {
+		s.logger.Sugar().Errorf("failed to publish rabbitmq msg to queue(%s): %s", rabbitmq.POST_APPEAL_DECIDED_QUEUE, err.Error())
+	}
+}
+
+// Same effects as a validation of the post, failures are logged since the post is already validated
+func (s *postService) applyOverturnedAppeal(ctx context.Context, postID int64) {
+	s.savePostMentions(ctx, postID)
+
+	if err := s.rdb.Del(ctx, redisrepo.PostKey(postID)).Err(); err != nil {
+		s.logger.Sugar().Errorf("failed to delete post(%d) from redis: %s", postID, err.Error())
+	}
+
+	post, err := s.repo.Postgres.Post.FindAnyByID(ctx, postID)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find post(%d) from postgres: %s", postID, err.Error())
+		return
+	}
+	if !post.Draft && post.PublishAt == nil && post.DeletedAt == nil && post.HiddenAt == nil {
+		s.postBecameVisible(ctx, post)
+	}
+}
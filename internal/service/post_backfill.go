@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/BloggingApp/post-service/internal/repository/redisrepo"
+	"github.com/go-co-op/gocron/v2"
+)
+
+const BACKFILL_BATCH_SIZE = 500
+
+// One-off backfills of data added after posts already existed. They run once on every startup and are
+// idempotent, so restarts are safe. Most only touch rows that weren't backfilled yet, followers counts are
+// recounted in full. Comments counts are recounted by the comment service before it flushes them
+func (s *postService) scheduleBackfills() {
+	backfills := []func(ctx context.Context) error{
+		s.backfillSearchVectors,
+		s.normalizePostTags,
+		s.backfillFollowerCounts,
+		s.deleteTombstonesRevisions,
+	}
+
+	s.scheduler.NewJob(gocron.OneTimeJob(gocron.OneTimeJobStartImmediately()), gocron.NewTask(func(ctx context.Context) {
+		for _, backfill := range backfills {
+			if err := backfill(ctx); err != nil {
+				s.logger.Sugar().Error(err.Error())
+			}
+		}
+	}))
+}
+
+// Makes posts written before full-text search searchable and creates the search index
+func (s *postService) backfillSearchVectors(ctx context.Context) error {
+	for {
+		updated, err := s.repo.Postgres.Post.BackfillSearchVectors(ctx, BACKFILL_BATCH_SIZE)
+		if err != nil {
+			return fmt.Errorf("failed to backfill posts search vectors: %s", err.Error())
+		}
+		if updated == 0 {
+			break
+		}
+	}
+
+	if err := s.repo.Postgres.Post.CreateSearchIndex(ctx); err != nil {
+		return fmt.Errorf("failed to create posts search index: %s", err.Error())
+	}
+
+	return nil
+}
+
+// Counts followers of follows saved before followers counts were maintained
+func (s *postService) backfillFollowerCounts(ctx context.Context) error {
+	if _, err := s.repo.Postgres.Follow.RecountFollowers(ctx); err != nil {
+		return fmt.Errorf("failed to recount followers: %s", err.Error())
+	}
+
+	return nil
+}
+
+// Removes previous versions of comments deleted before tombstones lost their revisions
+func (s *postService) deleteTombstonesRevisions(ctx context.Context) error {
+	if _, err := s.repo.Postgres.Comment.DeleteTombstonesRevisions(ctx); err != nil {
+		return fmt.Errorf("failed to delete revisions of deleted comments: %s", err.Error())
+	}
+
+	return nil
+}
+
+// Merges tags written before normalization into their canonical tags, so a post doesn't get the same tag
+// twice and old spellings become aliases. Tags that can't be normalized or resolve to a banned tag are removed
+func (s *postService) normalizePostTags(ctx context.Context) error {
+	slugs, err := s.repo.Postgres.Tag.FindUsedSlugs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to find used tags: %s", err.Error())
+	}
+
+	merges := make(map[string][]string)
+	var invalid []string
+	for _, slug := range slugs {
+		normalized, ok := normalizeTag(slug)
+		if !ok {
+			invalid = append(invalid, slug)
+			continue
+		}
+		if normalized != slug {
+			merges[normalized] = append(merges[normalized], slug)
+		}
+	}
+	if len(merges) == 0 && len(invalid) == 0 {
+		return nil
+	}
+
+	var postIDs []int64
+	for normalized, from := range merges {
+		canonical, banned, err := s.repo.Postgres.Tag.Resolve(ctx, []string{normalized})
+		if err != nil {
+			return fmt.Errorf("failed to resolve tag(%s): %s", normalized, err.Error())
+		}
+		if len(banned) != 0 {
+			invalid = append(invalid, from...)
+			continue
+		}
+
+		to := canonical[0]
+		from = slices.DeleteFunc(from, func(slug string) bool { return slug == to })
+		if len(from) == 0 {
+			continue
+		}
+
+		merged, err := s.repo.Postgres.Tag.Merge(ctx, from, to)
+		if err != nil {
+			return fmt.Errorf("failed to merge tags(%v) into tag(%s): %s", from, to, err.Error())
+		}
+		postIDs = append(postIDs, merged...)
+	}
+
+	if len(invalid) != 0 {
+		removed, err := s.repo.Postgres.Tag.RemoveFromPosts(ctx, invalid)
+		if err != nil {
+			return fmt.Errorf("failed to remove invalid tags(%v) from posts: %s", invalid, err.Error())
+		}
+		postIDs = append(postIDs, removed...)
+	}
+
+	invalidateTagCaches(ctx, s.rdb, s.logger, postIDs, redisrepo.AUTHOR_POSTS_KEY_PATTERN, redisrepo.SEARCH_POSTS_RESULT_KEY_PATTERN)
+
+	// Per-tag trending sets are keyed by tag, rebuilding them drops the sets of old spellings
+	if err := s.recomputeTrending(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,335 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/BloggingApp/post-service/internal/dto"
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/BloggingApp/post-service/internal/repository/redisrepo"
+	"github.com/go-co-op/gocron/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+)
+
+func (s *postService) CreateDraft(ctx context.Context, authorID uuid.UUID, req dto.SaveDraftRequest) (*model.Post, error) {
+	tags, err := s.canonicalPostTags(ctx, req.Tags)
+	if err != nil {
+		return nil, err
+	}
+
+	post := model.Post{
+		AuthorID: authorID,
+		Title: req.Title,
+		Content: req.Content,
+		FeedView: req.FeedView,
+		Draft: true,
+	}
+
+	createdDraft, err := s.repo.Postgres.Post.Create(ctx, post, tags)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to create user(%s) draft: %s", authorID.String(), err.Error())
+		return nil, ErrInternal
+	}
+
+	return createdDraft, nil
+}
+
+func (s *postService) FindAuthorDrafts(ctx context.Context, authorID uuid.UUID, cursor string, limit int) (*dto.Page[*model.AuthorPost], error) {
+	limit = pageSize(limit, PAGE_POSTS)
+	after, err := dto.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	drafts, next, err := s.repo.Postgres.Post.FindAuthorDrafts(ctx, authorID, after, limit)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find user(%s)'s drafts from postgres: %s", authorID.String(), err.Error())
+		return nil, ErrInternal
+	}
+
+	return dto.NewPage(drafts, next), nil
+}
+
+// Returns the draft with its not yet flushed autosave applied
+func (s *postService) FindAuthorDraft(ctx context.Context, id int64, authorID uuid.UUID) (*model.AuthorPost, error) {
+	draft, err := s.repo.Postgres.Post.FindAuthorDraft(ctx, id, authorID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrDraftNotFound
+		}
+
+		s.logger.Sugar().Errorf("failed to find user(%s)'s draft(%d) from postgres: %s", authorID.String(), id, err.Error())
+		return nil, ErrInternal
+	}
+
+	autosave, err := redisrepo.Get[dto.DraftAutosave](s.rdb, ctx, redisrepo.DraftAutosaveKey(id))
+	if err != nil && err != redis.Nil {
+		s.logger.Sugar().Errorf("failed to get draft(%d) autosave from redis: %s", id, err.Error())
+		return nil, ErrInternal
+	}
+	if autosave != nil && autosave.AuthorID == authorID {
+		if autosave.Title != nil {
+			draft.Post.Title = *autosave.Title
+		}
+		if autosave.Content != nil {
+			draft.Post.Content = *autosave.Content
+		}
+		if autosave.FeedView != nil {
+			draft.Post.FeedView = *autosave.FeedView
+		}
+	}
+
+	return draft, nil
+}
+
+func (s *postService) UpdateDraft(ctx context.Context, id int64, authorID uuid.UUID, req dto.EditDraftRequest) error {
+	// Pending autosave is older than the explicit update, so it is applied first
+	if err := s.flushDraftAutosave(ctx, id); err != nil {
+		s.logger.Sugar().Error(err.Error())
+		return ErrInternal
+	}
+
+	updates := make(map[string]any)
+	if req.Title != nil {
+		updates["title"] = *req.Title
+	}
+	if req.Content != nil {
+		updates["content"] = *req.Content
+	}
+	if req.FeedView != nil {
+		updates["feed_view"] = *req.FeedView
+	}
+
+	var tags []string
+	if req.Tags != nil {
+		canonicalTags, err := s.canonicalPostTags(ctx, *req.Tags)
+		if err != nil {
+			return err
+		}
+		tags = canonicalTags
+	}
+
+	if err := s.repo.Postgres.Post.UpdateDraft(ctx, id, authorID, updates, tags); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrDraftNotFound
+		}
+
+		s.logger.Sugar().Errorf("failed to update user(%s)'s draft(%d): %s", authorID.String(), id, err.Error())
+		return ErrInternal
+	}
+
+	return nil
+}
+
+// Autosave only touches redis, drafts are checked in postgres once per autosave session.
+// Autosaves are flushed to postgres by a scheduled job, on update and on publish
+func (s *postService) AutosaveDraft(ctx context.Context, id int64, authorID uuid.UUID, req dto.AutosaveDraftRequest) error {
+	key := redisrepo.DraftAutosaveKey(id)
+
+	autosave, err := redisrepo.Get[dto.DraftAutosave](s.rdb, ctx, key)
+	if err != nil && err != redis.Nil {
+		s.logger.Sugar().Errorf("failed to get draft(%d) autosave from redis: %s", id, err.Error())
+		return ErrInternal
+	}
+
+	if autosave == nil || autosave.AuthorID != authorID {
+		if _, err := s.repo.Postgres.Post.FindAuthorDraft(ctx, id, authorID); err != nil {
+			if err == pgx.ErrNoRows {
+				return ErrDraftNotFound
+			}
+
+			s.logger.Sugar().Errorf("failed to find user(%s)'s draft(%d) from postgres: %s", authorID.String(), id, err.Error())
+			return ErrInternal
+		}
+
+		autosave = &dto.DraftAutosave{AuthorID: authorID}
+	}
+
+	if req.Title != nil {
+		autosave.Title = req.Title
+	}
+	if req.Content != nil {
+		autosave.Content = req.Content
+	}
+	if req.FeedView != nil {
+		autosave.FeedView = req.FeedView
+	}
+
+	if err := redisrepo.SetJSON(s.rdb, ctx, key, autosave, DRAFT_AUTOSAVE_TTL); err != nil {
+		s.logger.Sugar().Errorf("failed to set draft(%d) autosave in redis: %s", id, err.Error())
+		return ErrInternal
+	}
+
+	return nil
+}
+
+func (s *postService) DiscardDraft(ctx context.Context, id int64, authorID uuid.UUID) error {
+	if err := s.repo.Postgres.Post.DeleteDraft(ctx, id, authorID); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrDraftNotFound
+		}
+
+		s.logger.Sugar().Errorf("failed to delete user(%s)'s draft(%d): %s", authorID.String(), id, err.Error())
+		return ErrInternal
+	}
+
+	if err := s.rdb.Del(ctx, redisrepo.DraftAutosaveKey(id)).Err(); err != nil {
+		s.logger.Sugar().Errorf("failed to delete draft(%d) autosave from redis: %s", id, err.Error())
+	}
+
+	return nil
+}
+
+// Moves draft images to perm storage, submits the post for validation and notifies about the new post.
+// If publishAt is not nil the post is scheduled and notification is sent at publish time
+func (s *postService) PublishDraft(ctx context.Context, id int64, authorID uuid.UUID, publishAt *time.Time) (*model.Post, error) {
+	if publishAt != nil && !publishAt.After(time.Now()) {
+		return nil, ErrPublishAtMustBeInFuture
+	}
+
+	if err := s.flushDraftAutosave(ctx, id); err != nil {
+		s.logger.Sugar().Error(err.Error())
+		return nil, ErrInternal
+	}
+
+	draft, err := s.repo.Postgres.Post.FindAuthorDraft(ctx, id, authorID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrDraftNotFound
+		}
+
+		s.logger.Sugar().Errorf("failed to find user(%s)'s draft(%d) from postgres: %s", authorID.String(), id, err.Error())
+		return nil, ErrInternal
+	}
+
+	if !isDraftComplete(draft.Post) {
+		return nil, ErrDraftIsIncomplete
+	}
+
+	content, err := s.moveContentImagesToPerm(draft.Post.Content)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to move user(%s)'s post images from temp to perm: %s", authorID.String(), err.Error())
+		return nil, ErrInternal
+	}
+
+	post, err := s.repo.Postgres.Post.Publish(ctx, id, authorID, content, publishAt)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrDraftNotFound
+		}
+
+		s.logger.Sugar().Errorf("failed to publish user(%s)'s draft(%d): %s", authorID.String(), id, err.Error())
+		return nil, ErrInternal
+	}
+
+	result := s.autoModerate(ctx, post.ID, authorID, post.Title, post.FeedView, post.Content)
+	if s.applyAutoModeration(ctx, post.ID, result) {
+		statusMsg := autoModerationStatusMsg(result)
+		post.Validated = result.Decision == model.AUTO_MODERATION_APPROVE
+		post.ValidationStatusMsg = &statusMsg
+	}
+
+	if mentions := s.savePostMentions(ctx, post.ID); mentions != nil {
+		post.Mentions = mentions
+	}
+
+	if post.PublishAt != nil {
+		return post, nil
+	}
+
+	if err := s.publishPostCreated(post); err != nil {
+		return nil, err
+	}
+
+	return post, nil
+}
+
+// Same limits as in dto.CreatePostRequest
+func isDraftComplete(post model.Post) bool {
+	titleLen := utf8.RuneCountInString(post.Title)
+	contentLen := utf8.RuneCountInString(post.Content)
+	feedViewLen := utf8.RuneCountInString(post.FeedView)
+
+	return titleLen >= 2 &&
+		contentLen >= 100 && contentLen <= 15000 &&
+		feedViewLen >= 100 && feedViewLen <= 2000
+}
+
+// Deletes the key only if its value is still ARGV[1], so a newer autosave isn't lost
+var deleteUnchangedAutosaveScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// Autosave is deleted from redis only after it's saved to postgres
+func (s *postService) flushDraftAutosave(ctx context.Context, id int64) error {
+	key := redisrepo.DraftAutosaveKey(id)
+	value, err := s.rdb.Get(ctx, key).Result()
+	if err == redis.Nil {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get draft(%d) autosave from redis: %s", id, err.Error())
+	}
+
+	var autosave dto.DraftAutosave
+	if err := json.Unmarshal([]byte(value), &autosave); err != nil {
+		return fmt.Errorf("failed to unmarshal draft(%d) autosave: %s", id, err.Error())
+	}
+
+	updates := make(map[string]any)
+	if autosave.Title != nil {
+		updates["title"] = *autosave.Title
+	}
+	if autosave.Content != nil {
+		updates["content"] = *autosave.Content
+	}
+	if autosave.FeedView != nil {
+		updates["feed_view"] = *autosave.FeedView
+	}
+
+	if err := s.repo.Postgres.Post.UpdateDraft(ctx, id, autosave.AuthorID, updates, nil); err != nil && err != pgx.ErrNoRows {
+		return fmt.Errorf("failed to flush draft(%d) autosave to postgres: %s", id, err.Error())
+	}
+
+	if err := deleteUnchangedAutosaveScript.Run(ctx, s.rdb, []string{key}, value).Err(); err != nil {
+		return fmt.Errorf("failed to delete draft(%d) autosave from redis: %s", id, err.Error())
+	}
+
+	return nil
+}
+
+func (s *postService) draftsBatchAutosaveFlush(ctx context.Context) error {
+	keys, err := s.rdb.Keys(ctx, redisrepo.DRAFT_AUTOSAVE_KEY_PATTERN).Result()
+	if err != nil && err != redis.Nil {
+		return fmt.Errorf("failed to get keys with pattern(%s) from redis: %s", redisrepo.DRAFT_AUTOSAVE_KEY_PATTERN, err.Error())
+	}
+
+	for _, key := range keys {
+		postID, err := redisrepo.GetPostIDFromDraftAutosaveKey(key)
+		if err != nil {
+			continue
+		}
+
+		if err := s.flushDraftAutosave(ctx, postID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *postService) ScheduleDraftAutosavesFlush() {
+	s.scheduler.NewJob(gocron.DurationJob(DRAFT_AUTOSAVES_FLUSH_TIMEOUT), gocron.NewTask(func(ctx context.Context) {
+		if err := s.draftsBatchAutosaveFlush(ctx); err != nil {
+			s.logger.Sugar().Error(err.Error())
+		}
+	}))
+}
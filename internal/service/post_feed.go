@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/BloggingApp/post-service/internal/dto"
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/BloggingApp/post-service/internal/rabbitmq"
+	"github.com/BloggingApp/post-service/internal/repository/redisrepo"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+)
+
+// Feed is a hybrid: posts of authors with at most "feed.fanout-max-followers" followers are pushed
+// to followers' timelines (redis sorted sets of post IDs scored by created_at in microseconds),
+// posts of bigger authors are read from postgres when the feed is requested
+const (
+	FEED_DEFAULT_FANOUT_MAX_FOLLOWERS = 1000
+	FEED_DEFAULT_TIMELINE_SIZE = 500
+	FEED_TIMELINE_TTL = time.Hour * 24 * 7
+	// Keeps the timeline key when the user has nothing to see, so it isn't rebuilt on every request
+	FEED_TIMELINE_PLACEHOLDER = "0"
+)
+
+func fanoutMaxFollowers() int64 {
+	if max := viper.GetInt64("feed.fanout-max-followers"); max > 0 {
+		return max
+	}
+	return FEED_DEFAULT_FANOUT_MAX_FOLLOWERS
+}
+
+func timelineSize() int64 {
+	if size := viper.GetInt64("feed.timeline-size"); size > 0 {
+		return size
+	}
+	return FEED_DEFAULT_TIMELINE_SIZE
+}
+
+// Returns validated posts of followed authors, newest first
+func (s *postService) FindFeed(ctx context.Context, userID uuid.UUID, cursor string, limit int) (*dto.Page[*model.FullPost], error) {
+	limit = pageSize(limit, PAGE_POSTS)
+	after, err := dto.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	followees, err := s.repo.Postgres.Follow.FindFollowees(ctx, userID)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find user(%s)'s followees from postgres: %s", userID.String(), err.Error())
+		return nil, ErrInternal
+	}
+	if len(followees) == 0 {
+		return dto.NewPage([]*model.FullPost{}, nil), nil
+	}
+
+	var smallAuthors, largeAuthors []uuid.UUID
+	for _, followee := range followees {
+		if followee.FollowersCount > fanoutMaxFollowers() {
+			largeAuthors = append(largeAuthors, followee.ID)
+		} else {
+			smallAuthors = append(smallAuthors, followee.ID)
+		}
+	}
+
+	key := redisrepo.UserTimelineKey(userID.String())
+	if err := s.ensureTimeline(ctx, key, smallAuthors); err != nil {
+		s.logger.Sugar().Errorf("failed to build user(%s)'s timeline: %s", userID.String(), err.Error())
+		return nil, ErrInternal
+	}
+
+	max := "+inf"
+	if after != nil {
+		max = strconv.FormatInt(after.CreatedAt.UnixMicro(), 10)
+	}
+	entries, err := s.rdb.ZRevRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{
+		Min: "(0",
+		Max: max,
+		Count: int64(limit + 1),
+	}).Result()
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to get user(%s)'s timeline from redis: %s", userID.String(), err.Error())
+		return nil, ErrInternal
+	}
+
+	// Timeline is trimmed to its size, older posts of small authors are read from postgres
+	authorIDs := largeAuthors
+	if len(entries) <= limit {
+		size, err := s.rdb.ZCard(ctx, key).Result()
+		if err != nil {
+			s.logger.Sugar().Errorf("failed to get user(%s)'s timeline size from redis: %s", userID.String(), err.Error())
+			return nil, ErrInternal
+		}
+		if size >= timelineSize() {
+			authorIDs = append(authorIDs, smallAuthors...)
+		}
+	}
+
+	postIDs := []int64{}
+	for _, entry := range entries {
+		postID, err := strconv.ParseInt(entry.Member.(string), 10, 64)
+		if err != nil {
+			continue
+		}
+		postIDs = append(postIDs, postID)
+	}
+
+	// There may be more timeline entries after the fetched ones, so posts of large authors
+	// are only read up to the last fetched entry, otherwise newer unfetched entries would be skipped
+	var until *model.Cursor
+	if len(entries) == limit+1 {
+		last := entries[len(entries)-1]
+		lastID, _ := strconv.ParseInt(last.Member.(string), 10, 64)
+		until = &model.Cursor{
+			CreatedAt: time.UnixMicro(int64(last.Score)),
+			ID: lastID,
+		}
+	}
+
+	posts, next, err := s.repo.Postgres.Post.FindFeed(ctx, postIDs, authorIDs, after, until, limit)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find user(%s)'s feed from postgres: %s", userID.String(), err.Error())
+		return nil, ErrInternal
+	}
+
+	// Page isn't full, so every visible post from the timeline entries is in it.
+	// Missing ones were deleted or unvalidated and are dropped from the timeline
+	if next == nil && len(entries) > 0 {
+		s.dropHiddenTimelineEntries(ctx, key, entries, posts, after)
+
+		// Every post up to the last fetched entry is in the page, the next one continues after it
+		next = until
+	}
+
+	if err := s.rdb.Expire(ctx, key, FEED_TIMELINE_TTL).Err(); err != nil {
+		s.logger.Sugar().Errorf("failed to set user(%s)'s timeline expiration in redis: %s", userID.String(), err.Error())
+	}
+
+	return dto.NewPage(posts, next), nil
+}
+
+// Builds the timeline from postgres if it doesn't exist
+func (s *postService) ensureTimeline(ctx context.Context, key string, authorIDs []uuid.UUID) error {
+	exists, err := s.rdb.Exists(ctx, key).Result()
+	if err != nil {
+		return err
+	}
+	if exists == 1 {
+		return nil
+	}
+
+	timeline := []redis.Z{{Score: 0, Member: FEED_TIMELINE_PLACEHOLDER}}
+	if len(authorIDs) != 0 {
+		entries, err := s.repo.Postgres.Post.FindTimelineEntries(ctx, authorIDs, int(timelineSize()))
+		if err != nil {
+			return err
+		}
+
+		for _, entry := range entries {
+			timeline = append(timeline, redis.Z{
+				Score: float64(entry.CreatedAt.UnixMicro()),
+				Member: strconv.FormatInt(entry.PostID, 10),
+			})
+		}
+	}
+
+	pipe := s.rdb.TxPipeline()
+	pipe.ZAdd(ctx, key, timeline...)
+	pipe.Expire(ctx, key, FEED_TIMELINE_TTL)
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (s *postService) dropHiddenTimelineEntries(ctx context.Context, key string, entries []redis.Z, posts []*model.FullPost, after *model.Cursor) {
+	visible := make(map[string]struct{}, len(posts))
+	for _, post := range posts {
+		visible[strconv.FormatInt(post.Post.ID, 10)] = struct{}{}
+	}
+
+	var hidden []any
+	for _, entry := range entries {
+		member := entry.Member.(string)
+		if _, ok := visible[member]; ok {
+			continue
+		}
+		// Entries at the cursor position were on the previous page
+		if after != nil && int64(entry.Score) == after.CreatedAt.UnixMicro() {
+			continue
+		}
+		hidden = append(hidden, member)
+	}
+
+	if len(hidden) == 0 {
+		return
+	}
+
+	if err := s.rdb.ZRem(ctx, key, hidden...).Err(); err != nil {
+		s.logger.Sugar().Errorf("failed to remove hidden posts from timeline(%s) in redis: %s", key, err.Error())
+	}
+}
+
+// Updates feeds and related posts with a post that became visible to readers
+func (s *postService) postBecameVisible(ctx context.Context, post *model.Post) {
+	s.fanOutPost(ctx, post)
+	s.markRelatedPostsStale(ctx, post.ID)
+}
+
+// Pushes a post that became visible to timelines of the author's followers.
+// Only existing timelines are updated, missing ones are built with the post when requested
+func (s *postService) fanOutPost(ctx context.Context, post *model.Post) {
+	count, err := s.repo.Postgres.Follow.CountFollowers(ctx, post.AuthorID)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to count user(%s)'s followers: %s", post.AuthorID.String(), err.Error())
+		return
+	}
+	if count == 0 || count > fanoutMaxFollowers() {
+		return
+	}
+
+	followers, err := s.repo.Postgres.Follow.FindFollowers(ctx, post.AuthorID)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find user(%s)'s followers: %s", post.AuthorID.String(), err.Error())
+		return
+	}
+
+	existsPipe := s.rdb.Pipeline()
+	exists := make([]*redis.IntCmd, len(followers))
+	for i, follower := range followers {
+		exists[i] = existsPipe.Exists(ctx, redisrepo.UserTimelineKey(follower.String()))
+	}
+	if _, err := existsPipe.Exec(ctx); err != nil {
+		s.logger.Sugar().Errorf("failed to check followers' timelines in redis: %s", err.Error())
+		return
+	}
+
+	entry := redis.Z{
+		Score: float64(post.CreatedAt.UnixMicro()),
+		Member: strconv.FormatInt(post.ID, 10),
+	}
+	pipe := s.rdb.Pipeline()
+	for i, follower := range followers {
+		if exists[i].Val() == 0 {
+			continue
+		}
+
+		key := redisrepo.UserTimelineKey(follower.String())
+		pipe.ZAdd(ctx, key, entry)
+		pipe.ZRemRangeByRank(ctx, key, 0, -timelineSize()-1)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		s.logger.Sugar().Errorf("failed to push post(%d) to followers' timelines in redis: %s", post.ID, err.Error())
+	}
+}
+
+func (s *postService) consumeFollows(ctx context.Context) {
+	exchange := rabbitmq.USERS_FOLLOWS_EXCHANGE
+	msgs, err := s.rabbitmq.ConsumeExchange(exchange)
+	if err != nil {
+		s.logger.Sugar().Fatalf("failed to start consume follows from exchange(%s): %s", exchange, err.Error())
+	}
+
+	for msg := range msgs {
+		var data dto.MQUserFollowMsg
+		if err := json.Unmarshal(msg.Body, &data); err != nil {
+			s.logger.Sugar().Errorf("failed to unmarshal json in exchange(%s): %s", exchange, err.Error())
+			msg.Ack(false)
+			continue
+		}
+
+		if data.Followed {
+			if data.CreatedAt.IsZero() {
+				data.CreatedAt = time.Now()
+			}
+			err = s.repo.Postgres.Follow.Create(ctx, model.Follow{
+				FollowerID: data.FollowerID,
+				FolloweeID: data.FolloweeID,
+				CreatedAt: data.CreatedAt,
+			})
+		} else {
+			err = s.repo.Postgres.Follow.Delete(ctx, data.FollowerID, data.FolloweeID)
+		}
+		if err != nil {
+			s.logger.Sugar().Errorf("failed to save follow(%s -> %s) in exchange(%s): %s", data.FollowerID.String(), data.FolloweeID.String(), exchange, err.Error())
+			msg.Ack(false)
+			continue
+		}
+
+		// Timeline is rebuilt with the new set of followees when requested
+		if err := s.rdb.Del(ctx, redisrepo.UserTimelineKey(data.FollowerID.String())).Err(); err != nil {
+			s.logger.Sugar().Errorf("failed to delete user(%s)'s timeline from redis: %s", data.FollowerID.String(), err.Error())
+		}
+
+		msg.Ack(false)
+	}
+}
@@ -0,0 +1,234 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/BloggingApp/post-service/internal/dto"
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/BloggingApp/post-service/internal/repository/redisrepo"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+)
+
+const (
+	DEFAULT_MODERATION_CLAIM_TTL = time.Minute * 15
+	// Queue pages looked through for an unclaimed post when assigning the next one
+	MODERATION_ASSIGNMENT_MAX_PAGES = 10
+	MODERATION_ASSIGNMENT_PAGE_SIZE = 50
+)
+
+// Takes the lease if it's free or extends it if the moderator already holds it, returns 0 if someone else holds it
+var claimPostScript = redis.NewScript(`
+local owner = redis.call("GET", KEYS[1])
+if owner and owner ~= ARGV[1] then
+	return 0
+end
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+return 1
+`)
+
+// Deletes the lease only if the moderator holds it, returns 0 if someone else holds it
+var releasePostClaimScript = redis.NewScript(`
+local owner = redis.call("GET", KEYS[1])
+if owner and owner ~= ARGV[1] then
+	return 0
+end
+redis.call("DEL", KEYS[1])
+return 1
+`)
+
+// Lease duration, "moderation.claim-ttl" in config
+func moderationClaimTTL() time.Duration {
+	if ttl := viper.GetDuration("moderation.claim-ttl"); ttl > 0 {
+		return ttl
+	}
+	return DEFAULT_MODERATION_CLAIM_TTL
+}
+
+// Approvals of different moderators a post needs to be validated, the most required by its tags
+// in "moderation.approvals.tags" or "moderation.approvals.default"
+func requiredApprovals(tags []string) int {
+	required := max(viper.GetInt("moderation.approvals.default"), 1)
+	for _, tag := range tags {
+		required = max(required, viper.GetInt("moderation.approvals.tags."+tag))
+	}
+	return required
+}
+
+// Approvals the post needs by its tags, see requiredApprovals
+func (s *postService) postRequiredApprovals(ctx context.Context, post *model.Post) (int, error) {
+	authorPost, err := s.repo.Postgres.Post.FindAuthorPost(ctx, post.ID, post.AuthorID)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find post(%d) tags from postgres: %s", post.ID, err.Error())
+		return 0, ErrInternal
+	}
+
+	return requiredApprovals(authorPost.Tags), nil
+}
+
+func (s *postService) tryClaimPost(ctx context.Context, postID int64, moderatorID uuid.UUID) (*model.ModerationClaim, error) {
+	ttl := moderationClaimTTL()
+	claimed, err := claimPostScript.Run(ctx, s.rdb, []string{redisrepo.ModerationClaimKey(postID)}, moderatorID.String(), ttl.Milliseconds()).Int()
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to claim post(%d) for moderator(%s) in redis: %s", postID, moderatorID.String(), err.Error())
+		return nil, ErrInternal
+	}
+	if claimed == 0 {
+		return nil, nil
+	}
+
+	return &model.ModerationClaim{
+		PostID: postID,
+		ModeratorID: moderatorID,
+		ExpiresAt: time.Now().Add(ttl),
+	}, nil
+}
+
+// Claims the post or extends the moderator's claim
+func (s *postService) ClaimPost(ctx context.Context, postID int64, moderatorID uuid.UUID) (*model.ModerationClaim, error) {
+	if _, err := s.repo.Postgres.Post.FindAnyByID(ctx, postID); err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrPostNotFound
+		}
+
+		s.logger.Sugar().Errorf("failed to find post(%d) from postgres: %s", postID, err.Error())
+		return nil, ErrInternal
+	}
+
+	claim, err := s.tryClaimPost(ctx, postID, moderatorID)
+	if err != nil {
+		return nil, err
+	}
+	if claim == nil {
+		return nil, ErrPostClaimedByAnotherModerator
+	}
+
+	return claim, nil
+}
+
+func (s *postService) ReleasePostClaim(ctx context.Context, postID int64, moderatorID uuid.UUID) error {
+	released, err := releasePostClaimScript.Run(ctx, s.rdb, []string{redisrepo.ModerationClaimKey(postID)}, moderatorID.String()).Int()
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to release moderator(%s) claim on post(%d) in redis: %s", moderatorID.String(), postID, err.Error())
+		return ErrInternal
+	}
+	if released == 0 {
+		return ErrPostClaimedByAnotherModerator
+	}
+
+	return nil
+}
+
+// Claims the oldest post in the moderation queue that isn't claimed by another moderator
+// and isn't approved by this one yet, then the oldest pending edit of an unclaimed post.
+// Post already claimed by the moderator is handed again
+func (s *postService) AssignNextPost(ctx context.Context, moderatorID uuid.UUID) (*dto.ModerationAssignment, error) {
+	var cursor *model.Cursor
+	for page := 0; page < MODERATION_ASSIGNMENT_MAX_PAGES; page++ {
+		posts, next, err := s.repo.Postgres.Post.FindNotValidatedPosts(ctx, &moderatorID, cursor, MODERATION_ASSIGNMENT_PAGE_SIZE)
+		if err != nil {
+			s.logger.Sugar().Errorf("failed to find not validated posts from postgres: %s", err.Error())
+			return nil, ErrInternal
+		}
+
+		for _, post := range posts {
+			claim, err := s.tryClaimPost(ctx, post.Post.ID, moderatorID)
+			if err != nil {
+				return nil, err
+			}
+			if claim != nil {
+				return &dto.ModerationAssignment{
+					Post: post,
+					Claim: *claim,
+				}, nil
+			}
+		}
+
+		if next == nil {
+			break
+		}
+		cursor = next
+	}
+
+	return s.assignNextPendingEdit(ctx, moderatorID)
+}
+
+func (s *postService) assignNextPendingEdit(ctx context.Context, moderatorID uuid.UUID) (*dto.ModerationAssignment, error) {
+	var cursor *model.Cursor
+	for page := 0; page < MODERATION_ASSIGNMENT_MAX_PAGES; page++ {
+		revisions, next, err := s.repo.Postgres.PostRevision.FindPendingRevisions(ctx, cursor, MODERATION_ASSIGNMENT_PAGE_SIZE)
+		if err != nil {
+			s.logger.Sugar().Errorf("failed to find pending revisions from postgres: %s", err.Error())
+			return nil, ErrInternal
+		}
+
+		for _, revision := range revisions {
+			claim, err := s.tryClaimPost(ctx, revision.PostID, moderatorID)
+			if err != nil {
+				return nil, err
+			}
+			if claim == nil {
+				continue
+			}
+
+			pendingEdit, err := s.pendingEdit(ctx, revision)
+			if err != nil {
+				return nil, err
+			}
+			if pendingEdit == nil {
+				if err := s.ReleasePostClaim(ctx, revision.PostID, moderatorID); err != nil {
+					s.logger.Sugar().Errorf("failed to release post(%d) claim: %s", revision.PostID, err.Error())
+				}
+				continue
+			}
+
+			return &dto.ModerationAssignment{
+				PendingEdit: pendingEdit,
+				Claim: *claim,
+			}, nil
+		}
+
+		if next == nil {
+			break
+		}
+		cursor = next
+	}
+
+	return nil, ErrNoPostsToModerate
+}
+
+// Rejects decisions on posts claimed by another moderator, unclaimed posts can be decided on by anyone
+func (s *postService) checkPostClaim(ctx context.Context, postID int64, moderatorID uuid.UUID) error {
+	owner, err := s.rdb.Get(ctx, redisrepo.ModerationClaimKey(postID)).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil
+		}
+
+		s.logger.Sugar().Errorf("failed to get post(%d) moderation claim from redis: %s", postID, err.Error())
+		return ErrInternal
+	}
+
+	if owner != moderatorID.String() {
+		return ErrPostClaimedByAnotherModerator
+	}
+
+	return nil
+}
+
+// Atomically checks that no other moderator holds the post's lease and holds it for the moderator,
+// so another moderator can't claim the post while the decision is being committed
+func (s *postService) holdPostClaim(ctx context.Context, postID int64, moderatorID uuid.UUID) error {
+	claim, err := s.tryClaimPost(ctx, postID, moderatorID)
+	if err != nil {
+		return err
+	}
+	if claim == nil {
+		return ErrPostClaimedByAnotherModerator
+	}
+
+	return nil
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/BloggingApp/post-service/internal/repository/redisrepo"
+	"github.com/go-co-op/gocron/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	RELATED_POSTS_LIMIT = 10
+	RELATED_POSTS_RECOMPUTE_TIMEOUT = time.Hour
+	// Related posts of unchanged posts are still refreshed once in a while, since posts around them change
+	RELATED_POSTS_MAX_AGE = time.Hour * 24
+	RELATED_POSTS_REFRESH_BATCH = 1000
+	// Cached related posts outlive several failed refreshes
+	RELATED_POSTS_TTL = RELATED_POSTS_MAX_AGE * 7
+	RELATED_POSTS_DEFAULT_MAX_CANDIDATES = 200
+	RELATED_POSTS_DEFAULT_MAX_CO_LIKERS = 100
+	RELATED_POSTS_DEFAULT_TAGS_WEIGHT = 3.0
+	RELATED_POSTS_DEFAULT_AUTHOR_WEIGHT = 1.0
+	RELATED_POSTS_DEFAULT_CO_LIKES_WEIGHT = 2.0
+	RELATED_POSTS_DEFAULT_TEXT_WEIGHT = 10.0
+)
+
+func relatedPostsWeights() model.RelatedPostsWeights {
+	return model.RelatedPostsWeights{
+		Tags: floatParam("related.weights.tags", RELATED_POSTS_DEFAULT_TAGS_WEIGHT),
+		Author: floatParam("related.weights.author", RELATED_POSTS_DEFAULT_AUTHOR_WEIGHT),
+		CoLikes: floatParam("related.weights.co-likes", RELATED_POSTS_DEFAULT_CO_LIKES_WEIGHT),
+		Text: floatParam("related.weights.text", RELATED_POSTS_DEFAULT_TEXT_WEIGHT),
+	}
+}
+
+// Related posts are only computed by the scheduled job. Posts that weren't computed yet
+// have no related posts and are queued for the next run
+func (s *postService) FindRelated(ctx context.Context, postID int64) ([]*model.FullPost, error) {
+	ids, err := redisrepo.Get[[]int64](s.rdb, ctx, redisrepo.RelatedPostsKey(postID))
+	if err != nil && err != redis.Nil {
+		s.logger.Sugar().Errorf("failed to get post(%d) related posts from redis: %s", postID, err.Error())
+		return nil, ErrInternal
+	}
+	if ids == nil {
+		post, err := s.repo.Postgres.Post.FindByID(ctx, postID)
+		if err != nil {
+			s.logger.Sugar().Errorf("failed to find post(%d) from postgres: %s", postID, err.Error())
+			return nil, ErrInternal
+		}
+		if post == nil {
+			return nil, ErrPostNotFound
+		}
+
+		s.markRelatedPostsStale(ctx, postID)
+		return []*model.FullPost{}, nil
+	}
+
+	if len(*ids) == 0 {
+		return []*model.FullPost{}, nil
+	}
+
+	posts, err := s.repo.Postgres.Post.FindByIDs(ctx, *ids)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find post(%d) related posts from postgres: %s", postID, err.Error())
+		return nil, ErrInternal
+	}
+
+	return posts, nil
+}
+
+// Queues the post's related posts to be recomputed on the next run
+func (s *postService) markRelatedPostsStale(ctx context.Context, postID int64) {
+	if err := s.rdb.SAdd(ctx, redisrepo.RELATED_POSTS_STALE_KEY, postID).Err(); err != nil {
+		s.logger.Sugar().Errorf("failed to mark post(%d) related posts stale in redis: %s", postID, err.Error())
+	}
+}
+
+func (s *postService) computeRelated(ctx context.Context, postID int64, computedAt time.Time) error {
+	ids, err := s.repo.Postgres.Post.FindRelatedIDs(
+		ctx,
+		postID,
+		relatedPostsWeights(),
+		RELATED_POSTS_LIMIT,
+		int(floatParam("related.max-candidates", RELATED_POSTS_DEFAULT_MAX_CANDIDATES)),
+		int(floatParam("related.max-co-likers", RELATED_POSTS_DEFAULT_MAX_CO_LIKERS)),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to find post(%d) related posts: %s", postID, err.Error())
+	}
+
+	if err := redisrepo.SetJSON(s.rdb, ctx, redisrepo.RelatedPostsKey(postID), ids, RELATED_POSTS_TTL); err != nil {
+		return fmt.Errorf("failed to set post(%d) related posts in redis: %s", postID, err.Error())
+	}
+
+	if err := s.rdb.ZAdd(ctx, redisrepo.RELATED_POSTS_COMPUTED_KEY, redis.Z{Score: float64(computedAt.Unix()), Member: postID}).Err(); err != nil {
+		return fmt.Errorf("failed to save post(%d) related posts computation time in redis: %s", postID, err.Error())
+	}
+
+	return nil
+}
+
+// Recomputes posts that changed since the previous run, posts marked stale and a batch of the longest
+// not refreshed posts. The first run computes every visible post
+func (s *postService) recomputeRelatedPosts(ctx context.Context) error {
+	now := time.Now()
+
+	var since time.Time
+	recomputedAt, err := s.rdb.Get(ctx, redisrepo.RELATED_POSTS_RECOMPUTED_AT_KEY).Int64()
+	if err != nil && err != redis.Nil {
+		return fmt.Errorf("failed to get related posts recomputation time from redis: %s", err.Error())
+	}
+	if err == nil {
+		since = time.Unix(recomputedAt, 0)
+	}
+
+	staleMembers, err := s.rdb.SMembers(ctx, redisrepo.RELATED_POSTS_STALE_KEY).Result()
+	if err != nil {
+		return fmt.Errorf("failed to get stale related posts from redis: %s", err.Error())
+	}
+
+	agedMembers, err := s.rdb.ZRangeByScore(ctx, redisrepo.RELATED_POSTS_COMPUTED_KEY, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(now.Add(-RELATED_POSTS_MAX_AGE).Unix(), 10),
+		Count: RELATED_POSTS_REFRESH_BATCH,
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("failed to get aged related posts from redis: %s", err.Error())
+	}
+
+	queued := []int64{}
+	for _, member := range append(staleMembers, agedMembers...) {
+		if id, err := strconv.ParseInt(member, 10, 64); err == nil {
+			queued = append(queued, id)
+		}
+	}
+
+	ids, err := s.repo.Postgres.Post.FindRelatedRecomputeIDs(ctx, since, queued)
+	if err != nil {
+		return fmt.Errorf("failed to find posts to recompute related posts: %s", err.Error())
+	}
+
+	visible := make(map[int64]struct{}, len(ids))
+	var failed []any
+	for _, id := range ids {
+		visible[id] = struct{}{}
+		// One failed post shouldn't stop the others, it's retried on the next run
+		if err := s.computeRelated(ctx, id, now); err != nil {
+			s.logger.Sugar().Error(err.Error())
+			failed = append(failed, id)
+		}
+	}
+
+	// Queued posts that aren't visible anymore aren't refreshed again
+	var invisible []any
+	for _, id := range queued {
+		if _, ok := visible[id]; !ok {
+			invisible = append(invisible, id)
+		}
+	}
+
+	var processed []any
+	for _, member := range staleMembers {
+		processed = append(processed, member)
+	}
+
+	pipe := s.rdb.TxPipeline()
+	if len(processed) != 0 {
+		pipe.SRem(ctx, redisrepo.RELATED_POSTS_STALE_KEY, processed...)
+	}
+	if len(failed) != 0 {
+		pipe.SAdd(ctx, redisrepo.RELATED_POSTS_STALE_KEY, failed...)
+	}
+	if len(invisible) != 0 {
+		pipe.ZRem(ctx, redisrepo.RELATED_POSTS_COMPUTED_KEY, invisible...)
+	}
+	pipe.Set(ctx, redisrepo.RELATED_POSTS_RECOMPUTED_AT_KEY, now.Unix(), 0)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save related posts recomputation state in redis: %s", err.Error())
+	}
+
+	return nil
+}
+func (s *postService) ScheduleRelatedPostsRecompute() {
+	s.scheduler.NewJob(
+		gocron.DurationJob(RELATED_POSTS_RECOMPUTE_TIMEOUT),
+		gocron.NewTask(func(ctx context.Context) {
+			if err := s.recomputeRelatedPosts(ctx); err != nil {
+				s.logger.Sugar().Error(err.Error())
+			}
+		}),
+		gocron.WithStartAt(gocron.WithStartImmediately()),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule),
+	)
+}
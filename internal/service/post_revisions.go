@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"github.com/BloggingApp/post-service/internal/dto"
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *postService) FindRevisions(ctx context.Context, postID int64, authorID uuid.UUID, cursor string, limit int) (*dto.Page[*model.PostRevision], error) {
+	limit = pageSize(limit, PAGE_POSTS)
+	after, err := dto.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	revisions, next, err := s.repo.Postgres.PostRevision.FindPostRevisions(ctx, postID, authorID, after, limit)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find post(%d) revisions from postgres: %s", postID, err.Error())
+		return nil, ErrInternal
+	}
+
+	return dto.NewPage(revisions, next), nil
+}
+
+func (s *postService) FindRevision(ctx context.Context, postID, revisionID int64, authorID uuid.UUID) (*model.PostRevision, error) {
+	revision, err := s.repo.Postgres.PostRevision.FindByID(ctx, postID, revisionID, authorID)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrRevisionNotFound
+		}
+
+		s.logger.Sugar().Errorf("failed to find post(%d) revision(%d) from postgres: %s", postID, revisionID, err.Error())
+		return nil, ErrInternal
+	}
+
+	return revision, nil
+}
+
+func (s *postService) DiffRevisions(ctx context.Context, postID int64, authorID uuid.UUID, fromRevisionID, toRevisionID int64) (*dto.RevisionDiff, error) {
+	from, err := s.FindRevision(ctx, postID, fromRevisionID, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := s.FindRevision(ctx, postID, toRevisionID, authorID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.RevisionDiff{
+		FromRevisionID: from.ID,
+		ToRevisionID: to.ID,
+		PostDiff: dto.PostDiff{
+			Title: diffLines(from.Title, to.Title),
+			FeedView: diffLines(from.FeedView, to.FeedView),
+			Content: diffLines(from.Content, to.Content),
+			Tags: diffLines(strings.Join(from.Tags, "\n"), strings.Join(to.Tags, "\n")),
+		},
+	}, nil
+}
+
+// Rollback is an edit to the revision's version, so it's recorded as a new revision
+// and goes through moderation like any other edit
+func (s *postService) Rollback(ctx context.Context, postID, revisionID int64, authorID uuid.UUID) (bool, error) {
+	revision, err := s.FindRevision(ctx, postID, revisionID, authorID)
+	if err != nil {
+		return false, err
+	}
+
+	input := dto.EditPostRequest{
+		PostID: postID,
+		AuthorID: authorID,
+		Title: &revision.Title,
+		Content: &revision.Content,
+		FeedView: &revision.FeedView,
+	}
+	if revision.Tags != nil {
+		input.Tags = &revision.Tags
+	}
+
+	return s.Edit(ctx, input)
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/BloggingApp/post-service/internal/dto"
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/go-co-op/gocron/v2"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *postService) FindAuthorScheduledPosts(ctx context.Context, authorID uuid.UUID, cursor string, limit int) (*dto.Page[*model.AuthorPost], error) {
+	limit = pageSize(limit, PAGE_POSTS)
+	after, err := dto.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	posts, next, err := s.repo.Postgres.Post.FindAuthorScheduledPosts(ctx, authorID, after, limit)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find user(%s)'s scheduled posts from postgres: %s", authorID.String(), err.Error())
+		return nil, ErrInternal
+	}
+
+	return dto.NewPage(posts, next), nil
+}
+
+func (s *postService) Reschedule(ctx context.Context, id int64, authorID uuid.UUID, publishAt time.Time) error {
+	if !publishAt.After(time.Now()) {
+		return ErrPublishAtMustBeInFuture
+	}
+
+	if err := s.repo.Postgres.Post.Reschedule(ctx, id, authorID, publishAt); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrPostIsNotScheduled
+		}
+
+		s.logger.Sugar().Errorf("failed to reschedule user(%s)'s post(%d): %s", authorID.String(), id, err.Error())
+		return ErrInternal
+	}
+
+	return nil
+}
+
+// Cancelled posts are moved back to drafts
+func (s *postService) CancelSchedule(ctx context.Context, id int64, authorID uuid.UUID) error {
+	if err := s.repo.Postgres.Post.CancelSchedule(ctx, id, authorID); err != nil {
+		if err == pgx.ErrNoRows {
+			return ErrPostIsNotScheduled
+		}
+
+		s.logger.Sugar().Errorf("failed to cancel user(%s)'s post(%d) schedule: %s", authorID.String(), id, err.Error())
+		return ErrInternal
+	}
+
+	return nil
+}
+
+func (s *postService) publishDuePosts(ctx context.Context) error {
+	posts, err := s.repo.Postgres.Post.PublishDue(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to publish due scheduled posts: %s", err.Error())
+	}
+
+	for _, post := range posts {
+		// Post is already live, so other posts still have to be notified if this one fails
+		if err := s.publishPostCreatedWithRetries(post); err != nil {
+			s.logger.Sugar().Errorf("scheduled post(%d) is published but its followers weren't notified: %s", post.ID, err.Error())
+		}
+		s.savePostMentions(ctx, post.ID)
+
+		if post.Validated {
+			s.postBecameVisible(ctx, post)
+		}
+	}
+
+	return nil
+}
+
+func (s *postService) publishPostCreatedWithRetries(post *model.Post) error {
+	var err error
+	for attempt := 0; attempt < SCHEDULED_POST_NOTIFICATION_ATTEMPTS; attempt++ {
+		if attempt > 0 {
+			time.Sleep(SCHEDULED_POST_NOTIFICATION_RETRY_DELAY * time.Duration(attempt))
+		}
+		if err = s.publishPostCreated(post); err == nil {
+			return nil
+		}
+	}
+	return err
+}
+
+func (s *postService) SchedulePostsPublishing() {
+	s.scheduler.NewJob(gocron.DurationJob(SCHEDULED_POSTS_PUBLISH_TIMEOUT), gocron.NewTask(func(ctx context.Context) {
+		if err := s.publishDuePosts(ctx); err != nil {
+			s.logger.Sugar().Error(err.Error())
+		}
+	}))
+}
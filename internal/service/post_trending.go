@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/BloggingApp/post-service/internal/dto"
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/BloggingApp/post-service/internal/repository/redisrepo"
+	"github.com/go-co-op/gocron/v2"
+	"github.com/redis/go-redis/v9"
+)
+
+// Trending score is (likes*w + views*w + comments*w) / (age in hours + 2)^gravity,
+// it's precomputed for every window into redis sorted sets of post IDs, overall and per tag
+const (
+	TRENDING_RECOMPUTE_TIMEOUT = time.Minute * 5
+	// Sets outlive a few failed recomputes, but don't stay forever if the job stops
+	TRENDING_TTL = time.Hour
+	TRENDING_DEFAULT_WINDOW = "24h"
+	TRENDING_DEFAULT_GRAVITY = 1.8
+	TRENDING_DEFAULT_LIKES_WEIGHT = 1.0
+	TRENDING_DEFAULT_VIEWS_WEIGHT = 0.05
+	TRENDING_DEFAULT_COMMENTS_WEIGHT = 2.0
+)
+
+var TRENDING_WINDOWS = map[string]time.Duration{
+	"1h": time.Hour,
+	"24h": time.Hour * 24,
+	"7d": time.Hour * 24 * 7,
+}
+
+func trendingScore(engagement *model.PostEngagement, now time.Time) float64 {
+	points := float64(engagement.Likes) * floatParam("trending.weights.likes", TRENDING_DEFAULT_LIKES_WEIGHT) +
+		float64(engagement.Views) * floatParam("trending.weights.views", TRENDING_DEFAULT_VIEWS_WEIGHT) +
+		float64(engagement.Comments) * floatParam("trending.weights.comments", TRENDING_DEFAULT_COMMENTS_WEIGHT)
+	age := math.Max(now.Sub(engagement.CreatedAt).Hours(), 0)
+
+	return points / math.Pow(age + 2, floatParam("trending.gravity", TRENDING_DEFAULT_GRAVITY))
+}
+
+// Returns posts of the window ordered by trending score, tag is optional
+func (s *postService) FindTrending(ctx context.Context, window, tag string, cursor string, limit int) (*dto.Page[*model.FullPost], error) {
+	limit = pageSize(limit, PAGE_POSTS)
+	after, err := dto.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	if window == "" {
+		window = TRENDING_DEFAULT_WINDOW
+	}
+	if _, ok := TRENDING_WINDOWS[window]; !ok {
+		return nil, ErrInvalidTrendingWindow
+	}
+
+	key := redisrepo.TrendingPostsKey(window)
+	if tag != "" {
+		tags, err := s.canonicalQueryTags(ctx, []string{tag})
+		if err != nil {
+			return nil, err
+		}
+		if len(tags) == 0 {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidTag, tag)
+		}
+		key = redisrepo.TrendingTagPostsKey(window, tags[0])
+	}
+
+	entries, err := s.trendingEntries(ctx, key, after, limit + 1)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to get trending posts(%s) from redis: %s", key, err.Error())
+		return nil, ErrInternal
+	}
+
+	var next *model.Cursor
+	if len(entries) > limit {
+		entries = entries[:limit]
+		last := entries[limit-1]
+		lastID, _ := strconv.ParseInt(last.Member.(string), 10, 64)
+		next = &model.Cursor{Score: last.Score, ID: lastID}
+	}
+
+	ids := []int64{}
+	for _, entry := range entries {
+		id, err := strconv.ParseInt(entry.Member.(string), 10, 64)
+		if err != nil {
+			continue
+		}
+		ids = append(ids, id)
+	}
+
+	posts := []*model.FullPost{}
+	if len(ids) != 0 {
+		posts, err = s.repo.Postgres.Post.FindByIDs(ctx, ids)
+		if err != nil {
+			s.logger.Sugar().Errorf("failed to find trending posts(%s) from postgres: %s", key, err.Error())
+			return nil, ErrInternal
+		}
+	}
+
+	return dto.NewPage(posts, next), nil
+}
+
+// Returns up to count set entries after the cursor. Members with equal scores
+// are ordered by member in reverse, the same way ZREVRANGE does
+func (s *postService) trendingEntries(ctx context.Context, key string, after *model.Cursor, count int) ([]redis.Z, error) {
+	if after == nil {
+		return s.rdb.ZRevRangeWithScores(ctx, key, 0, int64(count - 1)).Result()
+	}
+
+	score := strconv.FormatFloat(after.Score, 'g', -1, 64)
+	ties, err := s.rdb.ZCount(ctx, key, score, score).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.rdb.ZRevRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: score,
+		Count: int64(count) + ties,
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	member := strconv.FormatInt(after.ID, 10)
+	result := []redis.Z{}
+	for _, entry := range entries {
+		if entry.Score == after.Score && entry.Member.(string) >= member {
+			continue
+		}
+		result = append(result, entry)
+		if len(result) == count {
+			break
+		}
+	}
+
+	return result, nil
+}
+
+func (s *postService) recomputeTrending(ctx context.Context) error {
+	var longest time.Duration
+	for _, duration := range TRENDING_WINDOWS {
+		longest = max(longest, duration)
+	}
+
+	now := time.Now()
+	engagements, err := s.repo.Postgres.Post.FindEngagementSince(ctx, now.Add(-longest))
+	if err != nil {
+		return fmt.Errorf("failed to find posts engagement for trending: %s", err.Error())
+	}
+
+	scores := make(map[int64]float64, len(engagements))
+	for _, engagement := range engagements {
+		scores[engagement.PostID] = trendingScore(engagement, now)
+	}
+
+	for window, duration := range TRENDING_WINDOWS {
+		since := now.Add(-duration)
+
+		sets := make(map[string][]redis.Z)
+		for _, engagement := range engagements {
+			if engagement.CreatedAt.Before(since) {
+				continue
+			}
+
+			entry := redis.Z{Score: scores[engagement.PostID], Member: strconv.FormatInt(engagement.PostID, 10)}
+			key := redisrepo.TrendingPostsKey(window)
+			sets[key] = append(sets[key], entry)
+			for _, tag := range engagement.Tags {
+				tagKey := redisrepo.TrendingTagPostsKey(window, tag)
+				sets[tagKey] = append(sets[tagKey], entry)
+			}
+		}
+
+		staleKeys, err := s.rdb.Keys(ctx, redisrepo.TrendingTagPostsKeyPattern(window)).Result()
+		if err != nil && err != redis.Nil {
+			return fmt.Errorf("failed to get trending keys of window(%s) from redis: %s", window, err.Error())
+		}
+
+		// Readers see either the old or the new sets
+		pipe := s.rdb.TxPipeline()
+		pipe.Del(ctx, append(staleKeys, redisrepo.TrendingPostsKey(window))...)
+		for key, entries := range sets {
+			pipe.ZAdd(ctx, key, entries...)
+			pipe.Expire(ctx, key, TRENDING_TTL)
+		}
+		if _, err := pipe.Exec(ctx); err != nil {
+			return fmt.Errorf("failed to set trending posts of window(%s) in redis: %s", window, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (s *postService) ScheduleTrendingRecompute() {
+	s.scheduler.NewJob(
+		gocron.DurationJob(TRENDING_RECOMPUTE_TIMEOUT),
+		gocron.NewTask(func(ctx context.Context) {
+			if err := s.recomputeTrending(ctx); err != nil {
+				s.logger.Sugar().Error(err.Error())
+			}
+		}),
+		gocron.WithStartAt(gocron.WithStartImmediately()),
+	)
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/BloggingApp/post-service/internal/repository"
+	"github.com/BloggingApp/post-service/internal/repository/postgres"
+	"github.com/BloggingApp/post-service/internal/repository/redisrepo"
+	"github.com/go-co-op/gocron/v2"
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// Reactions counts are changed in redis and flushed to postgres in batches, like likes
+const REACTIONS_FLUSH_TIMEOUT = time.Minute * 2
+
+var REACTION_DEFAULT_TYPES = []string{"clap", "insightful", "funny"}
+
+// Returns reaction types from "reactions.types" config
+func reactionTypes() []string {
+	if types := viper.GetStringSlice("reactions.types"); len(types) != 0 {
+		return types
+	}
+	return REACTION_DEFAULT_TYPES
+}
+
+type reactionService struct {
+	logger *zap.Logger
+	repo *repository.Repository
+	rdb *redis.Client
+	scheduler gocron.Scheduler
+}
+
+func newReactionService(logger *zap.Logger, repo *repository.Repository, rdb *redis.Client) Reaction {
+	scheduler, err := gocron.NewScheduler()
+	if err != nil {
+		panic(err)
+	}
+
+	return &reactionService{
+		logger: logger,
+		repo: repo,
+		rdb: rdb,
+		scheduler: scheduler,
+	}
+}
+
+func (s *reactionService) ReactionTypes() []string {
+	return reactionTypes()
+}
+
+// Sets user's reaction to the target, the previous reaction of the user is replaced
+func (s *reactionService) React(ctx context.Context, target string, targetID int64, userID uuid.UUID, reactionType string) error {
+	if !slices.Contains(reactionTypes(), reactionType) {
+		return fmt.Errorf("%w: %s", ErrInvalidReactionType, reactionType)
+	}
+
+	previous, err := s.repo.Postgres.Reaction.React(ctx, target, targetID, userID, reactionType)
+	if err != nil {
+		if errors.Is(err, postgres.ErrReactionTargetIsNotVisible) {
+			return ErrFailedToReact
+		}
+		s.logger.Sugar().Errorf("failed to set user(%s) reaction to %s(%d): %s", userID.String(), target, targetID, err.Error())
+		return ErrInternal
+	}
+
+	return s.updateCachedReactions(ctx, target, targetID, userID, previous, reactionType)
+}
+
+func (s *reactionService) Unreact(ctx context.Context, target string, targetID int64, userID uuid.UUID) error {
+	previous, err := s.repo.Postgres.Reaction.Unreact(ctx, target, targetID, userID)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to delete user(%s) reaction to %s(%d): %s", userID.String(), target, targetID, err.Error())
+		return ErrInternal
+	}
+	if previous == "" {
+		return ErrFailedToReact
+	}
+
+	return s.updateCachedReactions(ctx, target, targetID, userID, previous, "")
+}
+
+// Updates user's reaction cache and counts deltas, empty current means the reaction was removed
+func (s *reactionService) updateCachedReactions(ctx context.Context, target string, targetID int64, userID uuid.UUID, previous, current string) error {
+	if err := s.rdb.Set(ctx, redisrepo.UserReactionKey(userID.String(), target, targetID), current, time.Minute).Err(); err != nil {
+		s.logger.Sugar().Errorf("failed to set user(%s) reaction to %s(%d) in redis: %s", userID.String(), target, targetID, err.Error())
+		return ErrInternal
+	}
+
+	if previous == current {
+		return nil
+	}
+
+	key := redisrepo.ReactionsKey(target, targetID)
+	pipe := s.rdb.Pipeline()
+	if previous != "" {
+		pipe.HIncrBy(ctx, key, previous, -1)
+	}
+	if current != "" {
+		pipe.HIncrBy(ctx, key, current, 1)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		s.logger.Sugar().Errorf("failed to increment key(%s) in redis: %s", key, err.Error())
+		return ErrInternal
+	}
+
+	return nil
+}
+
+// Returns user's reaction to the target, nil if there is none
+func (s *reactionService) FindMyReaction(ctx context.Context, target string, targetID int64, userID uuid.UUID) *string {
+	key := redisrepo.UserReactionKey(userID.String(), target, targetID)
+	cachedReaction, err := s.rdb.Get(ctx, key).Result()
+	if err == nil {
+		if cachedReaction == "" {
+			return nil
+		}
+		return &cachedReaction
+	}
+	if err != redis.Nil {
+		s.logger.Sugar().Errorf("failed to get user(%s) reaction to %s(%d) from redis: %s", userID.String(), target, targetID, err.Error())
+		return nil
+	}
+
+	reactions, err := s.repo.Postgres.Reaction.FindUserReactions(ctx, target, []int64{targetID}, userID)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find user(%s) reaction to %s(%d) from postgres: %s", userID.String(), target, targetID, err.Error())
+		return nil
+	}
+	reaction := reactions[targetID]
+
+	if err := s.rdb.Set(ctx, key, reaction, time.Minute).Err(); err != nil {
+		s.logger.Sugar().Errorf("failed to set user(%s) reaction to %s(%d) in redis: %s", userID.String(), target, targetID, err.Error())
+	}
+
+	if reaction == "" {
+		return nil
+	}
+	return &reaction
+}
+
+// Sets MyReaction of the comments to user's reactions
+func (s *reactionService) SetMyCommentReactions(ctx context.Context, comments []*model.FullComment, userID uuid.UUID) {
+	if len(comments) == 0 {
+		return
+	}
+
+	ids := make([]int64, len(comments))
+	for i, comment := range comments {
+		ids[i] = comment.Comment.ID
+	}
+
+	reactions, err := s.repo.Postgres.Reaction.FindUserReactions(ctx, model.REACTION_TARGET_COMMENT, ids, userID)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find user(%s) reactions to comments from postgres: %s", userID.String(), err.Error())
+		return
+	}
+
+	for _, comment := range comments {
+		if reaction, ok := reactions[comment.Comment.ID]; ok {
+			comment.MyReaction = &reaction
+		}
+	}
+}
+
+func (s *reactionService) batchReactionsFlush(ctx context.Context, target string) error {
+	pattern := redisrepo.ReactionsKeyPattern(target)
+	keys, err := s.rdb.Keys(ctx, pattern).Result()
+	if err != nil && err != redis.Nil {
+		return fmt.Errorf("failed to get keys with pattern(%s) from redis: %s", pattern, err.Error())
+	}
+	if err == redis.Nil || len(keys) == 0 {
+		return nil
+	}
+
+	for _, key := range keys {
+		targetID, err := redisrepo.GetTargetIDFromReactionsKey(key)
+		if err != nil {
+			continue
+		}
+
+		// Deltas are taken atomically, so increments made during the flush stay for the next one
+		pipe := s.rdb.TxPipeline()
+		getCmd := pipe.HGetAll(ctx, key)
+		pipe.Del(ctx, key)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return fmt.Errorf("failed to get %s(%d) cached reactions from redis: %s", target, targetID, err.Error())
+		}
+
+		deltas := model.ReactionCounts{}
+		for reactionType, value := range getCmd.Val() {
+			delta, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || delta == 0 {
+				continue
+			}
+			deltas[reactionType] = delta
+		}
+		if len(deltas) == 0 {
+			continue
+		}
+
+		if err := s.repo.Postgres.Reaction.IncrReactionsBy(ctx, target, targetID, deltas); err != nil {
+			// Returning deltas to redis to flush them next time
+			restorePipe := s.rdb.Pipeline()
+			for reactionType, delta := range deltas {
+				restorePipe.HIncrBy(ctx, key, reactionType, delta)
+			}
+			if _, err := restorePipe.Exec(ctx); err != nil {
+				s.logger.Sugar().Errorf("failed to restore %s(%d) cached reactions in redis: %s", target, targetID, err.Error())
+			}
+			return fmt.Errorf("failed to incr %s(%d) reactions: %s", target, targetID, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (s *reactionService) ScheduleReactionsFlush() {
+	s.scheduler.NewJob(gocron.DurationJob(REACTIONS_FLUSH_TIMEOUT), gocron.NewTask(func(ctx context.Context) {
+		for _, target := range []string{model.REACTION_TARGET_POST, model.REACTION_TARGET_COMMENT} {
+			if err := s.batchReactionsFlush(ctx, target); err != nil {
+				s.logger.Sugar().Error(err.Error())
+			}
+		}
+	}))
+}
+
+func (s *reactionService) StartScheduledJobs() {
+	s.ScheduleReactionsFlush()
+
+	s.scheduler.Start()
+}
@@ -0,0 +1,181 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+
+	"github.com/BloggingApp/post-service/internal/dto"
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/BloggingApp/post-service/internal/rabbitmq"
+	"github.com/BloggingApp/post-service/internal/repository"
+	"github.com/BloggingApp/post-service/internal/repository/postgres"
+	"github.com/BloggingApp/post-service/internal/repository/redisrepo"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+type reportService struct {
+	logger *zap.Logger
+	repo *repository.Repository
+	rdb *redis.Client
+	rabbitmq *rabbitmq.MQConn
+	comments Comment
+}
+
+func newReportService(logger *zap.Logger, repo *repository.Repository, rdb *redis.Client, rabbitmq *rabbitmq.MQConn, comments Comment) Report {
+	return &reportService{
+		logger: logger,
+		repo: repo,
+		rdb: rdb,
+		rabbitmq: rabbitmq,
+		comments: comments,
+	}
+}
+
+func (s *reportService) ReportReasons() []string {
+	return model.REPORT_REASONS
+}
+
+// Reports the post or comment, repeated reports of the same user are ignored until their report is resolved.
+// Targets with "reports.hide-threshold" open reports are hidden until a moderator resolves them
+func (s *reportService) Report(ctx context.Context, target string, targetID int64, reporterID uuid.UUID, req dto.ReportRequest) error {
+	if !slices.Contains(model.REPORT_REASONS, req.Reason) {
+		return ErrInvalidReportReason
+	}
+
+	report, hidden, err := s.repo.Postgres.Report.Create(ctx, model.Report{
+		Target: target,
+		TargetID: targetID,
+		ReporterID: reporterID,
+		Reason: req.Reason,
+		Details: req.Details,
+	}, viper.GetInt("reports.hide-threshold"))
+	if err != nil {
+		if errors.Is(err, postgres.ErrReportTargetIsNotVisible) {
+			return ErrFailedToReport
+		}
+
+		s.logger.Sugar().Errorf("failed to report %s(%d) by user(%s): %s", target, targetID, reporterID.String(), err.Error())
+		return ErrInternal
+	}
+
+	if hidden {
+		s.deleteTargetCache(ctx, target, targetID, report.PostID)
+	}
+
+	return nil
+}
+
+func (s *reportService) deleteTargetCache(ctx context.Context, target string, targetID, postID int64) {
+	if target == model.REPORT_TARGET_COMMENT {
+		s.comments.deletePostCommentsCache(ctx, postID)
+		return
+	}
+
+	if err := s.rdb.Del(ctx, redisrepo.PostKey(targetID)).Err(); err != nil {
+		s.logger.Sugar().Errorf("failed to delete post(%d) from redis: %s", targetID, err.Error())
+	}
+}
+
+func isReportTarget(target string) bool {
+	return target == model.REPORT_TARGET_POST || target == model.REPORT_TARGET_COMMENT
+}
+
+// Target is model.REPORT_TARGET_* or empty for all targets
+func (s *reportService) FindQueue(ctx context.Context, target, cursor string, limit int) (*dto.Page[*model.ReportedTarget], error) {
+	if target != "" && !isReportTarget(target) {
+		return nil, ErrInvalidReportTarget
+	}
+
+	limit = pageSize(limit, PAGE_MODERATION)
+	after, err := dto.DecodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	targets, next, err := s.repo.Postgres.Report.FindQueue(ctx, target, after, limit)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find reported targets: %s", err.Error())
+		return nil, ErrInternal
+	}
+
+	return dto.NewPage(targets, next), nil
+}
+
+func (s *reportService) FindTargetReports(ctx context.Context, target string, targetID int64) ([]*model.Report, error) {
+	if !isReportTarget(target) {
+		return nil, ErrInvalidReportTarget
+	}
+
+	reports, err := s.repo.Postgres.Report.FindTargetReports(ctx, target, targetID)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find %s(%d) reports: %s", target, targetID, err.Error())
+		return nil, ErrInternal
+	}
+
+	return reports, nil
+}
+
+// Resolves open reports of the target and publishes the outcome for the author and the reporters
+func (s *reportService) Resolve(ctx context.Context, target string, targetID int64, moderatorID uuid.UUID, req dto.ResolveReportsRequest) (*model.ReportResolution, error) {
+	if !isReportTarget(target) {
+		return nil, ErrInvalidReportTarget
+	}
+
+	switch req.Action {
+	case model.REPORT_ACTION_DISMISS, model.REPORT_ACTION_HIDE, model.REPORT_ACTION_DELETE, model.REPORT_ACTION_WARN:
+	default:
+		return nil, ErrInvalidReportAction
+	}
+
+	resolution, err := s.repo.Postgres.Report.Resolve(ctx, target, targetID, moderatorID, req.Action, req.Note)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrReportsNotFound
+		}
+
+		s.logger.Sugar().Errorf("failed to resolve %s(%d) reports: %s", target, targetID, err.Error())
+		return nil, ErrInternal
+	}
+
+	if target == model.REPORT_TARGET_COMMENT && req.Action == model.REPORT_ACTION_DELETE {
+		// Invalidates the comments cache itself
+		if err := s.comments.Delete(ctx, resolution.PostID, targetID, moderatorID, true, req.Note); err != nil && !errors.Is(err, ErrCommentNotFound) {
+			return nil, err
+		}
+	} else {
+		s.deleteTargetCache(ctx, target, targetID, resolution.PostID)
+	}
+
+	s.publishResolution(resolution)
+
+	return resolution, nil
+}
+
+// Failed notifications are logged, the reports are already resolved
+func (s *reportService) publishResolution(resolution *model.ReportResolution) {
+	msgJSON, err := json.Marshal(dto.MQReportResolvedMsg{
+		ResolutionID: resolution.ID,
+		Target: resolution.Target,
+		TargetID: resolution.TargetID,
+		PostID: resolution.PostID,
+		AuthorID: resolution.AuthorID,
+		ModeratorID: resolution.ModeratorID,
+		Action: resolution.Action,
+		Note: resolution.Note,
+		ReporterIDs: resolution.ReporterIDs,
+		CreatedAt: resolution.CreatedAt,
+	})
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to marshal rabbitmq msg for queue(%s) to json: %s", rabbitmq.REPORT_RESOLVED_QUEUE, err.Error())
+		return
+	}
+	if err := s.rabbitmq.PublishToQueue(rabbitmq.REPORT_RESOLVED_QUEUE, msgJSON); err != nil {
+		s.logger.Sugar().Errorf("failed to publish rabbitmq msg to queue(%s): %s", rabbitmq.REPORT_RESOLVED_QUEUE, err.Error())
+	}
+}
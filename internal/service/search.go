@@ -0,0 +1,58 @@
+package service
+
+import (
+	"strings"
+	"unicode"
+)
+
+const MAX_SEARCH_QUERY_TERMS = 16
+
+// Converts a user search query to to_tsquery input.
+// Terms are ANDed, "quoted words" are matched as a phrase and term* is matched as a prefix.
+// Everything except letters and digits is dropped, so the result is always a valid tsquery
+// and can be used as a normalized cache key. Empty result means nothing to search for
+func parseSearchQuery(query string) string {
+	var terms []string
+
+	rest := strings.ToLower(query)
+	for len(terms) < MAX_SEARCH_QUERY_TERMS {
+		rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		if rest == "" {
+			break
+		}
+
+		var raw string
+		phrase := rest[0] == '"'
+		if phrase {
+			rest = rest[1:]
+			end := strings.IndexByte(rest, '"')
+			if end == -1 {
+				end = len(rest)
+			}
+			raw, rest = rest[:end], strings.TrimPrefix(rest[end:], `"`)
+		} else {
+			end := strings.IndexFunc(rest, unicode.IsSpace)
+			if end == -1 {
+				end = len(rest)
+			}
+			raw, rest = rest[:end], rest[end:]
+		}
+
+		prefix := !phrase && strings.HasSuffix(raw, "*")
+
+		words := strings.FieldsFunc(raw, func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if len(words) == 0 {
+			continue
+		}
+		if prefix {
+			words[len(words)-1] += ":*"
+		}
+
+		// Words of a phrase and parts of hyphenated terms must follow each other
+		terms = append(terms, strings.Join(words, " <-> "))
+	}
+
+	return strings.Join(terms, " & ")
+}
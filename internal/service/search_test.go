@@ -0,0 +1,37 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		expected string
+	}{
+		{name: "empty", query: "  ", expected: ""},
+		{name: "terms", query: "Hello  World", expected: "hello & world"},
+		{name: "phrase", query: `"big data" rocks`, expected: "big <-> data & rocks"},
+		{name: "unclosed phrase", query: `"big data`, expected: "big <-> data"},
+		{name: "prefix", query: "postg*", expected: "postg:*"},
+		{name: "prefix in phrase is ignored", query: `"postg*"`, expected: "postg"},
+		{name: "hyphenated term", query: "e-mail", expected: "e <-> mail"},
+		{name: "tsquery operators dropped", query: "a & !b | (c)", expected: "a & b & c"},
+		{name: "only symbols", query: "!!! ???", expected: ""},
+		{
+			name: "too many terms",
+			query: strings.Repeat("word ", MAX_SEARCH_QUERY_TERMS+4),
+			expected: strings.Repeat("word & ", MAX_SEARCH_QUERY_TERMS-1) + "word",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := parseSearchQuery(tt.query); result != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, result)
+			}
+		})
+	}
+}
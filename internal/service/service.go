@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"mime/multipart"
+	"time"
 
 	"github.com/BloggingApp/post-service/internal/dto"
 	"github.com/BloggingApp/post-service/internal/model"
@@ -10,6 +11,7 @@ import (
 	"github.com/BloggingApp/post-service/internal/repository"
 	"github.com/google/uuid"
 	"github.com/redis/go-redis/v9"
+	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
 
@@ -21,35 +23,161 @@ func maxLimit(limit *int) {
 	}
 }
 
+// Lists paginated with cursors, their max page sizes are set in "pagination.max-page-size" config
+const (
+	PAGE_POSTS = "posts"
+	PAGE_COMMENTS = "comments"
+	PAGE_SEARCH = "search"
+	PAGE_MODERATION = "moderation"
+)
+
+// Returns page size for the list, "pagination.default-page-size" is used if limit isn't set
+func pageSize(limit int, list string) int {
+	if limit <= 0 {
+		limit = viper.GetInt("pagination.default-page-size")
+	}
+	if limit <= 0 {
+		limit = MAX_LIMIT
+	}
+
+	max := viper.GetInt("pagination.max-page-size." + list)
+	if max <= 0 {
+		max = MAX_LIMIT
+	}
+	if limit > max {
+		limit = max
+	}
+
+	return limit
+}
+
+// Returns the float config value or def if it isn't set
+func floatParam(key string, def float64) float64 {
+	if viper.IsSet(key) {
+		return viper.GetFloat64(key)
+	}
+	return def
+}
+
 type Post interface {
 	UploadTempPostImage(ctx context.Context, file multipart.File, fileHeader *multipart.FileHeader) (string, error)
 	Create(ctx context.Context, authorID uuid.UUID, req dto.CreatePostRequest) (*model.Post, error)
 	FindByID(ctx context.Context, id int64) (*model.FullPost, error)
-	FindAuthorPosts(ctx context.Context, authorID uuid.UUID, limit int, offset int) ([]*model.AuthorPost, error)
-	FindUserNotValidatedPosts(ctx context.Context, userID uuid.UUID, limit, offset int) ([]*model.AuthorPost, error)
-	FindNotValidatedPosts(ctx context.Context, limit, offset int) ([]*model.FullPost, error)
-	FindUserLikes(ctx context.Context, userID uuid.UUID, limit int, offset int) ([]*model.FullPost, error)
+	FindAuthorPosts(ctx context.Context, authorID uuid.UUID, cursor string, limit int) (*dto.Page[*model.AuthorPost], error)
+	FindUserNotValidatedPosts(ctx context.Context, userID uuid.UUID, cursor string, limit int) (*dto.Page[*model.AuthorPost], error)
+	FindNotValidatedPosts(ctx context.Context, cursor, editsCursor string, limit int) (*dto.ModerationQueue, error)
+	FindUserLikes(ctx context.Context, userID uuid.UUID, cursor string, limit int) (*dto.Page[*model.FullPost], error)
+	FindFeed(ctx context.Context, userID uuid.UUID, cursor string, limit int) (*dto.Page[*model.FullPost], error)
 	IsLiked(ctx context.Context, postID int64, userID uuid.UUID) bool
 	Like(ctx context.Context, postID int64, userID uuid.UUID, unlike bool) error
-	GetTrending(ctx context.Context, hours, limit int) ([]*model.FullPost, error)
-	SearchByTitle(ctx context.Context, title string, limit, offset int) ([]*model.FullPost, error)
-	Edit(ctx context.Context, dto dto.EditPostRequest) error
-	UpdateValidationStatus(ctx context.Context, id int64, moderatorID uuid.UUID, validated bool, validationStatusMsg string) error
+	FindTrending(ctx context.Context, window, tag string, cursor string, limit int) (*dto.Page[*model.FullPost], error)
+	FindRelated(ctx context.Context, postID int64) ([]*model.FullPost, error)
+	Search(ctx context.Context, query string, cursor string, limit int) (*dto.Page[*model.PostSearchResult], error)
+	FindByTags(ctx context.Context, tags []string, mode string, cursor string, limit int) (*dto.Page[*model.FullPost], error)
+	Edit(ctx context.Context, dto dto.EditPostRequest) (bool, error)
+	UpdateValidationStatus(ctx context.Context, id int64, moderatorID uuid.UUID, validated bool, validationStatusMsg string) (bool, error)
+	ClaimPost(ctx context.Context, postID int64, moderatorID uuid.UUID) (*model.ModerationClaim, error)
+	ReleasePostClaim(ctx context.Context, postID int64, moderatorID uuid.UUID) error
+	AssignNextPost(ctx context.Context, moderatorID uuid.UUID) (*dto.ModerationAssignment, error)
+	Appeal(ctx context.Context, postID int64, authorID uuid.UUID, req dto.AppealPostRequest) (*model.PostAppeal, error)
+	FindPostAppeals(ctx context.Context, postID int64, authorID uuid.UUID) ([]*model.PostAppeal, error)
+	FindAppealsQueue(ctx context.Context, moderatorID uuid.UUID, cursor string, limit int) (*dto.Page[*model.AppealedPost], error)
+	DecideAppeal(ctx context.Context, appealID int64, moderatorID uuid.UUID, req dto.DecideAppealRequest) (*model.PostAppeal, error)
+	ReviewRevision(ctx context.Context, revisionID int64, moderatorID uuid.UUID, approved bool, statusMsg string) error
+	Delete(ctx context.Context, id int64, authorID uuid.UUID) error
+	Restore(ctx context.Context, id int64, authorID uuid.UUID) error
+	Purge(ctx context.Context, id int64, authorID uuid.UUID) error
+	FindAuthorDeletedPosts(ctx context.Context, authorID uuid.UUID, cursor string, limit int) (*dto.Page[*model.AuthorPost], error)
 	SchedulePostLikesUpdates()
+	SchedulePostsPurge()
+	CreateDraft(ctx context.Context, authorID uuid.UUID, req dto.SaveDraftRequest) (*model.Post, error)
+	FindAuthorDrafts(ctx context.Context, authorID uuid.UUID, cursor string, limit int) (*dto.Page[*model.AuthorPost], error)
+	FindAuthorDraft(ctx context.Context, id int64, authorID uuid.UUID) (*model.AuthorPost, error)
+	UpdateDraft(ctx context.Context, id int64, authorID uuid.UUID, req dto.EditDraftRequest) error
+	AutosaveDraft(ctx context.Context, id int64, authorID uuid.UUID, req dto.AutosaveDraftRequest) error
+	DiscardDraft(ctx context.Context, id int64, authorID uuid.UUID) error
+	PublishDraft(ctx context.Context, id int64, authorID uuid.UUID, publishAt *time.Time) (*model.Post, error)
+	ScheduleDraftAutosavesFlush()
+	FindAuthorScheduledPosts(ctx context.Context, authorID uuid.UUID, cursor string, limit int) (*dto.Page[*model.AuthorPost], error)
+	Reschedule(ctx context.Context, id int64, authorID uuid.UUID, publishAt time.Time) error
+	CancelSchedule(ctx context.Context, id int64, authorID uuid.UUID) error
+	SchedulePostsPublishing()
+	ScheduleTrendingRecompute()
+	ScheduleRelatedPostsRecompute()
+	FindRevisions(ctx context.Context, postID int64, authorID uuid.UUID, cursor string, limit int) (*dto.Page[*model.PostRevision], error)
+	FindRevision(ctx context.Context, postID, revisionID int64, authorID uuid.UUID) (*model.PostRevision, error)
+	DiffRevisions(ctx context.Context, postID int64, authorID uuid.UUID, fromRevisionID, toRevisionID int64) (*dto.RevisionDiff, error)
+	Rollback(ctx context.Context, postID, revisionID int64, authorID uuid.UUID) (bool, error)
 	StartScheduledJobs()
+	consumeFollows(ctx context.Context)
+}
+
+type Tag interface {
+	FindTags(ctx context.Context, prefix string, limit, offset int) ([]*model.Tag, error)
+	Autocomplete(ctx context.Context, prefix string) ([]string, error)
+	Merge(ctx context.Context, from []string, to string) error
+	Rename(ctx context.Context, tag, name string) error
+	AddAlias(ctx context.Context, tag, alias string) error
+	SetBanned(ctx context.Context, tag string, banned bool) error
 }
 
 type Comment interface {
 	Create(ctx context.Context, authorID uuid.UUID, dto dto.CreateCommentDto) (*model.Comment, error)
-	FindPostComments(ctx context.Context, postID int64, limit int, offset int) ([]*model.FullComment, error)
-	FindCommentReplies(ctx context.Context, postID int64, commentID int64, limit int, offset int) ([]*model.FullComment, error)
-	Delete(ctx context.Context, postID int64, commentID int64, authorID uuid.UUID) error
+	FindPostComments(ctx context.Context, postID int64, cursor string, limit int) (*dto.Page[*model.FullComment], error)
+	FindCommentReplies(ctx context.Context, postID int64, commentID int64, cursor string, limit int) (*dto.Page[*model.FullComment], error)
+	FindTree(ctx context.Context, postID int64, parentID *int64, sort string, cursor string, depth, breadth int) (*dto.CommentTree, error)
+	Delete(ctx context.Context, postID int64, commentID int64, userID uuid.UUID, asModerator bool, reason string) error
+	Edit(ctx context.Context, postID, commentID int64, authorID uuid.UUID, req dto.EditCommentRequest) (*model.Comment, error)
+	FindRevisions(ctx context.Context, postID, commentID int64, userID uuid.UUID, asModerator bool) ([]*model.CommentRevision, error)
 	Like(ctx context.Context, commentID int64, userID uuid.UUID, unlike bool) error
 	IsLiked(ctx context.Context, commentID int64, userID uuid.UUID) bool
 	ScheduleCommentLikesUpdates()
+	SchedulePostCommentsCountUpdates()
+	StartScheduledJobs()
+	deletePostCommentsCache(ctx context.Context, postID int64)
+}
+
+type Bookmark interface {
+	Bookmark(ctx context.Context, postID int64, userID uuid.UUID, unbookmark bool) error
+	IsBookmarked(ctx context.Context, postID int64, userID uuid.UUID) bool
+	FindUserBookmarks(ctx context.Context, userID uuid.UUID, cursor string, limit int) (*dto.Page[*model.FullPost], error)
+	CreateCollection(ctx context.Context, userID uuid.UUID, req dto.CreateBookmarkCollectionRequest) (*model.BookmarkCollection, error)
+	FindUserCollections(ctx context.Context, userID uuid.UUID, publicOnly bool) ([]*model.BookmarkCollection, error)
+	FindCollection(ctx context.Context, id int64, viewerID uuid.UUID) (*model.BookmarkCollection, error)
+	FindCollectionPosts(ctx context.Context, id int64, viewerID uuid.UUID, cursor string, limit int) (*dto.Page[*model.FullPost], error)
+	UpdateCollection(ctx context.Context, id int64, userID uuid.UUID, req dto.UpdateBookmarkCollectionRequest) error
+	DeleteCollection(ctx context.Context, id int64, userID uuid.UUID) error
+	AddToCollection(ctx context.Context, id int64, userID uuid.UUID, postID int64) error
+	RemoveFromCollection(ctx context.Context, id int64, userID uuid.UUID, postID int64) error
+	ReorderCollection(ctx context.Context, id int64, userID uuid.UUID, postIDs []int64) error
+}
+
+// Targets are model.REACTION_TARGET_* values
+type Reaction interface {
+	ReactionTypes() []string
+	React(ctx context.Context, target string, targetID int64, userID uuid.UUID, reactionType string) error
+	Unreact(ctx context.Context, target string, targetID int64, userID uuid.UUID) error
+	FindMyReaction(ctx context.Context, target string, targetID int64, userID uuid.UUID) *string
+	SetMyCommentReactions(ctx context.Context, comments []*model.FullComment, userID uuid.UUID)
+	ScheduleReactionsFlush()
 	StartScheduledJobs()
 }
 
+// Targets are model.REPORT_TARGET_* values
+type Report interface {
+	ReportReasons() []string
+	Report(ctx context.Context, target string, targetID int64, reporterID uuid.UUID, req dto.ReportRequest) error
+	FindQueue(ctx context.Context, target, cursor string, limit int) (*dto.Page[*model.ReportedTarget], error)
+	FindTargetReports(ctx context.Context, target string, targetID int64) ([]*model.Report, error)
+	Resolve(ctx context.Context, target string, targetID int64, moderatorID uuid.UUID, req dto.ResolveReportsRequest) (*model.ReportResolution, error)
+}
+
+type ModerationAudit interface {
+	FindDecisions(ctx context.Context, filter model.ModerationDecisionsFilter, cursor string, limit int) (*dto.Page[*model.ModerationDecision], error)
+	ExportDecisions(ctx context.Context, filter model.ModerationDecisionsFilter) ([]*model.ModerationDecision, error)
+	FindModeratorStats(ctx context.Context, filter model.ModerationDecisionsFilter) ([]*model.ModeratorStats, error)
+}
+
 type UserCache interface {
 	CreateOrGet(ctx context.Context, id uuid.UUID, accessToken string) (*model.CachedUser, error)
 	Create(ctx context.Context, cachedUser model.CachedUser) error
@@ -61,14 +189,26 @@ type UserCache interface {
 
 type Service struct {
 	Post
+	Tag
 	Comment
+	Bookmark
+	Reaction
+	Report
+	ModerationAudit
 	UserCache
 }
 
 func New(logger *zap.Logger, repo *repository.Repository, rdb *redis.Client, rabbitmq *rabbitmq.MQConn) *Service {
+	comment := newCommentService(logger, repo, rdb, rabbitmq)
+
 	return &Service{
 		Post: newPostService(logger, repo, rdb, rabbitmq),
-		Comment: newCommentService(logger, repo, rdb),
+		Tag: newTagService(logger, repo, rdb),
+		Comment: comment,
+		Bookmark: newBookmarkService(logger, repo, rdb),
+		Reaction: newReactionService(logger, repo, rdb),
+		Report: newReportService(logger, repo, rdb, rabbitmq, comment),
+		ModerationAudit: newModerationAuditService(logger, repo),
 		UserCache: newUserCacheService(logger, repo, rdb, rabbitmq),
 	}
 }
@@ -76,9 +216,11 @@ func New(logger *zap.Logger, repo *repository.Repository, rdb *redis.Client, rab
 func (s *Service) StartConsumeAll(ctx context.Context) {
 	go s.UserCache.consumeUsersCreate(ctx)
 	go s.UserCache.consumeUserUpdates(ctx)
+	go s.Post.consumeFollows(ctx)
 }
 
 func (s *Service) StartAllScheduledJobs() {
 	go s.Post.StartScheduledJobs()
 	go s.Comment.StartScheduledJobs()
+	go s.Reaction.StartScheduledJobs()
 }
@@ -0,0 +1,349 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/BloggingApp/post-service/internal/dto"
+	"github.com/BloggingApp/post-service/internal/model"
+	"github.com/BloggingApp/post-service/internal/repository"
+	"github.com/BloggingApp/post-service/internal/repository/redisrepo"
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/zap"
+)
+
+const (
+	TAGS_MODE_ANY = "any"
+	TAGS_MODE_ALL = "all"
+	MAX_TAGS_PER_QUERY = 10
+	TAGS_AUTOCOMPLETE_LIMIT = 10
+	TAGS_CACHE_TTL = 5 * time.Minute
+	MAX_POST_TAGS = 5
+	MAX_TAG_LENGTH = 32
+)
+
+type tagService struct {
+	logger *zap.Logger
+	repo *repository.Repository
+	rdb *redis.Client
+}
+
+func newTagService(logger *zap.Logger, repo *repository.Repository, rdb *redis.Client) Tag {
+	return &tagService{
+		logger: logger,
+		repo: repo,
+		rdb: rdb,
+	}
+}
+
+func (s *tagService) FindTags(ctx context.Context, prefix string, limit, offset int) ([]*model.Tag, error) {
+	maxLimit(&limit)
+	prefix, _ = normalizeTag(prefix)
+
+	cachedTags, err := redisrepo.GetMany[model.Tag](s.rdb, ctx, redisrepo.TagsKey(prefix, limit, offset))
+	if err == nil {
+		return cachedTags, nil
+	}
+	if err != redis.Nil {
+		s.logger.Sugar().Errorf("failed to get tags with prefix(%s) from redis: %s", prefix, err.Error())
+		return nil, ErrInternal
+	}
+
+	tags, err := s.repo.Postgres.Tag.FindTags(ctx, prefix, limit, offset)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find tags with prefix(%s) from postgres: %s", prefix, err.Error())
+		return nil, ErrInternal
+	}
+
+	if err := redisrepo.SetJSON(s.rdb, ctx, redisrepo.TagsKey(prefix, limit, offset), tags, TAGS_CACHE_TTL); err != nil {
+		s.logger.Sugar().Errorf("failed to set tags with prefix(%s) in redis: %s", prefix, err.Error())
+		return nil, ErrInternal
+	}
+
+	return tags, nil
+}
+
+func (s *tagService) Autocomplete(ctx context.Context, prefix string) ([]string, error) {
+	prefix, ok := normalizeTag(prefix)
+	if !ok {
+		return []string{}, nil
+	}
+
+	cachedTags, err := redisrepo.Get[[]string](s.rdb, ctx, redisrepo.TagsAutocompleteKey(prefix))
+	if err == nil && cachedTags != nil {
+		return *cachedTags, nil
+	}
+	if err != nil && err != redis.Nil {
+		s.logger.Sugar().Errorf("failed to get tags autocomplete for prefix(%s) from redis: %s", prefix, err.Error())
+		return nil, ErrInternal
+	}
+
+	tags, err := s.repo.Postgres.Tag.Autocomplete(ctx, prefix, TAGS_AUTOCOMPLETE_LIMIT)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to find tags autocomplete for prefix(%s) from postgres: %s", prefix, err.Error())
+		return nil, ErrInternal
+	}
+
+	if err := redisrepo.SetJSON(s.rdb, ctx, redisrepo.TagsAutocompleteKey(prefix), tags, TAGS_CACHE_TTL); err != nil {
+		s.logger.Sugar().Errorf("failed to set tags autocomplete for prefix(%s) in redis: %s", prefix, err.Error())
+		return nil, ErrInternal
+	}
+
+	return tags, nil
+}
+
+// Merges tags into the target tag, merged tags become its aliases
+func (s *tagService) Merge(ctx context.Context, from []string, to string) error {
+	to, ok := normalizeTag(to)
+	if !ok {
+		return ErrInvalidTag
+	}
+
+	var slugs []string
+	for _, tag := range from {
+		slug, ok := normalizeTag(tag)
+		if !ok {
+			return ErrInvalidTag
+		}
+		if slug != to && !slices.Contains(slugs, slug) {
+			slugs = append(slugs, slug)
+		}
+	}
+	if len(slugs) == 0 {
+		return ErrNoTags
+	}
+
+	// Target may be an alias itself
+	canonical, banned, err := s.repo.Postgres.Tag.Resolve(ctx, []string{to})
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to resolve tag(%s): %s", to, err.Error())
+		return ErrInternal
+	}
+	if len(banned) != 0 {
+		return fmt.Errorf("%w: %s", ErrTagIsBanned, banned[0])
+	}
+	to = canonical[0]
+	slugs = slices.DeleteFunc(slugs, func(slug string) bool { return slug == to })
+	if len(slugs) == 0 {
+		return nil
+	}
+
+	postIDs, err := s.repo.Postgres.Tag.Merge(ctx, slugs, to)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to merge tags(%v) into tag(%s): %s", slugs, to, err.Error())
+		return ErrInternal
+	}
+
+	s.invalidateTagCaches(ctx, postIDs)
+
+	return nil
+}
+
+// Renaming keeps the old name as an alias, so old links keep working
+func (s *tagService) Rename(ctx context.Context, tag, name string) error {
+	slug, ok := normalizeTag(tag)
+	if !ok {
+		return ErrTagNotFound
+	}
+
+	exists, err := s.repo.Postgres.Tag.Exists(ctx, slug)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to check if tag(%s) exists: %s", slug, err.Error())
+		return ErrInternal
+	}
+	if !exists {
+		return ErrTagNotFound
+	}
+
+	return s.Merge(ctx, []string{slug}, name)
+}
+
+func (s *tagService) AddAlias(ctx context.Context, tag, alias string) error {
+	return s.Merge(ctx, []string{alias}, tag)
+}
+
+func (s *tagService) SetBanned(ctx context.Context, tag string, banned bool) error {
+	slug, ok := normalizeTag(tag)
+	if !ok {
+		return ErrInvalidTag
+	}
+
+	postIDs, err := s.repo.Postgres.Tag.SetBanned(ctx, slug, banned)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to set tag(%s) banned(%t): %s", slug, banned, err.Error())
+		return ErrInternal
+	}
+
+	s.invalidateTagCaches(ctx, postIDs)
+
+	return nil
+}
+
+// Deletes cache entries that may contain changed tags
+func (s *tagService) invalidateTagCaches(ctx context.Context, postIDs []int64) {
+	invalidateTagCaches(ctx, s.rdb, s.logger, postIDs, redisrepo.AUTHOR_POSTS_KEY_PATTERN, redisrepo.SEARCH_POSTS_RESULT_KEY_PATTERN)
+}
+
+// Deletes cached posts, tag feeds and tag directory, extra patterns are deleted too
+func invalidateTagCaches(ctx context.Context, rdb *redis.Client, logger *zap.Logger, postIDs []int64, extraPatterns ...string) {
+	var keys []string
+	for _, postID := range postIDs {
+		keys = append(keys, redisrepo.PostKey(postID))
+	}
+
+	patterns := []string{
+		redisrepo.TAGS_POSTS_KEY_PATTERN,
+		redisrepo.TAGS_KEY_PATTERN,
+		redisrepo.TAGS_AUTOCOMPLETE_KEY_PATTERN,
+	}
+	for _, pattern := range append(patterns, extraPatterns...) {
+		patternKeys, err := rdb.Keys(ctx, pattern).Result()
+		if err != nil && err != redis.Nil {
+			logger.Sugar().Errorf("failed to get keys with pattern(%s) from redis: %s", pattern, err.Error())
+			continue
+		}
+		keys = append(keys, patternKeys...)
+	}
+
+	if len(keys) == 0 {
+		return
+	}
+
+	if err := rdb.Del(ctx, keys...).Err(); err != nil {
+		logger.Sugar().Errorf("failed to delete tag caches from redis: %s", err.Error())
+	}
+}
+
+// Converts a tag to its slug: lower case, words separated by "-", only letters, digits and "+#." are kept.
+// Returns false if nothing is left or the tag is too long
+func normalizeTag(tag string) (string, bool) {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(strings.TrimSpace(tag)) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '+' || r == '#' || r == '.':
+			if dash && b.Len() > 0 {
+				b.WriteRune('-')
+			}
+			dash = false
+			b.WriteRune(r)
+		case unicode.IsSpace(r) || r == '-' || r == '_':
+			dash = true
+		}
+	}
+
+	slug := strings.Trim(b.String(), ".")
+	length := utf8.RuneCountInString(slug)
+	return slug, length > 0 && length <= MAX_TAG_LENGTH
+}
+
+// Normalizes post tags and maps them to canonical tags, duplicates are dropped
+func (s *postService) canonicalPostTags(ctx context.Context, tags []string) ([]string, error) {
+	result, err := s.resolveTags(ctx, tags)
+	if err != nil {
+		return nil, err
+	}
+	if len(result) > MAX_POST_TAGS {
+		return nil, ErrTooManyTags
+	}
+
+	return result, nil
+}
+
+// Returns the post tags after the edit sorted, or nil if the edit doesn't touch tags
+func (s *postService) editedPostTags(ctx context.Context, current []string, input dto.EditPostRequest) ([]string, error) {
+	if input.Tags == nil && len(input.AddTags) == 0 && len(input.RemoveTags) == 0 {
+		return nil, nil
+	}
+
+	tags := current
+	if input.Tags != nil {
+		tags = *input.Tags
+	}
+
+	tags, err := s.resolveTags(ctx, append(slices.Clone(tags), input.AddTags...))
+	if err != nil {
+		return nil, err
+	}
+
+	removed, err := s.canonicalQueryTags(ctx, input.RemoveTags)
+	if err != nil {
+		return nil, err
+	}
+	tags = slices.DeleteFunc(tags, func(tag string) bool { return slices.Contains(removed, tag) })
+
+	if len(tags) > MAX_POST_TAGS {
+		return nil, ErrTooManyTags
+	}
+
+	slices.Sort(tags)
+	return tags, nil
+}
+
+// Normalizes tags and maps them to canonical tags, duplicates are dropped
+func (s *postService) resolveTags(ctx context.Context, tags []string) ([]string, error) {
+	var slugs []string
+	for _, tag := range tags {
+		slug, ok := normalizeTag(tag)
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidTag, tag)
+		}
+		slugs = append(slugs, slug)
+	}
+	if len(slugs) == 0 {
+		return []string{}, nil
+	}
+
+	canonical, banned, err := s.repo.Postgres.Tag.Resolve(ctx, slugs)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to resolve tags(%v): %s", slugs, err.Error())
+		return nil, ErrInternal
+	}
+	if len(banned) != 0 {
+		return nil, fmt.Errorf("%w: %s", ErrTagIsBanned, banned[0])
+	}
+
+	result := []string{}
+	for _, tag := range canonical {
+		if !slices.Contains(result, tag) {
+			result = append(result, tag)
+		}
+	}
+
+	return result, nil
+}
+
+// Normalizes queried tags and maps them to canonical tags. Invalid and banned tags are dropped,
+// the rest is sorted so the same set of tags always hits the same cache entry
+func (s *postService) canonicalQueryTags(ctx context.Context, tags []string) ([]string, error) {
+	var slugs []string
+	for _, tag := range tags {
+		if slug, ok := normalizeTag(tag); ok {
+			slugs = append(slugs, slug)
+		}
+	}
+	if len(slugs) == 0 {
+		return []string{}, nil
+	}
+
+	canonical, _, err := s.repo.Postgres.Tag.Resolve(ctx, slugs)
+	if err != nil {
+		s.logger.Sugar().Errorf("failed to resolve tags(%v): %s", slugs, err.Error())
+		return nil, ErrInternal
+	}
+
+	result := []string{}
+	for _, tag := range canonical {
+		if !slices.Contains(result, tag) {
+			result = append(result, tag)
+		}
+	}
+
+	slices.Sort(result)
+	return result, nil
+}
@@ -0,0 +1,38 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTag(t *testing.T) {
+	tests := []struct {
+		name  string
+		tag   string
+		slug  string
+		valid bool
+	}{
+		{name: "lower case", tag: "Go", slug: "go", valid: true},
+		{name: "words", tag: "  Machine   Learning ", slug: "machine-learning", valid: true},
+		{name: "separators", tag: "foo__bar--baz", slug: "foo-bar-baz", valid: true},
+		{name: "leading separator", tag: "-lead", slug: "lead", valid: true},
+		{name: "kept symbols", tag: "C++", slug: "c++", valid: true},
+		{name: "hash", tag: "C#", slug: "c#", valid: true},
+		{name: "inner dot", tag: "Node.js", slug: "node.js", valid: true},
+		{name: "outer dots", tag: ".NET.", slug: "net", valid: true},
+		{name: "dropped symbols", tag: "a!b?", slug: "ab", valid: true},
+		{name: "non-latin", tag: "Привет Мир", slug: "привет-мир", valid: true},
+		{name: "nothing left", tag: "!!!", slug: "", valid: false},
+		{name: "max length", tag: strings.Repeat("a", MAX_TAG_LENGTH), slug: strings.Repeat("a", MAX_TAG_LENGTH), valid: true},
+		{name: "too long", tag: strings.Repeat("a", MAX_TAG_LENGTH+1), slug: strings.Repeat("a", MAX_TAG_LENGTH+1), valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slug, valid := normalizeTag(tt.tag)
+			if slug != tt.slug || valid != tt.valid {
+				t.Errorf("expected (%q, %v), got (%q, %v)", tt.slug, tt.valid, slug, valid)
+			}
+		})
+	}
+}